@@ -8,9 +8,10 @@ import (
 
 // APIError represents a structured API error response
 type APIError struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details string       `json:"details,omitempty"`
+	Errors  []FieldError `json:"errors,omitempty"` // Invalid fields of a request body
 }
 
 // ErrorResponse represents the full error response structure
@@ -26,6 +27,15 @@ const (
 	CodeConflict         = "CONFLICT"
 	CodeValidationFailed = "VALIDATION_FAILED"
 	CodeUnauthorized     = "UNAUTHORIZED"
+	CodeForbidden        = "FORBIDDEN"
+
+	CodePreconditionFailed   = "PRECONDITION_FAILED"
+	CodePreconditionRequired = "PRECONDITION_REQUIRED"
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	CodeInvalidPatch         = "INVALID_PATCH"
+	CodeInvalidImport        = "INVALID_IMPORT"
+	CodeInvalidUpload        = "INVALID_UPLOAD"
+	CodePayloadTooLarge      = "PAYLOAD_TOO_LARGE"
 
 	// Server errors (5xx)
 	CodeInternalError = "INTERNAL_ERROR"
@@ -59,6 +69,167 @@ var (
 		Details: "You have already liked this post from this IP address",
 	}
 
+	ErrTranslationAlreadyExists = APIError{
+		Code:    CodeConflict,
+		Message: "Translation already exists",
+		Details: "The translation group already has a post in this language",
+	}
+
+	ErrTranslationGroupNotFound = APIError{
+		Code:    CodeBadRequest,
+		Message: "Translation group not found",
+		Details: "No post belongs to the provided translation_group_id",
+	}
+
+	ErrPreconditionFailed = APIError{
+		Code:    CodePreconditionFailed,
+		Message: "Post has been modified",
+		Details: "The post was changed since the version in If-Match or the request body; fetch the latest version and retry",
+	}
+
+	ErrPreconditionRequired = APIError{
+		Code:    CodePreconditionRequired,
+		Message: "Post version required",
+		Details: "Provide the post's ETag in the If-Match header or its version in the request body",
+	}
+
+	ErrSlugAliasNotFound = APIError{
+		Code:    CodeNotFound,
+		Message: "Slug alias not found",
+		Details: "The slug is not a previous slug of this post",
+	}
+
+	ErrCannotRemoveCurrentSlug = APIError{
+		Code:    CodeBadRequest,
+		Message: "Cannot remove current slug",
+		Details: "Change the post's slug before removing it from the slug history",
+	}
+
+	ErrUnsupportedPatchFormat = APIError{
+		Code:    CodeUnsupportedMediaType,
+		Message: "Unsupported patch format",
+		Details: "PATCH requests must use Content-Type application/merge-patch+json or application/json-patch+json",
+	}
+
+	// Author-related errors
+	ErrInvalidAuthorID = APIError{
+		Code:    CodeBadRequest,
+		Message: "Invalid author ID format",
+		Details: "The provided author ID is not a valid MongoDB ObjectID",
+	}
+
+	ErrAuthorNotFound = APIError{
+		Code:    CodeNotFound,
+		Message: "Author not found",
+		Details: "The requested author does not exist or has been deleted",
+	}
+
+	ErrAuthorAlreadyExists = APIError{
+		Code:    CodeConflict,
+		Message: "Author with this slug or API key already exists",
+		Details: "Please choose a different slug, or remove the API key ID from the other author",
+	}
+
+	// Series-related errors
+	ErrInvalidSeriesID = APIError{
+		Code:    CodeBadRequest,
+		Message: "Invalid series ID format",
+		Details: "The provided series ID is not a valid MongoDB ObjectID",
+	}
+
+	ErrSeriesNotFound = APIError{
+		Code:    CodeNotFound,
+		Message: "Series not found",
+		Details: "The requested series does not exist or has been deleted",
+	}
+
+	ErrSeriesAlreadyExists = APIError{
+		Code:    CodeConflict,
+		Message: "Series with this slug already exists, or a post belongs to another series",
+		Details: "Please choose a different slug, or remove the posts from their other series",
+	}
+
+	// Media-related errors
+	ErrInvalidMediaID = APIError{
+		Code:    CodeBadRequest,
+		Message: "Invalid media ID format",
+		Details: "The provided media ID is not a valid MongoDB ObjectID",
+	}
+
+	ErrMediaNotFound = APIError{
+		Code:    CodeNotFound,
+		Message: "Media not found",
+		Details: "The requested media file or variant does not exist or has been deleted",
+	}
+
+	ErrUnsupportedMediaUpload = APIError{
+		Code:    CodeUnsupportedMediaType,
+		Message: "Unsupported file type",
+		Details: "Uploads must be JPEG, PNG, GIF or WebP images, PDF documents or MP4 videos",
+	}
+
+	ErrFailedToStoreMedia = APIError{
+		Code:    CodeInternalError,
+		Message: "Failed to store media",
+		Details: "An error occurred while writing the file to media storage",
+	}
+
+	ErrFailedToReadMedia = APIError{
+		Code:    CodeInternalError,
+		Message: "Failed to read media",
+		Details: "An error occurred while reading the file from media storage",
+	}
+
+	// Webhook-related errors
+	ErrInvalidWebhookID = APIError{
+		Code:    CodeBadRequest,
+		Message: "Invalid webhook ID format",
+		Details: "The provided webhook ID is not a valid MongoDB ObjectID",
+	}
+
+	ErrWebhookNotFound = APIError{
+		Code:    CodeNotFound,
+		Message: "Webhook not found",
+		Details: "The requested webhook does not exist or has been deleted",
+	}
+
+	ErrWebhookDeliveryNotFound = APIError{
+		Code:    CodeNotFound,
+		Message: "Webhook delivery not found",
+		Details: "The requested delivery does not exist for this webhook",
+	}
+
+	ErrWebhookDisabled = APIError{
+		Code:    CodeConflict,
+		Message: "Webhook is disabled",
+		Details: "Re-enable the webhook by updating it with active set to true before redelivering",
+	}
+
+	// Newsletter-related errors
+	ErrInvalidNewsletterToken = APIError{
+		Code:    CodeBadRequest,
+		Message: "Invalid or expired link",
+		Details: "The link is invalid or has expired; subscribe again to receive a new confirmation link",
+	}
+
+	ErrInvalidSubscriberID = APIError{
+		Code:    CodeBadRequest,
+		Message: "Invalid subscriber ID format",
+		Details: "The provided subscriber ID is not a valid MongoDB ObjectID",
+	}
+
+	ErrSubscriberNotFound = APIError{
+		Code:    CodeNotFound,
+		Message: "Subscriber not found",
+		Details: "No subscriber matches the request",
+	}
+
+	ErrFailedToSendConfirmation = APIError{
+		Code:    CodeInternalError,
+		Message: "Failed to send confirmation email",
+		Details: "The confirmation email could not be sent; please try again later",
+	}
+
 	// Database operation errors
 	ErrFailedToCreatePost = APIError{
 		Code:    CodeDatabaseError,
@@ -132,6 +303,174 @@ var (
 		Details: "The post was updated but could not be retrieved for response",
 	}
 
+	ErrFailedToFetchAnalytics = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to fetch analytics",
+		Details: "An error occurred while aggregating analytics from the database",
+	}
+
+	ErrFailedToFetchArchive = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to fetch archive",
+		Details: "An error occurred while aggregating the post archive from the database",
+	}
+
+	ErrFailedToFetchAuditLog = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to fetch audit log",
+		Details: "An error occurred while retrieving audit log entries from the database",
+	}
+
+	ErrFailedToCreateAuthor = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to create author",
+		Details: "An error occurred while saving the author to the database",
+	}
+
+	ErrFailedToFetchAuthor = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to fetch author",
+		Details: "An error occurred while retrieving the author from the database",
+	}
+
+	ErrFailedToFetchAuthors = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to fetch authors",
+		Details: "An error occurred while retrieving authors from the database",
+	}
+
+	ErrFailedToUpdateAuthor = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to update author",
+		Details: "An error occurred while updating the author in the database",
+	}
+
+	ErrFailedToDeleteAuthor = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to delete author",
+		Details: "An error occurred while deleting the author from the database",
+	}
+
+	ErrFailedToCreateSeries = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to create series",
+		Details: "An error occurred while saving the series to the database",
+	}
+
+	ErrFailedToFetchSeries = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to fetch series",
+		Details: "An error occurred while retrieving series from the database",
+	}
+
+	ErrFailedToUpdateSeries = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to update series",
+		Details: "An error occurred while updating the series in the database",
+	}
+
+	ErrFailedToDeleteSeries = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to delete series",
+		Details: "An error occurred while deleting the series from the database",
+	}
+
+	ErrFailedToSaveMedia = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to save media",
+		Details: "An error occurred while saving the media metadata to the database",
+	}
+
+	ErrFailedToFetchMedia = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to fetch media",
+		Details: "An error occurred while retrieving media from the database",
+	}
+
+	ErrFailedToDeleteMedia = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to delete media",
+		Details: "An error occurred while deleting the media from the database",
+	}
+
+	ErrFailedToFetchRelatedPosts = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to fetch related posts",
+		Details: "An error occurred while retrieving related posts from the database",
+	}
+
+	ErrFailedToRebuildRelatedPosts = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to rebuild related posts",
+		Details: "An error occurred while recomputing related posts",
+	}
+
+	ErrFailedToCreateWebhook = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to create webhook",
+		Details: "An error occurred while saving the webhook to the database",
+	}
+
+	ErrFailedToFetchWebhooks = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to fetch webhooks",
+		Details: "An error occurred while retrieving webhooks from the database",
+	}
+
+	ErrFailedToUpdateWebhook = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to update webhook",
+		Details: "An error occurred while updating the webhook in the database",
+	}
+
+	ErrFailedToDeleteWebhook = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to delete webhook",
+		Details: "An error occurred while deleting the webhook from the database",
+	}
+
+	ErrFailedToFetchWebhookDeliveries = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to fetch webhook deliveries",
+		Details: "An error occurred while retrieving webhook deliveries from the database",
+	}
+
+	ErrFailedToRedeliverWebhook = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to queue redelivery",
+		Details: "An error occurred while queueing the webhook delivery",
+	}
+
+	ErrFailedToSubscribe = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to subscribe",
+		Details: "An error occurred while saving the subscription to the database",
+	}
+
+	ErrFailedToFetchSubscribers = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to fetch subscribers",
+		Details: "An error occurred while retrieving subscribers from the database",
+	}
+
+	ErrFailedToUpdateSubscriber = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to update subscription",
+		Details: "An error occurred while updating the subscription in the database",
+	}
+
+	ErrFailedToDeleteSubscriber = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to delete subscriber",
+		Details: "An error occurred while deleting the subscriber from the database",
+	}
+
+	ErrFailedToFetchNewsletterIssues = APIError{
+		Code:    CodeDatabaseError,
+		Message: "Failed to fetch newsletter issues",
+		Details: "An error occurred while retrieving newsletter issues from the database",
+	}
+
 	// Authentication-related errors
 	ErrMissingAuthorization = APIError{
 		Code:    CodeUnauthorized,
@@ -156,12 +495,33 @@ var (
 		Message: "Invalid API key",
 		Details: "The provided API key is not valid for admin operations",
 	}
+
+	// Authorization-related errors
+	ErrForbidden = APIError{
+		Code:    CodeForbidden,
+		Message: "Insufficient permissions",
+		Details: "The role of the provided API key does not allow this operation",
+	}
+
+	ErrNotPostOwner = APIError{
+		Code:    CodeForbidden,
+		Message: "Not an author of this post",
+		Details: "The provided API key may only modify posts attributed to its author",
+	}
 )
 
 // Helper functions to send structured error responses
 
-// RespondWithError sends a structured error response
+// RespondWithError sends a structured error response, or a problem details
+// document when the request prefers application/problem+json
 func RespondWithError(c *gin.Context, statusCode int, apiError APIError) {
+	c.Writer.Header().Add("Vary", "Accept")
+	if c.Request != nil && PrefersProblem(c.Request.Header.Get("Accept")) {
+		c.Header("Content-Type", ProblemContentType)
+		c.JSON(statusCode, NewProblem(c, statusCode, apiError))
+		return
+	}
+
 	response := ErrorResponse{
 		Error: apiError,
 	}
@@ -205,6 +565,49 @@ func RespondPostAlreadyLiked(c *gin.Context) {
 	RespondWithError(c, http.StatusConflict, ErrPostAlreadyLiked)
 }
 
+func RespondTranslationAlreadyExists(c *gin.Context) {
+	RespondWithError(c, http.StatusConflict, ErrTranslationAlreadyExists)
+}
+
+func RespondTranslationGroupNotFound(c *gin.Context) {
+	RespondWithError(c, http.StatusBadRequest, ErrTranslationGroupNotFound)
+}
+
+func RespondPreconditionFailed(c *gin.Context) {
+	RespondWithError(c, http.StatusPreconditionFailed, ErrPreconditionFailed)
+}
+
+func RespondPreconditionRequired(c *gin.Context) {
+	RespondWithError(c, http.StatusPreconditionRequired, ErrPreconditionRequired)
+}
+
+func RespondSlugAliasNotFound(c *gin.Context) {
+	RespondWithError(c, http.StatusNotFound, ErrSlugAliasNotFound)
+}
+
+func RespondCannotRemoveCurrentSlug(c *gin.Context) {
+	RespondWithError(c, http.StatusBadRequest, ErrCannotRemoveCurrentSlug)
+}
+
+func RespondUnsupportedPatchFormat(c *gin.Context) {
+	RespondWithError(c, http.StatusUnsupportedMediaType, ErrUnsupportedPatchFormat)
+}
+
+// RespondInvalidPatch sends an error for a patch document that is malformed or cannot be applied
+func RespondInvalidPatch(c *gin.Context, details string) {
+	RespondWithCustomError(c, http.StatusBadRequest, CodeInvalidPatch, "Invalid patch document", details)
+}
+
+// RespondInvalidImport sends an error for an import upload that can't be read
+func RespondInvalidImport(c *gin.Context, details string) {
+	RespondWithCustomError(c, http.StatusBadRequest, CodeInvalidImport, "Invalid import file", details)
+}
+
+// RespondPatchTestFailed sends an error for a JSON Patch whose test operation did not match
+func RespondPatchTestFailed(c *gin.Context, details string) {
+	RespondWithCustomError(c, http.StatusConflict, CodeConflict, "Patch test operation failed", details)
+}
+
 func RespondFailedToCreatePost(c *gin.Context) {
 	RespondWithError(c, http.StatusInternalServerError, ErrFailedToCreatePost)
 }
@@ -253,6 +656,216 @@ func RespondFailedToFetchUpdatedPost(c *gin.Context) {
 	RespondWithError(c, http.StatusInternalServerError, ErrFailedToFetchUpdatedPost)
 }
 
+func RespondFailedToFetchAnalytics(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToFetchAnalytics)
+}
+
+func RespondFailedToFetchArchive(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToFetchArchive)
+}
+
+func RespondFailedToFetchAuditLog(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToFetchAuditLog)
+}
+
+// Author error response helpers
+func RespondInvalidAuthorID(c *gin.Context) {
+	RespondWithError(c, http.StatusBadRequest, ErrInvalidAuthorID)
+}
+
+func RespondAuthorNotFound(c *gin.Context) {
+	RespondWithError(c, http.StatusNotFound, ErrAuthorNotFound)
+}
+
+func RespondAuthorAlreadyExists(c *gin.Context) {
+	RespondWithError(c, http.StatusConflict, ErrAuthorAlreadyExists)
+}
+
+func RespondFailedToCreateAuthor(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToCreateAuthor)
+}
+
+func RespondFailedToFetchAuthor(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToFetchAuthor)
+}
+
+func RespondFailedToFetchAuthors(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToFetchAuthors)
+}
+
+func RespondFailedToUpdateAuthor(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToUpdateAuthor)
+}
+
+func RespondFailedToDeleteAuthor(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToDeleteAuthor)
+}
+
+// Series error response helpers
+func RespondInvalidSeriesID(c *gin.Context) {
+	RespondWithError(c, http.StatusBadRequest, ErrInvalidSeriesID)
+}
+
+func RespondSeriesNotFound(c *gin.Context) {
+	RespondWithError(c, http.StatusNotFound, ErrSeriesNotFound)
+}
+
+func RespondSeriesAlreadyExists(c *gin.Context) {
+	RespondWithError(c, http.StatusConflict, ErrSeriesAlreadyExists)
+}
+
+func RespondPostInAnotherSeries(c *gin.Context, details string) {
+	RespondWithCustomError(c, http.StatusConflict, CodeConflict, "Post belongs to another series", details)
+}
+
+func RespondFailedToCreateSeries(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToCreateSeries)
+}
+
+func RespondFailedToFetchSeries(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToFetchSeries)
+}
+
+func RespondFailedToUpdateSeries(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToUpdateSeries)
+}
+
+func RespondFailedToDeleteSeries(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToDeleteSeries)
+}
+
+// Newsletter error response helpers
+func RespondInvalidNewsletterToken(c *gin.Context) {
+	RespondWithError(c, http.StatusBadRequest, ErrInvalidNewsletterToken)
+}
+
+func RespondInvalidSubscriberID(c *gin.Context) {
+	RespondWithError(c, http.StatusBadRequest, ErrInvalidSubscriberID)
+}
+
+func RespondSubscriberNotFound(c *gin.Context) {
+	RespondWithError(c, http.StatusNotFound, ErrSubscriberNotFound)
+}
+
+func RespondFailedToSendConfirmation(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToSendConfirmation)
+}
+
+func RespondFailedToSubscribe(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToSubscribe)
+}
+
+func RespondFailedToFetchSubscribers(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToFetchSubscribers)
+}
+
+func RespondFailedToUpdateSubscriber(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToUpdateSubscriber)
+}
+
+func RespondFailedToDeleteSubscriber(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToDeleteSubscriber)
+}
+
+func RespondFailedToFetchNewsletterIssues(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToFetchNewsletterIssues)
+}
+
+// Webhook error response helpers
+func RespondInvalidWebhookID(c *gin.Context) {
+	RespondWithError(c, http.StatusBadRequest, ErrInvalidWebhookID)
+}
+
+func RespondWebhookNotFound(c *gin.Context) {
+	RespondWithError(c, http.StatusNotFound, ErrWebhookNotFound)
+}
+
+func RespondWebhookDeliveryNotFound(c *gin.Context) {
+	RespondWithError(c, http.StatusNotFound, ErrWebhookDeliveryNotFound)
+}
+
+func RespondWebhookDisabled(c *gin.Context) {
+	RespondWithError(c, http.StatusConflict, ErrWebhookDisabled)
+}
+
+func RespondFailedToCreateWebhook(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToCreateWebhook)
+}
+
+func RespondFailedToFetchWebhooks(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToFetchWebhooks)
+}
+
+func RespondFailedToUpdateWebhook(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToUpdateWebhook)
+}
+
+func RespondFailedToDeleteWebhook(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToDeleteWebhook)
+}
+
+func RespondFailedToFetchWebhookDeliveries(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToFetchWebhookDeliveries)
+}
+
+func RespondFailedToRedeliverWebhook(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToRedeliverWebhook)
+}
+
+// Related posts error response helpers
+func RespondFailedToFetchRelatedPosts(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToFetchRelatedPosts)
+}
+
+func RespondFailedToRebuildRelatedPosts(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToRebuildRelatedPosts)
+}
+
+// Media error response helpers
+func RespondInvalidMediaID(c *gin.Context) {
+	RespondWithError(c, http.StatusBadRequest, ErrInvalidMediaID)
+}
+
+func RespondMediaNotFound(c *gin.Context) {
+	RespondWithError(c, http.StatusNotFound, ErrMediaNotFound)
+}
+
+func RespondMediaInUse(c *gin.Context, details string) {
+	RespondWithCustomError(c, http.StatusConflict, CodeConflict, "Media is referenced by posts", details)
+}
+
+func RespondUnsupportedMediaUpload(c *gin.Context) {
+	RespondWithError(c, http.StatusUnsupportedMediaType, ErrUnsupportedMediaUpload)
+}
+
+func RespondInvalidUpload(c *gin.Context, details string) {
+	RespondWithCustomError(c, http.StatusBadRequest, CodeInvalidUpload, "Invalid upload", details)
+}
+
+func RespondUploadTooLarge(c *gin.Context, details string) {
+	RespondWithCustomError(c, http.StatusRequestEntityTooLarge, CodePayloadTooLarge, "Upload too large", details)
+}
+
+func RespondFailedToStoreMedia(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToStoreMedia)
+}
+
+func RespondFailedToReadMedia(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToReadMedia)
+}
+
+func RespondFailedToSaveMedia(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToSaveMedia)
+}
+
+func RespondFailedToFetchMedia(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToFetchMedia)
+}
+
+func RespondFailedToDeleteMedia(c *gin.Context) {
+	RespondWithError(c, http.StatusInternalServerError, ErrFailedToDeleteMedia)
+}
+
 // Authentication error response helpers
 func RespondMissingAuthorization(c *gin.Context) {
 	RespondWithError(c, http.StatusUnauthorized, ErrMissingAuthorization)
@@ -269,3 +882,12 @@ func RespondEmptyAPIKey(c *gin.Context) {
 func RespondInvalidAPIKey(c *gin.Context) {
 	RespondWithError(c, http.StatusUnauthorized, ErrInvalidAPIKey)
 }
+
+// Authorization error response helpers
+func RespondForbidden(c *gin.Context) {
+	RespondWithError(c, http.StatusForbidden, ErrForbidden)
+}
+
+func RespondNotPostOwner(c *gin.Context) {
+	RespondWithError(c, http.StatusForbidden, ErrNotPostOwner)
+}
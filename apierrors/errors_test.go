@@ -0,0 +1,168 @@
+package apierrors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for error responses, problem details and field errors
+
+type testSocialLink struct {
+	URL string `json:"url" binding:"required,url"`
+}
+
+type testAuthor struct {
+	Name        string           `json:"name" binding:"required,min=1,max=10"`
+	Tags        []string         `json:"tags" binding:"max=2,dive,alphanum"`
+	Age         int              `json:"age" binding:"max=150"`
+	SocialLinks []testSocialLink `json:"social_links" binding:"dive"`
+}
+
+func bindAuthor(body string) error {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	var author testAuthor
+	return c.ShouldBindJSON(&author)
+}
+
+func TestFieldErrors(t *testing.T) {
+	err := bindAuthor(`{"tags":["go","c++","rust"],"age":200,"social_links":[{"url":"not a url"}]}`)
+	require.Error(t, err)
+	assert.Equal(t, []FieldError{
+		{Field: "name", Rule: "required", Message: "is required"},
+		{Field: "tags", Rule: "max", Message: "must have at most 2 items"},
+		{Field: "age", Rule: "max", Message: "must be at most 150"},
+		{Field: "social_links[0].url", Rule: "url", Message: "must be a valid URL"},
+	}, FieldErrors(err))
+
+	err = bindAuthor(`{"name":"a very long name","tags":["c++"]}`)
+	assert.Equal(t, []FieldError{
+		{Field: "name", Rule: "max", Message: "must be at most 10 characters long"},
+		{Field: "tags[0]", Rule: "alphanum", Message: "must only contain letters and digits"},
+	}, FieldErrors(err))
+
+	err = bindAuthor(`{"name":"Jane","age":"old"}`)
+	assert.Equal(t, []FieldError{{Field: "age", Rule: "type", Message: "must be an integer"}}, FieldErrors(err))
+
+	assert.Nil(t, FieldErrors(bindAuthor(`{"name":`)), "Malformed JSON isn't about a field")
+}
+
+type testAuthorUpdate struct {
+	testAuthor
+	Version *int64 `json:"version" binding:"required"`
+}
+
+func TestFieldErrors_EmbeddedStructs(t *testing.T) {
+	var update testAuthorUpdate
+	err := binding.Validator.ValidateStruct(&update)
+	require.Error(t, err)
+	assert.Equal(t, []FieldError{
+		{Field: "name", Rule: "required", Message: "is required"},
+		{Field: "version", Rule: "required", Message: "is required"},
+	}, FieldErrors(err), "Fields of embedded structs are inlined in JSON")
+}
+
+func TestPrefersProblem(t *testing.T) {
+	assert.False(t, PrefersProblem(""))
+	assert.False(t, PrefersProblem("*/*"))
+	assert.False(t, PrefersProblem("application/json"))
+	assert.True(t, PrefersProblem("application/problem+json"))
+	assert.True(t, PrefersProblem("application/problem+json, application/json"))
+	assert.True(t, PrefersProblem("application/json;q=0.5, application/problem+json"))
+	assert.False(t, PrefersProblem("application/json, application/problem+json;q=0.5"))
+	assert.True(t, PrefersProblem("application/problem+json;q=0.5, */*;q=0.1"))
+	assert.False(t, PrefersProblem("*/*;q=0.1, application/*, application/problem+json;q=0.5"), "The most specific range counts")
+	assert.False(t, PrefersProblem("application/problem+json;q=0"))
+}
+
+func TestProblemType(t *testing.T) {
+	assert.Equal(t, "/problems/validation-failed", ProblemType(CodeValidationFailed))
+
+	t.Setenv("PROBLEM_TYPE_BASE_URL", "https://api.example.com/problems")
+	assert.Equal(t, "https://api.example.com/problems/not-found", ProblemType(CodeNotFound))
+}
+
+func respond(accept string, respond func(c *gin.Context)) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/v1/authors", func(c *gin.Context) {
+		c.Header("X-Request-ID", "req-1")
+		respond(c)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/authors?dry_run=true", strings.NewReader(`{"tags":["c++"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRespondWithErrorNegotiatesProblems(t *testing.T) {
+	w := respond("application/json", RespondAuthorNotFound)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept", w.Header().Get("Vary"))
+	assert.JSONEq(t, `{"error":{"code":"NOT_FOUND","message":"Author not found","details":"The requested author does not exist or has been deleted"}}`, w.Body.String())
+
+	w = respond("application/problem+json", RespondAuthorNotFound)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "/problems/not-found",
+		"title": "Resource not found",
+		"status": 404,
+		"detail": "Author not found: The requested author does not exist or has been deleted",
+		"instance": "/api/v1/authors#request-req-1",
+		"code": "NOT_FOUND",
+		"errors": []
+	}`, w.Body.String())
+
+	w = respond("application/problem+json", func(c *gin.Context) {
+		RespondWithCustomError(c, http.StatusTooManyRequests, "SLOW_DOWN", "Too many requests", "")
+	})
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, "/problems/slow-down", problem.Type)
+	assert.Equal(t, "Too Many Requests", problem.Title, "Unknown codes are titled by their status")
+	assert.Equal(t, "Too many requests", problem.Detail)
+}
+
+func TestRespondWithBindingError(t *testing.T) {
+	bind := func(c *gin.Context) {
+		var author testAuthor
+		RespondWithBindingError(c, c.ShouldBindJSON(&author))
+	}
+
+	w := respond("", bind)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, CodeValidationFailed, response.Error.Code)
+	assert.Equal(t, "name is required; tags[0] must only contain letters and digits", response.Error.Details)
+	assert.Len(t, response.Error.Errors, 2)
+
+	w = respond("application/problem+json", bind)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var problem Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, "/problems/validation-failed", problem.Type)
+	assert.Equal(t, "Validation failed", problem.Title)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, []FieldError{
+		{Field: "name", Rule: "required", Message: "is required"},
+		{Field: "tags[0]", Rule: "alphanum", Message: "must only contain letters and digits"},
+	}, problem.Errors)
+}
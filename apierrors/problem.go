@@ -0,0 +1,133 @@
+package apierrors
+
+import (
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the media type of RFC 9457 problem details documents
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details document, sent instead of an
+// ErrorResponse to clients preferring application/problem+json
+type Problem struct {
+	Type     string       `json:"type"`     // URI identifying the error code, see ProblemType
+	Title    string       `json:"title"`    // Summary of the error code
+	Status   int          `json:"status"`   // HTTP status code
+	Detail   string       `json:"detail"`   // Explanation of this occurrence
+	Instance string       `json:"instance"` // Request path and ID, e.g. "/api/v1/posts/x#request-3f2a"
+	Code     string       `json:"code"`     // Error code of the ErrorResponse shape
+	Errors   []FieldError `json:"errors"`   // Invalid fields, empty unless validation failed
+}
+
+// problemTitles summarise the error codes. Codes missing here are titled by
+// their HTTP status.
+var problemTitles = map[string]string{
+	CodeBadRequest:            "Bad request",
+	CodeNotFound:              "Resource not found",
+	CodeConflict:              "Conflict with the current state of the resource",
+	CodeValidationFailed:      "Validation failed",
+	CodeUnauthorized:          "Authentication required",
+	CodeForbidden:             "Operation not permitted",
+	CodePreconditionFailed:    "Precondition failed",
+	CodePreconditionRequired:  "Precondition required",
+	CodeUnsupportedMediaType:  "Unsupported media type",
+	CodeInvalidPatch:          "Invalid patch document",
+	CodeInvalidImport:         "Invalid import file",
+	CodeInvalidUpload:         "Invalid upload",
+	CodePayloadTooLarge:       "Payload too large",
+	CodeInternalError:         "Internal server error",
+	CodeDatabaseError:         "Database error",
+	"INVALID_INPUT":           "Invalid input",
+	"RATE_LIMIT_EXCEEDED":     "Rate limit exceeded",
+	"SERVER_MISCONFIGURATION": "Server misconfiguration",
+}
+
+// ProblemType returns the type URI of an error code: PROBLEM_TYPE_BASE_URL
+// (default "/problems/") followed by the code in kebab case, e.g.
+// "/problems/validation-failed" for VALIDATION_FAILED
+func ProblemType(code string) string {
+	base := os.Getenv("PROBLEM_TYPE_BASE_URL")
+	if base == "" {
+		base = "/problems/"
+	} else if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	return base + strings.ReplaceAll(strings.ToLower(code), "_", "-")
+}
+
+// NewProblem converts an error of a request into a problem details document
+func NewProblem(c *gin.Context, statusCode int, apiError APIError) Problem {
+	title, ok := problemTitles[apiError.Code]
+	if !ok {
+		title = http.StatusText(statusCode)
+	}
+	detail := apiError.Message
+	if apiError.Details != "" {
+		detail += ": " + apiError.Details
+	}
+	fieldErrors := apiError.Errors
+	if fieldErrors == nil {
+		fieldErrors = []FieldError{}
+	}
+
+	return Problem{
+		Type:     ProblemType(apiError.Code),
+		Title:    title,
+		Status:   statusCode,
+		Detail:   detail,
+		Instance: problemInstance(c),
+		Code:     apiError.Code,
+		Errors:   fieldErrors,
+	}
+}
+
+// problemInstance identifies an occurrence of an error by the request path and
+// the request ID echoed in X-Request-ID
+func problemInstance(c *gin.Context) string {
+	instance := c.Request.URL.Path
+	if requestID := c.Writer.Header().Get("X-Request-ID"); requestID != "" {
+		instance += "#request-" + requestID
+	}
+	return instance
+}
+
+// jsonRangeSpecificity ranks the media ranges of an Accept header matching
+// application/json, the most specific one being the highest
+var jsonRangeSpecificity = map[string]int{"*/*": 1, "application/*": 2, "application/json": 3}
+
+// PrefersProblem reports whether an Accept header prefers
+// application/problem+json to application/json. Clients that don't list
+// application/problem+json get the ErrorResponse shape.
+func PrefersProblem(accept string) bool {
+	problemQuality, jsonQuality, jsonSpecificity := -1.0, -1.0, 0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				parsed = 0
+			}
+			quality = parsed
+		}
+
+		if mediaType == ProblemContentType {
+			problemQuality = quality
+			continue
+		}
+		// The most specific range matching application/json sets its quality
+		if specificity := jsonRangeSpecificity[mediaType]; specificity > jsonSpecificity {
+			jsonQuality, jsonSpecificity = quality, specificity
+		}
+	}
+	return problemQuality > 0 && problemQuality >= jsonQuality
+}
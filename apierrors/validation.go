@@ -0,0 +1,160 @@
+package apierrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes a field of a request body that failed validation
+type FieldError struct {
+	Field   string `json:"field"`   // Path of the field, e.g. "title" or "social_links[0].url"
+	Rule    string `json:"rule"`    // Validation rule that failed, e.g. "required" or "max"
+	Message string `json:"message"` // Human-readable description of the failure
+}
+
+// embeddedField names embedded structs without a JSON key in validator
+// namespaces. Their fields are inlined in JSON, so fieldPath drops it.
+const embeddedField = "~embedded"
+
+func init() {
+	// Name fields by their JSON keys in validation errors, so that they match
+	// the request bodies rather than the Go structs
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" && field.Anonymous {
+				return embeddedField
+			}
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// FieldErrors breaks an error of ShouldBindJSON or binding.Validator down per
+// field. Errors that aren't about a field, such as malformed JSON, return nil.
+func FieldErrors(err error) []FieldError {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fieldErrors := make([]FieldError, 0, len(validationErrors))
+		for _, fieldError := range validationErrors {
+			fieldErrors = append(fieldErrors, FieldError{
+				Field:   fieldPath(fieldError.Namespace()),
+				Rule:    fieldError.Tag(),
+				Message: ruleMessage(fieldError),
+			})
+		}
+		return fieldErrors
+	}
+
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) && typeError.Field != "" {
+		return []FieldError{{
+			Field:   typeError.Field,
+			Rule:    "type",
+			Message: "must be " + jsonTypeName(typeError.Type),
+		}}
+	}
+	return nil
+}
+
+// RespondWithBindingError sends a validation error response for a request body
+// that ShouldBindJSON or binding.Validator rejected, listing the invalid fields
+func RespondWithBindingError(c *gin.Context, err error) {
+	fieldErrors := FieldErrors(err)
+	details := err.Error()
+	if errors.Is(err, io.EOF) {
+		details = "request body is empty"
+	}
+	if len(fieldErrors) > 0 {
+		messages := make([]string, 0, len(fieldErrors))
+		for _, fieldError := range fieldErrors {
+			messages = append(messages, fieldError.Field+" "+fieldError.Message)
+		}
+		details = strings.Join(messages, "; ")
+	}
+
+	apiError := APIError{
+		Code:    CodeValidationFailed,
+		Message: "Request validation failed",
+		Details: details,
+		Errors:  fieldErrors,
+	}
+	RespondWithError(c, http.StatusBadRequest, apiError)
+}
+
+// fieldPath drops the struct name and embedded structs from a validator
+// namespace, turning "Author.social_links[0].url" into "social_links[0].url"
+// and "postUpdateRequest.~embedded.title" into "title"
+func fieldPath(namespace string) string {
+	segments := strings.Split(namespace, ".")
+	path := make([]string, 0, len(segments))
+	for i, segment := range segments {
+		if (i > 0 || len(segments) == 1) && segment != embeddedField {
+			path = append(path, segment)
+		}
+	}
+	return strings.Join(path, ".")
+}
+
+// ruleMessage describes the failure of a validation rule
+func ruleMessage(fieldError validator.FieldError) string {
+	param := fieldError.Param()
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "min", "max":
+		bound := "at least"
+		if fieldError.Tag() == "max" {
+			bound = "at most"
+		}
+		switch fieldError.Kind() {
+		case reflect.String:
+			return fmt.Sprintf("must be %s %s characters long", bound, param)
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return fmt.Sprintf("must have %s %s items", bound, param)
+		default:
+			return fmt.Sprintf("must be %s %s", bound, param)
+		}
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "alphanum":
+		return "must only contain letters and digits"
+	default:
+		return fmt.Sprintf("failed the '%s' rule", fieldError.Tag())
+	}
+}
+
+// jsonTypeName names the JSON type a Go type is decoded from
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"time"
+
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CollectionName is the append-only collection holding audit entries
+const CollectionName = "audit_log"
+
+// Audited actions
+const (
+	ActionPostCreate  = "post.create"
+	ActionPostUpdate  = "post.update"
+	ActionPostDelete  = "post.delete"
+	ActionMediaUpload = "media.upload"
+	ActionMediaDelete = "media.delete"
+	ActionAuthFailure = "auth.failure"
+)
+
+// ignoredFields are excluded from diffs because they change outside admin actions
+var ignoredFields = map[string]bool{
+	"_id":        true,
+	"updated_at": true,
+	"views":      true,
+	"likes":      true,
+	"version":    true,
+}
+
+// Record appends an entry to the audit log. Failures are logged but never
+// interrupt the request being audited.
+func Record(entry models.AuditEntry) {
+	if database.Database == nil {
+		return
+	}
+
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := database.Database.Collection(CollectionName).InsertOne(ctx, entry); err != nil {
+		log.Printf("[ERROR] audit.Record: Failed to record '%s' for request %s - %s", entry.Action, entry.RequestID, err.Error())
+	}
+}
+
+// Diff returns the field-level changes between two documents, keyed by their
+// BSON field names. A nil before or after yields changes against an empty document.
+func Diff(before, after interface{}) map[string]models.FieldChange {
+	beforeDoc := toDocument(before)
+	afterDoc := toDocument(after)
+
+	changes := make(map[string]models.FieldChange)
+	for field, afterValue := range afterDoc {
+		if ignoredFields[field] {
+			continue
+		}
+		beforeValue, existed := beforeDoc[field]
+		if !existed || !reflect.DeepEqual(beforeValue, afterValue) {
+			changes[field] = models.FieldChange{Before: beforeValue, After: afterValue}
+		}
+	}
+	for field, beforeValue := range beforeDoc {
+		if ignoredFields[field] {
+			continue
+		}
+		if _, exists := afterDoc[field]; !exists {
+			changes[field] = models.FieldChange{Before: beforeValue, After: nil}
+		}
+	}
+
+	return changes
+}
+
+// toDocument converts a value to its BSON document representation
+func toDocument(value interface{}) bson.M {
+	doc := bson.M{}
+	if value == nil {
+		return doc
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Ptr && v.IsNil() {
+		return doc
+	}
+
+	data, err := bson.Marshal(value)
+	if err != nil {
+		log.Printf("[ERROR] audit.Diff: Failed to marshal document - %s", err.Error())
+		return doc
+	}
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		log.Printf("[ERROR] audit.Diff: Failed to unmarshal document - %s", err.Error())
+	}
+	return doc
+}
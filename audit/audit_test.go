@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"dbl-blog-backend/models"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Unit tests for audit log diffs
+
+func TestDiff_ChangedFields(t *testing.T) {
+	before := &models.Post{
+		ID:        primitive.NewObjectID(),
+		Title:     "Original title",
+		Content:   "Same content",
+		Slug:      "original-title",
+		Tags:      []string{"go"},
+		Published: false,
+		Views:     10,
+		UpdatedAt: time.Now().Add(-time.Hour),
+	}
+	after := *before
+	after.Title = "Updated title"
+	after.Tags = []string{"go", "mongodb"}
+	after.Published = true
+	after.Views = 11
+	after.UpdatedAt = time.Now()
+
+	changes := Diff(before, &after)
+
+	assert.Len(t, changes, 3, "Only title, tags and published should be reported")
+	assert.Equal(t, "Original title", changes["title"].Before)
+	assert.Equal(t, "Updated title", changes["title"].After)
+	assert.Equal(t, false, changes["published"].Before)
+	assert.Equal(t, true, changes["published"].After)
+	assert.NotContains(t, changes, "content")
+	assert.NotContains(t, changes, "views", "Counters change outside admin actions")
+	assert.NotContains(t, changes, "updated_at", "Timestamps change on every update")
+}
+
+func TestDiff_CreateAndDelete(t *testing.T) {
+	post := &models.Post{
+		Title:   "New post",
+		Content: "Content",
+		Slug:    "new-post",
+	}
+
+	created := Diff(nil, post)
+	assert.Equal(t, "New post", created["title"].After)
+	assert.Nil(t, created["title"].Before)
+
+	var noPost *models.Post
+	deleted := Diff(post, noPost)
+	assert.Equal(t, "new-post", deleted["slug"].Before)
+	assert.Nil(t, deleted["slug"].After)
+}
+
+func TestDiff_NoChanges(t *testing.T) {
+	post := &models.Post{Title: "Unchanged", Content: "Content", Slug: "unchanged"}
+	assert.Empty(t, Diff(post, post))
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+)
+
+// usage describes the available commands
+const usage = `Usage: dbl-blog-backend [command]
+
+Without a command, the API server is started.
+
+Commands:
+  import [-dry-run] [-format markdown|wordpress] <file>
+      Upsert posts from a zip, tar or tar.gz archive of Markdown files,
+      or from a WordPress WXR export (the default for .xml files)
+`
+
+// Run executes a command-line command and returns the process exit code
+func Run(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	switch args[0] {
+	case "import":
+		return runImport(args[1:])
+	case "help", "-h", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command '%s'\n\n%s", args[0], usage)
+		return 2
+	}
+}
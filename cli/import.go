@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/importer"
+	"dbl-blog-backend/webhooks"
+)
+
+// Import source formats
+const (
+	formatMarkdown  = "markdown"
+	formatWordPress = "wordpress"
+)
+
+// runImport imports posts from a local Markdown archive or WordPress export and
+// prints the report as JSON
+func runImport(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "report what would be imported without writing anything")
+	format := flags.String("format", "", "source format, markdown or wordpress (default: wordpress for .xml files, otherwise markdown)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: dbl-blog-backend import [-dry-run] [-format markdown|wordpress] <file>")
+		return 2
+	}
+
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = formatMarkdown
+		if strings.EqualFold(filepath.Ext(path), ".xml") {
+			*format = formatWordPress
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("[ERROR] import: Failed to read '%s' - %s", path, err.Error())
+		return 1
+	}
+
+	var candidates []importer.Candidate
+	var wordpressReport importer.WordPressReport
+	switch *format {
+	case formatMarkdown:
+		files, err := importer.ReadArchive(data)
+		if err != nil {
+			log.Printf("[ERROR] import: Failed to read archive '%s' - %s", path, err.Error())
+			return 1
+		}
+		candidates = importer.ParseMarkdown(files)
+	case formatWordPress:
+		candidates, wordpressReport, err = importer.ParseWordPress(bytes.NewReader(data))
+		if err != nil {
+			log.Printf("[ERROR] import: Failed to parse WordPress export '%s' - %s", path, err.Error())
+			return 1
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown import format '%s'\n", *format)
+		return 2
+	}
+
+	database.Connect()
+	database.CreateIndexes()
+
+	report := importer.Import(context.Background(), candidates, importer.Options{DryRun: *dryRun, Events: webhooks.EnqueuePostEvents})
+	if *format == formatWordPress {
+		wordpressReport.Report = report
+		return printReport(wordpressReport, report)
+	}
+	return printReport(report, report)
+}
+
+// printReport writes an import report to stdout, returning a non-zero exit code
+// when any file could not be imported
+func printReport(output interface{}, report importer.Report) int {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(output); err != nil {
+		log.Printf("[ERROR] import: Failed to write report - %s", err.Error())
+		return 1
+	}
+
+	log.Printf("[SUCCESS] import: Processed %d posts (dry run: %t) - %d created, %d updated, %d unchanged, %d invalid, %d failed",
+		report.Total, report.DryRun, report.Created, report.Updated, report.Unchanged, report.Invalid, report.Failed)
+	if report.Invalid > 0 || report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -2,11 +2,18 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"time"
 
+	"dbl-blog-backend/locale"
+	"dbl-blog-backend/markdown"
+	"dbl-blog-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -16,6 +23,9 @@ var (
 	Database *mongo.Database
 )
 
+// auditRetentionIndex is the name of the TTL index expiring old audit log entries
+const auditRetentionIndex = "audit_log_retention"
+
 // Connect initializes the MongoDB connection
 func Connect() {
 	var err error
@@ -105,19 +115,238 @@ func Connect() {
 func CreateIndexes() {
 	ctx := context.Background()
 
-	// Create unique index on post slug
+	// Give posts written before translations existed the default language and
+	// a translation group of their own
 	postsCollection := Database.Collection("posts")
+	backfillPostLanguages(ctx)
+
+	// Create unique index on post slug per language, so translations can share
+	// a slug. It replaces the former index on slug alone.
+	dropPostIndex(ctx, "slug_1")
 	_, err := postsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
-		Keys:    map[string]int{"slug": 1},
+		Keys:    bson.D{{Key: "lang", Value: 1}, {Key: "slug", Value: 1}},
 		Options: options.Index().SetUnique(true),
 	})
 	if err != nil {
 		log.Printf("Warning: Failed to create slug index: %v", err)
 	}
 
+	// Create unique index across current and previous post slugs per language,
+	// so a slug can't be reused while it still redirects to another post
+	backfillPostSlugs(ctx)
+	backfillPostMetadata(ctx)
+	dropPostIndex(ctx, "slugs_1")
+	_, err = postsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "lang", Value: 1}, {Key: "slugs", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"slugs": bson.M{"$exists": true}}),
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to create slug history index: %v", err)
+	}
+
+	// Create unique index on translation groups, allowing one post per language
+	_, err = postsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "translation_group_id", Value: 1}, {Key: "lang", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to create translation group index: %v", err)
+	}
+
+	// Create index on post authors for author filtering
+	_, err = postsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: map[string]int{"author_ids": 1},
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to create post authors index: %v", err)
+	}
+
+	// Create indexes backing each sort order of post lists, with _id breaking
+	// ties. Indexes serve both directions.
+	sortIndexes := make([]mongo.IndexModel, 0, len(models.PostSortFields))
+	for _, field := range models.PostSortFields {
+		sortIndexes = append(sortIndexes, mongo.IndexModel{Keys: bson.D{{Key: field, Value: -1}, {Key: "_id", Value: -1}}})
+	}
+	_, err = postsCollection.Indexes().CreateMany(ctx, sortIndexes)
+	if err != nil {
+		log.Printf("Warning: Failed to create post sort indexes: %v", err)
+	}
+
+	// Create index on publication and creation date for the archive
+	_, err = postsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "published", Value: 1}, {Key: "created_at", Value: 1}},
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to create post archive index: %v", err)
+	}
+
+	// Create text index for GraphQL search, favouring matches in titles and tags
+	_, err = postsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "title", Value: "text"}, {Key: "tags", Value: "text"}, {Key: "summary", Value: "text"}, {Key: "content", Value: "text"}},
+		Options: options.Index().
+			SetName("posts_text").
+			SetWeights(bson.M{"title": 10, "tags": 5, "summary": 3, "content": 1}).
+			SetDefaultLanguage("none"),
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to create post text index: %v", err)
+	}
+
+	// Create unique indexes on author slug and mapped API key IDs
+	authorsCollection := Database.Collection("authors")
+	_, err = authorsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    map[string]int{"slug": 1},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to create author slug index: %v", err)
+	}
+
+	// Authors without API keys are left out of the key index. It replaces a
+	// sparse index, which indexed the null and empty key lists of such authors.
+	_, err = authorsCollection.Indexes().DropOne(ctx, "api_key_ids_1")
+	if err != nil && !isIndexNotFound(err) {
+		log.Printf("Warning: Failed to drop author index api_key_ids_1: %v", err)
+	}
+	_, err = authorsCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: map[string]int{"api_key_ids": 1},
+		Options: options.Index().SetName("api_key_ids_partial").SetUnique(true).
+			SetPartialFilterExpression(bson.M{"api_key_ids": bson.M{"$type": "string"}}),
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to create author API key index: %v", err)
+	}
+
+	// Create unique indexes on series slug and parts, so that a post belongs to at
+	// most one series. Series without parts are left out of the parts index.
+	seriesCollection := Database.Collection("series")
+	_, err = seriesCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    map[string]int{"slug": 1},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to create series slug index: %v", err)
+	}
+
+	_, err = seriesCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: map[string]int{"post_ids": 1},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"post_ids": bson.M{"$type": "objectId"}}),
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to create series posts index: %v", err)
+	}
+
+	// Create index on media upload time for listing newest first
+	_, err = Database.Collection("media").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "created_at", Value: -1}},
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to create media index: %v", err)
+	}
+
+	createAuditLogIndexes(ctx)
+
+	// Create indexes for the webhook dispatcher and delivery log. Completed
+	// deliveries expire after 30 days; pending ones have no completion time.
+	_, err = Database.Collection("webhook_deliveries").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+		{Keys: bson.D{{Key: "webhook_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{
+			Keys:    bson.D{{Key: "completed_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(30 * 24 * 60 * 60)),
+		},
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to create webhook delivery indexes: %v", err)
+	}
+
+	// Create indexes for newsletter subscribers and issues. An address subscribes
+	// once, and a post is sent once.
+	subscribersCollection := Database.Collection("subscribers")
+	_, err = subscribersCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "unsubscribe_token", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{
+			Keys:    bson.D{{Key: "confirm_token_hash", Value: 1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"confirm_token_hash": bson.M{"$exists": true}}),
+		},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "_id", Value: 1}}},
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to create subscriber indexes: %v", err)
+	}
+
+	_, err = Database.Collection("newsletter_issues").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "post_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}}},
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to create newsletter issue indexes: %v", err)
+	}
+
 	log.Println("Database indexes created successfully")
 }
 
+// createAuditLogIndexes creates the audit log query indexes and the TTL index
+// that expires entries after AUDIT_LOG_RETENTION_DAYS (0 keeps entries forever)
+func createAuditLogIndexes(ctx context.Context) {
+	auditCollection := Database.Collection("audit_log")
+
+	_, err := auditCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "action", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "actor_key_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "target_post_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "request_id", Value: 1}}},
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to create audit log indexes: %v", err)
+	}
+
+	retentionDays := 90
+	if value := os.Getenv("AUDIT_LOG_RETENTION_DAYS"); value != "" {
+		if days, err := strconv.Atoi(value); err == nil && days >= 0 {
+			retentionDays = days
+		}
+	}
+
+	if retentionDays == 0 {
+		_, err = auditCollection.Indexes().DropOne(ctx, auditRetentionIndex)
+		if err != nil && !isIndexNotFound(err) {
+			log.Printf("Warning: Failed to drop audit log retention index: %v", err)
+		}
+		return
+	}
+
+	expireAfter := int32(retentionDays * 24 * 60 * 60)
+	_, err = auditCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "created_at", Value: 1}},
+		Options: options.Index().SetName(auditRetentionIndex).SetExpireAfterSeconds(expireAfter),
+	})
+	if err == nil {
+		return
+	}
+
+	// The retention period changed: update the existing TTL index in place
+	err = Database.RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: "audit_log"},
+		{Key: "index", Value: bson.D{
+			{Key: "name", Value: auditRetentionIndex},
+			{Key: "expireAfterSeconds", Value: expireAfter},
+		}},
+	}).Err()
+	if err != nil {
+		log.Printf("Warning: Failed to update audit log retention index: %v", err)
+	}
+}
+
+// isIndexNotFound reports whether err is MongoDB's IndexNotFound error
+func isIndexNotFound(err error) bool {
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && cmdErr.Code == 27
+}
+
 // Disconnect closes the MongoDB connection
 func Disconnect() {
 	if Client != nil {
@@ -129,3 +358,100 @@ func Disconnect() {
 		}
 	}
 }
+
+// dropPostIndex drops an index of the posts collection that has been replaced,
+// if it still exists
+func dropPostIndex(ctx context.Context, name string) {
+	_, err := Database.Collection("posts").Indexes().DropOne(ctx, name)
+	if err != nil && !isIndexNotFound(err) {
+		log.Printf("Warning: Failed to drop post index %s: %v", name, err)
+	}
+}
+
+// backfillPostLanguages sets the default language on posts created before
+// translations existed, each post starting a translation group of its own
+func backfillPostLanguages(ctx context.Context) {
+	result, err := Database.Collection("posts").UpdateMany(ctx,
+		bson.M{"lang": bson.M{"$exists": false}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{"lang": locale.Default(), "translation_group_id": "$_id"}}}},
+	)
+	if err != nil {
+		log.Printf("Warning: Failed to backfill post languages: %v", err)
+		return
+	}
+	if result.ModifiedCount > 0 {
+		log.Printf("Backfilled language for %d posts", result.ModifiedCount)
+	}
+}
+
+// backfillPostSlugs seeds the slug history of posts created before it existed with their current slug
+func backfillPostSlugs(ctx context.Context) {
+	result, err := Database.Collection("posts").UpdateMany(ctx,
+		bson.M{"slugs": bson.M{"$exists": false}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{"slugs": bson.A{"$slug"}}}}},
+	)
+	if err != nil {
+		log.Printf("Warning: Failed to backfill post slug history: %v", err)
+		return
+	}
+	if result.ModifiedCount > 0 {
+		log.Printf("Backfilled slug history for %d posts", result.ModifiedCount)
+	}
+}
+
+// backfillPostMetadata computes the word count, reading time and table of contents
+// of posts created before they were derived on every write
+func backfillPostMetadata(ctx context.Context) {
+	collection := Database.Collection("posts")
+	cursor, err := collection.Find(ctx,
+		bson.M{"toc": bson.M{"$exists": false}},
+		options.Find().SetProjection(bson.M{"content": 1}))
+	if err != nil {
+		log.Printf("Warning: Failed to find posts for metadata backfill: %v", err)
+		return
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	const batchSize = 500
+	var writes []mongo.WriteModel
+	backfilled := 0
+	flush := func() bool {
+		if len(writes) == 0 {
+			return true
+		}
+		result, err := collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+		if err != nil {
+			log.Printf("Warning: Failed to backfill post metadata: %v", err)
+			return false
+		}
+		backfilled += int(result.ModifiedCount)
+		writes = writes[:0]
+		return true
+	}
+
+	for cursor.Next(ctx) {
+		var post models.Post
+		if err := cursor.Decode(&post); err != nil {
+			log.Printf("Warning: Failed to decode post for metadata backfill: %v", err)
+			continue
+		}
+		markdown.ApplyMetadata(&post)
+		// Only posts still missing metadata are updated, in case a write got there first
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": post.ID, "toc": bson.M{"$exists": false}}).
+			SetUpdate(bson.M{"$set": bson.M{
+				"word_count":           post.WordCount,
+				"reading_time_minutes": post.ReadingTimeMinutes,
+				"toc":                  post.TOC,
+			}}))
+		if len(writes) == batchSize && !flush() {
+			return
+		}
+	}
+	if !flush() {
+		return
+	}
+	if backfilled > 0 {
+		log.Printf("Backfilled content metadata for %d posts", backfilled)
+	}
+}
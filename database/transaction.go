@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync/atomic"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// transactionsUnsupported is set once the deployment has rejected a transaction,
+// so that later writes don't retry one
+var transactionsUnsupported atomic.Bool
+
+// WithTransaction runs fn in a multi-document transaction, retrying transient
+// errors, so that all of its writes are committed together or not at all. fn must
+// use the context it is given for every operation.
+//
+// Transactions need a replica set or sharded cluster. On a standalone server, as
+// used in local development, fn runs without a transaction instead.
+func WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if transactionsUnsupported.Load() {
+		return fn(ctx)
+	}
+
+	session, err := Client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessionCtx)
+	})
+	if isTransactionsUnsupported(err) {
+		if transactionsUnsupported.CompareAndSwap(false, true) {
+			log.Printf("Warning: MongoDB deployment doesn't support transactions, writing without them")
+		}
+		return fn(ctx)
+	}
+	return err
+}
+
+// isTransactionsUnsupported reports whether err is the IllegalOperation error a
+// standalone server returns for transactions. It is raised by the first
+// operation, before anything is written.
+func isTransactionsUnsupported(err error) bool {
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && cmdErr.Code == 20
+}
@@ -0,0 +1,138 @@
+// Package dispatch executes requests on the REST API in process, on behalf of
+// the GraphQL and gRPC APIs. Their writes go through the REST handlers and
+// middleware rather than reimplementing them, so that all APIs authenticate,
+// authorize, rate limit, validate and audit them the same way.
+package dispatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"dbl-blog-backend/apierrors"
+)
+
+// forwardedHeaders are the headers of the original request carried by the REST
+// requests made on its behalf, so that they are authenticated, rate limited and
+// audited as the original request itself
+var forwardedHeaders = []string{"X-API-Key", "User-Agent", "X-Forwarded-For", "X-Real-IP", "Accept-Language"}
+
+// Dispatcher sends requests to the REST API on behalf of one original request
+type Dispatcher struct {
+	handler    http.Handler
+	remoteAddr string
+	header     http.Header
+}
+
+// New returns a dispatcher of requests to handler on behalf of a request with
+// the given headers and remote address, sharing its request ID
+func New(handler http.Handler, header http.Header, remoteAddr, requestID string) *Dispatcher {
+	forwarded := http.Header{}
+	for _, name := range forwardedHeaders {
+		for _, value := range header.Values(name) {
+			forwarded.Add(name, value)
+		}
+	}
+	if requestID != "" {
+		forwarded.Set("X-Request-ID", requestID)
+	}
+	return &Dispatcher{handler: handler, remoteAddr: remoteAddr, header: forwarded}
+}
+
+// Do sends a request with a JSON body, when body isn't nil, and decodes the JSON
+// response into out. Error responses are returned as *Error with the REST API's
+// code, message and status.
+func (d *Dispatcher) Do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header = d.header.Clone()
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.RemoteAddr = d.remoteAddr
+
+	w := &responseBuffer{header: http.Header{}}
+	d.handler.ServeHTTP(w, req)
+
+	if w.status >= http.StatusBadRequest {
+		return responseError(w.status, w.body.Bytes())
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(w.body.Bytes(), out)
+}
+
+// Error is an error response of the REST API
+type Error struct {
+	Message string
+	Code    string
+	Status  int
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// responseError converts an error response of the REST API into an *Error
+func responseError(status int, body []byte) *Error {
+	var structured apierrors.ErrorResponse
+	if err := json.Unmarshal(body, &structured); err == nil && structured.Error.Code != "" {
+		message := structured.Error.Message
+		if structured.Error.Details != "" {
+			message += ": " + structured.Error.Details
+		}
+		return &Error{Message: message, Code: structured.Error.Code, Status: status}
+	}
+
+	// Some failures are reported as a plain message
+	var plain struct {
+		Error string `json:"error"`
+	}
+	message := http.StatusText(status)
+	if err := json.Unmarshal(body, &plain); err == nil && plain.Error != "" {
+		message = plain.Error
+	}
+	code := apierrors.CodeInternalError
+	if status < http.StatusInternalServerError {
+		code = apierrors.CodeBadRequest
+	}
+	return &Error{Message: message, Code: code, Status: status}
+}
+
+// responseBuffer is an http.ResponseWriter keeping the response in memory
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseBuffer) Header() http.Header {
+	return w.header
+}
+
+func (w *responseBuffer) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(data)
+}
+
+func (w *responseBuffer) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
@@ -0,0 +1,81 @@
+package dispatch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"dbl-blog-backend/apierrors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for in-process dispatch to the REST API
+
+func TestDispatcherForwardsRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/v1/posts", func(c *gin.Context) {
+		var body map[string]interface{}
+		require.NoError(t, c.ShouldBindJSON(&body))
+		c.JSON(http.StatusCreated, gin.H{
+			"title":      body["title"],
+			"api_key":    c.GetHeader("X-API-Key"),
+			"request_id": c.GetHeader("X-Request-ID"),
+			"client_ip":  c.ClientIP(),
+			"cookie":     c.GetHeader("Cookie"),
+		})
+	})
+
+	original := httptest.NewRequest(http.MethodPost, "/api/v1/graphql", nil)
+	original.Header.Set("X-API-Key", "secret")
+	original.Header.Set("Cookie", "session=1")
+	original.RemoteAddr = "203.0.113.7:4321"
+
+	var out map[string]string
+	dispatcher := New(router, original.Header, original.RemoteAddr, "req-1")
+	err := dispatcher.Do(context.Background(), http.MethodPost, "/api/v1/posts", map[string]string{"title": "Hello"}, &out)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hello", out["title"])
+	assert.Equal(t, "secret", out["api_key"])
+	assert.Equal(t, "req-1", out["request_id"])
+	assert.Equal(t, "203.0.113.7", out["client_ip"])
+	assert.Empty(t, out["cookie"], "only listed headers are forwarded")
+}
+
+func TestDispatcherErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PUT("/api/v1/posts/:id/like", func(c *gin.Context) {
+		apierrors.RespondPostNotFound(c)
+	})
+	router.DELETE("/api/v1/posts/:id", func(c *gin.Context) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded"})
+	})
+	router.PUT("/api/v1/posts/:id/view", func(c *gin.Context) {
+		c.Status(http.StatusBadGateway)
+	})
+	dispatcher := New(router, http.Header{}, "192.0.2.1:1234", "")
+
+	err := dispatcher.Do(context.Background(), http.MethodPut, "/api/v1/posts/1/like", nil, nil)
+	assert.Equal(t, &Error{
+		Message: apierrors.ErrPostNotFound.Message + ": " + apierrors.ErrPostNotFound.Details,
+		Code:    apierrors.CodeNotFound,
+		Status:  http.StatusNotFound,
+	}, err)
+
+	err = dispatcher.Do(context.Background(), http.MethodDelete, "/api/v1/posts/1", nil, nil)
+	assert.Equal(t, &Error{Message: "Rate limit exceeded", Code: apierrors.CodeBadRequest, Status: http.StatusTooManyRequests}, err)
+
+	err = dispatcher.Do(context.Background(), http.MethodPut, "/api/v1/posts/1/view", nil, nil)
+	assert.Equal(t, &Error{Message: "Bad Gateway", Code: apierrors.CodeInternalError, Status: http.StatusBadGateway}, err)
+
+	err = dispatcher.Do(context.Background(), http.MethodGet, "/missing", nil, nil)
+	var gqlErr *Error
+	require.ErrorAs(t, err, &gqlErr)
+	assert.Equal(t, http.StatusNotFound, gqlErr.Status)
+}
@@ -4,15 +4,21 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"dbl-blog-backend/apierrors"
 	"dbl-blog-backend/database"
 	"dbl-blog-backend/models"
+	"dbl-blog-backend/newsletter"
 
 	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/assert"
@@ -29,8 +35,16 @@ const (
 	apiKeyHeader      = "X-API-Key"
 	updatedTitle      = "Updated E2E Test Post"
 	updatedContent    = "Updated content via E2E test"
+	authorsEndpoint   = "/api/v1/authors"
+	mediaEndpoint     = "/api/v1/media"
+	seriesEndpoint    = "/api/v1/series"
+	archiveEndpoint   = "/api/v1/archive"
+	graphQLEndpoint   = "/api/v1/graphql"
 )
 
+// e2eCollections lists the collections dropped before and after each E2E test
+var e2eCollections = []string{"posts", "authors", "media", "series", "related_posts", "webhooks", "webhook_deliveries", "subscribers", "newsletter_issues"}
+
 // getAPIBaseURL returns the API base URL from environment or default
 func getAPIBaseURL() string {
 	if url := os.Getenv("API_BASE_URL"); url != "" {
@@ -100,7 +114,9 @@ func setupE2ETestDB() func() {
 	if database.Database != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		_ = database.Database.Collection("posts").Drop(ctx) // Ignore error in test cleanup
+		for _, name := range e2eCollections {
+			_ = database.Database.Collection(name).Drop(ctx) // Ignore error in test cleanup
+		}
 	}
 
 	return func() {
@@ -108,7 +124,9 @@ func setupE2ETestDB() func() {
 		if database.Database != nil {
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
-			_ = database.Database.Collection("posts").Drop(ctx) // Ignore error in test cleanup
+			for _, name := range e2eCollections {
+				_ = database.Database.Collection(name).Drop(ctx) // Ignore error in test cleanup
+			}
 		}
 
 		// Disconnect using the real API's disconnect function
@@ -215,6 +233,7 @@ func TestE2EGetPosts(t *testing.T) {
 			Title:     "E2E Test Post 1",
 			Content:   "Content 1 for E2E testing",
 			Slug:      "e2e-test-post-1",
+			Tags:      []string{"go", "mongo"},
 			Published: true,
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
@@ -268,6 +287,23 @@ func TestE2EGetPosts(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, float64(1), response["total"])
 	}
+
+	// Test filtering by tag
+	req, _ = http.NewRequest("GET", getAPIBaseURL()+postsEndpoint+"?tag=go", nil)
+	resp, err = client.Do(req)
+	assert.NoError(t, err)
+	if resp != nil {
+		defer func() { _ = resp.Body.Close() }()
+	}
+
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response map[string]interface{}
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(1), response["total"])
+	}
 }
 
 // TestE2ELikePost tests the like post endpoint against live API
@@ -571,6 +607,7 @@ func TestE2EUpdatePost(t *testing.T) {
 		"summary":   "Updated summary",
 		"tags":      []string{"updated", "e2e", "test"},
 		"published": true,
+		"version":   0, // Posts inserted without a version are at version 0
 	}
 
 	updateJSON, _ := json.Marshal(updateData)
@@ -605,6 +642,7 @@ func TestE2EUpdatePost(t *testing.T) {
 		assert.Equal(t, updatedTitle, response.Title)
 		assert.Equal(t, updatedContent, response.Content)
 		assert.Equal(t, true, response.Published)
+		assert.Equal(t, int64(1), response.Version)
 	}
 
 	// Verify the update was persisted in database
@@ -749,6 +787,1369 @@ func TestE2EDeletePostWithoutAuth(t *testing.T) {
 	}
 }
 
+// TestE2EGetAuthorPosts tests listing an author's published posts against live API
+func TestE2EGetAuthorPosts(t *testing.T) {
+	cleanup := setupE2ETestDB()
+	defer cleanup()
+
+	// Create a test author and posts directly in database
+	author := models.Author{
+		Name:      "E2E Author",
+		Slug:      "e2e-author",
+		Bio:       "Writes E2E tests",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	authorResult, err := database.Database.Collection("authors").InsertOne(context.Background(), author)
+	assert.NoError(t, err)
+	authorID := authorResult.InsertedID.(primitive.ObjectID)
+
+	testPosts := []interface{}{
+		models.Post{
+			Title:     "E2E Authored Post",
+			Content:   "Content written by the E2E author",
+			Slug:      "e2e-authored-post",
+			AuthorIDs: []primitive.ObjectID{authorID},
+			Published: true,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+		models.Post{
+			Title:     "E2E Authored Draft",
+			Content:   "Unpublished content by the E2E author",
+			Slug:      "e2e-authored-draft",
+			AuthorIDs: []primitive.ObjectID{authorID},
+			Published: false,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+		models.Post{
+			Title:     "E2E Anonymous Post",
+			Content:   "Content without an author",
+			Slug:      "e2e-anonymous-post",
+			Published: true,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		},
+	}
+	_, err = database.Database.Collection("posts").InsertMany(context.Background(), testPosts)
+	assert.NoError(t, err)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	// Only the author's published post should be returned
+	req, _ := http.NewRequest("GET", getAPIBaseURL()+authorsEndpoint+"/e2e-author/posts", nil)
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	if resp != nil {
+		defer func() { _ = resp.Body.Close() }()
+	}
+
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var response map[string]interface{}
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(1), response["total"])
+	}
+
+	// Unknown authors should return 404
+	req, _ = http.NewRequest("GET", getAPIBaseURL()+authorsEndpoint+"/no-such-author/posts", nil)
+	resp, err = client.Do(req)
+	assert.NoError(t, err)
+	if resp != nil {
+		defer func() { _ = resp.Body.Close() }()
+	}
+
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+// TestE2EUpdatePostStaleVersion tests optimistic concurrency control against live API
+func TestE2EUpdatePostStaleVersion(t *testing.T) {
+	cleanup := setupE2ETestDB()
+	defer cleanup()
+
+	// Create a test post that has already been edited twice
+	testPost := models.Post{
+		Title:     "E2E Concurrency Test Post",
+		Content:   "Content edited by two editors at once",
+		Slug:      "e2e-concurrency-test-post",
+		Version:   2,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	collection := database.Database.Collection("posts")
+	result, err := collection.InsertOne(context.Background(), testPost)
+	assert.NoError(t, err)
+
+	postID := result.InsertedID.(primitive.ObjectID)
+
+	updateData := map[string]interface{}{
+		"title":   updatedTitle,
+		"content": updatedContent,
+		"slug":    "e2e-concurrency-test-post",
+	}
+	updateJSON, _ := json.Marshal(updateData)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	// An update based on an older version should be rejected
+	req, _ := http.NewRequest("PUT", getAPIBaseURL()+postsEndpoint+"/"+postID.Hex(), bytes.NewBuffer(updateJSON))
+	req.Header.Set(contentTypeHeader, applicationJSON)
+	req.Header.Set(apiKeyHeader, getValidAPIKey())
+	req.Header.Set("If-Match", `"1-000000000000000000000000"`)
+
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	if resp != nil {
+		defer func() { _ = resp.Body.Close() }()
+	}
+
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+		assert.NotEmpty(t, resp.Header.Get("ETag"), "Current ETag should be returned")
+	}
+
+	// An update without any version should be rejected
+	req, _ = http.NewRequest("PUT", getAPIBaseURL()+postsEndpoint+"/"+postID.Hex(), bytes.NewBuffer(updateJSON))
+	req.Header.Set(contentTypeHeader, applicationJSON)
+	req.Header.Set(apiKeyHeader, getValidAPIKey())
+
+	resp, err = client.Do(req)
+	assert.NoError(t, err)
+	if resp != nil {
+		defer func() { _ = resp.Body.Close() }()
+	}
+
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusPreconditionRequired, resp.StatusCode)
+	}
+
+	// Verify the post was not modified
+	var unchangedPost models.Post
+	err = collection.FindOne(context.Background(), bson.M{"_id": postID}).Decode(&unchangedPost)
+	assert.NoError(t, err)
+	assert.Equal(t, testPost.Title, unchangedPost.Title)
+	assert.Equal(t, int64(2), unchangedPost.Version)
+}
+
+func TestE2EPatchPost(t *testing.T) {
+	cleanup := setupE2ETestDB()
+	defer cleanup()
+
+	testPost := models.Post{
+		Title:     "E2E Patch Test Post",
+		Content:   "Content that should survive a partial update",
+		Slug:      "e2e-patch-test-post",
+		Tags:      []string{"go", "mongo"},
+		Version:   1,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	collection := database.Database.Collection("posts")
+	result, err := collection.InsertOne(context.Background(), testPost)
+	assert.NoError(t, err)
+
+	postID := result.InsertedID.(primitive.ObjectID)
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	// A merge patch should only change the fields it mentions
+	req, _ := http.NewRequest("PATCH", getAPIBaseURL()+postsEndpoint+"/"+postID.Hex(), bytes.NewBufferString(`{"published": true}`))
+	req.Header.Set(contentTypeHeader, "application/merge-patch+json")
+	req.Header.Set(apiKeyHeader, getValidAPIKey())
+
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	if resp != nil {
+		defer func() { _ = resp.Body.Close() }()
+	}
+
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var patchedPost models.Post
+		err = json.NewDecoder(resp.Body).Decode(&patchedPost)
+		assert.NoError(t, err)
+		assert.True(t, patchedPost.Published)
+		assert.Equal(t, testPost.Tags, patchedPost.Tags)
+		assert.Equal(t, testPost.Content, patchedPost.Content)
+		assert.Equal(t, int64(2), patchedPost.Version)
+	}
+
+	// A JSON patch whose test operation fails should be rejected
+	req, _ = http.NewRequest("PATCH", getAPIBaseURL()+postsEndpoint+"/"+postID.Hex(),
+		bytes.NewBufferString(`[{"op": "test", "path": "/title", "value": "Stale Title"}, {"op": "remove", "path": "/tags/0"}]`))
+	req.Header.Set(contentTypeHeader, "application/json-patch+json")
+	req.Header.Set(apiKeyHeader, getValidAPIKey())
+
+	resp, err = client.Do(req)
+	assert.NoError(t, err)
+	if resp != nil {
+		defer func() { _ = resp.Body.Close() }()
+	}
+
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	}
+
+	// A plain JSON body is not a patch document
+	req, _ = http.NewRequest("PATCH", getAPIBaseURL()+postsEndpoint+"/"+postID.Hex(), bytes.NewBufferString(`{"published": false}`))
+	req.Header.Set(contentTypeHeader, applicationJSON)
+	req.Header.Set(apiKeyHeader, getValidAPIKey())
+
+	resp, err = client.Do(req)
+	assert.NoError(t, err)
+	if resp != nil {
+		defer func() { _ = resp.Body.Close() }()
+	}
+
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+	}
+
+	var storedPost models.Post
+	err = collection.FindOne(context.Background(), bson.M{"_id": postID}).Decode(&storedPost)
+	assert.NoError(t, err)
+	assert.True(t, storedPost.Published)
+	assert.Equal(t, testPost.Tags, storedPost.Tags)
+}
+
+func TestE2EGetPostBySlugHistory(t *testing.T) {
+	cleanup := setupE2ETestDB()
+	defer cleanup()
+
+	testPost := models.Post{
+		Title:     "E2E Slug History Test Post",
+		Content:   "Content of a post that was renamed",
+		Slug:      "e2e-renamed-post",
+		Slugs:     []string{"e2e-original-post", "e2e-renamed-post"},
+		Version:   1,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	collection := database.Database.Collection("posts")
+	_, err := collection.InsertOne(context.Background(), testPost)
+	assert.NoError(t, err)
+
+	// Don't follow redirects so the 301 itself can be inspected
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Get(getAPIBaseURL() + postsEndpoint + "/e2e-original-post")
+	assert.NoError(t, err)
+	if resp != nil {
+		defer func() { _ = resp.Body.Close() }()
+	}
+
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusMovedPermanently, resp.StatusCode)
+		assert.Equal(t, postsEndpoint+"/e2e-renamed-post", resp.Header.Get("Location"))
+
+		var body map[string]interface{}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		assert.NoError(t, err)
+		assert.Equal(t, "e2e-renamed-post", body["slug"])
+	}
+
+	// A slug that was never used is still not found
+	resp, err = client.Get(getAPIBaseURL() + postsEndpoint + "/e2e-never-used")
+	assert.NoError(t, err)
+	if resp != nil {
+		defer func() { _ = resp.Body.Close() }()
+	}
+
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestE2ECreatePostGeneratesUniqueSlug(t *testing.T) {
+	cleanup := setupE2ETestDB()
+	defer cleanup()
+
+	postJSON, _ := json.Marshal(map[string]interface{}{
+		"title":   "Café & Go: 2024 — Part 1/2",
+		"content": "Posts without a slug get one generated from the title",
+	})
+
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	// The same title twice should yield the base slug, then a suffixed one
+	for _, expectedSlug := range []string{"cafe-go-2024-part-1-2", "cafe-go-2024-part-1-2-2"} {
+		req, _ := http.NewRequest("POST", getAPIBaseURL()+postsEndpoint, bytes.NewBuffer(postJSON))
+		req.Header.Set(contentTypeHeader, applicationJSON)
+		req.Header.Set(apiKeyHeader, getValidAPIKey())
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		if resp != nil {
+			defer func() { _ = resp.Body.Close() }()
+		}
+
+		if assert.NotNil(t, resp, responseNotNil) {
+			assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+			var createdPost models.Post
+			err = json.NewDecoder(resp.Body).Decode(&createdPost)
+			assert.NoError(t, err)
+			assert.Equal(t, expectedSlug, createdPost.Slug)
+		}
+	}
+}
+
+func TestE2EUploadMedia(t *testing.T) {
+	cleanup := setupE2ETestDB()
+	defer cleanup()
+
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	// Build a PNG wide enough to get a small variant
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	var imageData bytes.Buffer
+	assert.NoError(t, png.Encode(&imageData, img))
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	// The claimed type and name are ignored in favour of the sniffed content
+	part, _ := form.CreateFormFile("file", "diagram.txt")
+	_, _ = part.Write(imageData.Bytes())
+	_ = form.WriteField("alt", "E2E diagram")
+	_ = form.Close()
+
+	req, _ := http.NewRequest("POST", getAPIBaseURL()+mediaEndpoint, &body)
+	req.Header.Set(contentTypeHeader, form.FormDataContentType())
+	req.Header.Set(apiKeyHeader, getValidAPIKey())
+
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	if resp != nil {
+		defer func() { _ = resp.Body.Close() }()
+	}
+
+	var uploaded models.Media
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		err = json.NewDecoder(resp.Body).Decode(&uploaded)
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", uploaded.ContentType)
+		assert.Equal(t, 400, uploaded.Width)
+		assert.Equal(t, "E2E diagram", uploaded.Alt)
+		assert.Equal(t, mediaEndpoint+"/"+uploaded.ID.Hex(), uploaded.URL)
+		assert.Len(t, uploaded.Variants, 3, "Expected webp, small and small-webp variants")
+	}
+	if uploaded.ID.IsZero() {
+		return
+	}
+
+	// The original and its variants should be served by ID
+	for path, contentType := range map[string]string{
+		uploaded.URL:                 "image/png",
+		uploaded.URL + "/small-webp": "image/webp",
+	} {
+		resp, err = client.Get(getAPIBaseURL() + path)
+		assert.NoError(t, err)
+		if assert.NotNil(t, resp, responseNotNil) {
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, contentType, resp.Header.Get(contentTypeHeader))
+			_ = resp.Body.Close()
+		}
+	}
+
+	// Media referenced by a post should only be deleted with force
+	_, err = database.Database.Collection("posts").InsertOne(context.Background(), models.Post{
+		Title:     "E2E Media Post",
+		Content:   "![diagram](" + uploaded.URL + "/small)",
+		Slug:      "e2e-media-post",
+		Version:   1,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	assert.NoError(t, err)
+
+	req, _ = http.NewRequest("DELETE", getAPIBaseURL()+uploaded.URL, nil)
+	req.Header.Set(apiKeyHeader, getValidAPIKey())
+	resp, err = client.Do(req)
+	assert.NoError(t, err)
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		_ = resp.Body.Close()
+	}
+
+	req, _ = http.NewRequest("DELETE", getAPIBaseURL()+uploaded.URL+"?force=true", nil)
+	req.Header.Set(apiKeyHeader, getValidAPIKey())
+	resp, err = client.Do(req)
+	assert.NoError(t, err)
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+	}
+
+	resp, err = client.Get(getAPIBaseURL() + uploaded.URL)
+	assert.NoError(t, err)
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		_ = resp.Body.Close()
+	}
+}
+
+func TestE2EPostSeries(t *testing.T) {
+	cleanup := setupE2ETestDB()
+	defer cleanup()
+
+	collection := database.Database.Collection("posts")
+	var postIDs []string
+	for i, title := range []string{"E2E Series Part One", "E2E Series Part Two", "E2E Series Part Three"} {
+		result, err := collection.InsertOne(context.Background(), models.Post{
+			Title:     title,
+			Content:   "Series content",
+			Slug:      fmt.Sprintf("e2e-series-part-%d", i+1),
+			Published: i != 2, // The last part is still a draft
+			Version:   1,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		})
+		assert.NoError(t, err)
+		postIDs = append(postIDs, result.InsertedID.(primitive.ObjectID).Hex())
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	body, _ := json.Marshal(map[string]interface{}{
+		"title":    "E2E Series",
+		"post_ids": postIDs,
+	})
+	req, _ := http.NewRequest("POST", getAPIBaseURL()+seriesEndpoint, bytes.NewBuffer(body))
+	req.Header.Set(contentTypeHeader, applicationJSON)
+	req.Header.Set(apiKeyHeader, getValidAPIKey())
+
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	if resp != nil {
+		defer func() { _ = resp.Body.Close() }()
+	}
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+
+	// The public series only lists published parts
+	resp, err = client.Get(getAPIBaseURL() + seriesEndpoint + "/e2e-series")
+	assert.NoError(t, err)
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var series struct {
+			Parts []models.SeriesPart `json:"parts"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&series))
+		assert.Len(t, series.Parts, 2)
+		_ = resp.Body.Close()
+	}
+
+	// A part links to its neighbours
+	resp, err = client.Get(getAPIBaseURL() + postsEndpoint + "/e2e-series-part-1")
+	assert.NoError(t, err)
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var post models.Post
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&post))
+		if assert.NotNil(t, post.Series) {
+			assert.Equal(t, 1, post.Series.Part)
+			assert.Equal(t, 2, post.Series.Total)
+			assert.Nil(t, post.Series.Previous)
+			if assert.NotNil(t, post.Series.Next) {
+				assert.Equal(t, "e2e-series-part-2", post.Series.Next.Slug)
+			}
+		}
+		_ = resp.Body.Close()
+	}
+
+	// A post can't join a second series
+	body, _ = json.Marshal(map[string]interface{}{"title": "E2E Other Series", "post_ids": postIDs[:1]})
+	req, _ = http.NewRequest("POST", getAPIBaseURL()+seriesEndpoint, bytes.NewBuffer(body))
+	req.Header.Set(contentTypeHeader, applicationJSON)
+	req.Header.Set(apiKeyHeader, getValidAPIKey())
+	resp, err = client.Do(req)
+	assert.NoError(t, err)
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		_ = resp.Body.Close()
+	}
+}
+
+func TestE2ERelatedPosts(t *testing.T) {
+	cleanup := setupE2ETestDB()
+	defer cleanup()
+
+	collection := database.Database.Collection("posts")
+	for _, post := range []models.Post{
+		{Title: "Goroutines and channels", Slug: "e2e-goroutines", Content: "Concurrency in Go with goroutines and channels.", Tags: []string{"go"}},
+		{Title: "Channel patterns", Slug: "e2e-channel-patterns", Content: "Fan-in and fan-out with goroutines and channels.", Tags: []string{"go"}},
+		{Title: "Baking sourdough", Slug: "e2e-sourdough", Content: "Flour, water and salt.", Tags: []string{"baking"}},
+	} {
+		post.Published = true
+		post.Version = 1
+		post.CreatedAt = time.Now()
+		post.UpdatedAt = time.Now()
+		_, err := collection.InsertOne(context.Background(), post)
+		assert.NoError(t, err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	req, _ := http.NewRequest("POST", getAPIBaseURL()+"/api/v1/admin/related/rebuild", nil)
+	req.Header.Set(apiKeyHeader, getValidAPIKey())
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+	}
+
+	resp, err = client.Get(getAPIBaseURL() + postsEndpoint + "/e2e-goroutines/related?limit=5")
+	assert.NoError(t, err)
+	if assert.NotNil(t, resp, responseNotNil) {
+		defer func() { _ = resp.Body.Close() }()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var result struct {
+			Posts []models.Post `json:"posts"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		if assert.Len(t, result.Posts, 1) {
+			assert.Equal(t, "e2e-channel-patterns", result.Posts[0].Slug)
+		}
+	}
+}
+
+func TestE2EPostContentMetadata(t *testing.T) {
+	cleanup := setupE2ETestDB()
+	defer cleanup()
+
+	testPost := models.Post{
+		Title:     "E2E Metadata Post",
+		Content:   "## Getting Started\n\nSome words to read.\n\n## Getting Started\n\nMore words.",
+		Slug:      "e2e-metadata-post",
+		Published: true,
+	}
+	postJSON, _ := json.Marshal(testPost)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	req, _ := http.NewRequest("POST", getAPIBaseURL()+postsEndpoint, bytes.NewBuffer(postJSON))
+	req.Header.Set(contentTypeHeader, applicationJSON)
+	req.Header.Set(apiKeyHeader, getValidAPIKey())
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		var post models.Post
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&post))
+		assert.Equal(t, 10, post.WordCount)
+		assert.Equal(t, 1, post.ReadingTimeMinutes)
+		if assert.Len(t, post.TOC, 2) {
+			assert.Equal(t, "getting-started", post.TOC[0].ID)
+			assert.Equal(t, "getting-started-1", post.TOC[1].ID)
+		}
+		_ = resp.Body.Close()
+	}
+
+	// Post lists carry the metadata without the content
+	resp, err = client.Get(getAPIBaseURL() + postsEndpoint)
+	assert.NoError(t, err)
+	if assert.NotNil(t, resp, responseNotNil) {
+		defer func() { _ = resp.Body.Close() }()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var response struct {
+			Posts []map[string]interface{} `json:"posts"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		if assert.Len(t, response.Posts, 1) {
+			assert.NotContains(t, response.Posts[0], "content")
+			assert.Equal(t, float64(10), response.Posts[0]["word_count"])
+			assert.Contains(t, response.Posts[0], "toc")
+		}
+	}
+}
+
+func TestE2EWebhooks(t *testing.T) {
+	cleanup := setupE2ETestDB()
+	defer cleanup()
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	send := func(method, path string, body interface{}) *http.Response {
+		var reader io.Reader
+		if body != nil {
+			payload, _ := json.Marshal(body)
+			reader = bytes.NewBuffer(payload)
+		}
+		req, _ := http.NewRequest(method, getAPIBaseURL()+path, reader)
+		req.Header.Set(contentTypeHeader, applicationJSON)
+		req.Header.Set(apiKeyHeader, getValidAPIKey())
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	// Register a webhook; nothing listens on the URL, so deliveries stay in the log
+	resp := send("POST", "/api/v1/admin/webhooks", map[string]interface{}{
+		"url":    "http://127.0.0.1:9/e2e-hook",
+		"events": []string{"post.created", "post.published"},
+	})
+	var webhook struct {
+		ID     string `json:"id"`
+		Secret string `json:"secret"`
+	}
+	if !assert.NotNil(t, resp, responseNotNil) {
+		return
+	}
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&webhook))
+	_ = resp.Body.Close()
+	assert.True(t, strings.HasPrefix(webhook.Secret, "whsec_"))
+
+	// Unknown events are rejected
+	resp = send("POST", "/api/v1/admin/webhooks", map[string]interface{}{
+		"url":    "http://127.0.0.1:9/e2e-hook",
+		"events": []string{"post.viewed"},
+	})
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		_ = resp.Body.Close()
+	}
+
+	// Creating a published post queues post.created and post.published
+	resp = send("POST", postsEndpoint, models.Post{Title: "E2E Webhook Post", Content: "Hello", Slug: "e2e-webhook-post", Published: true})
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		_ = resp.Body.Close()
+	}
+
+	resp = send("GET", "/api/v1/admin/webhooks/"+webhook.ID+"/deliveries", nil)
+	var deliveryLog struct {
+		Deliveries []models.WebhookDelivery `json:"deliveries"`
+		Total      int                      `json:"total"`
+	}
+	if !assert.NotNil(t, resp, responseNotNil) {
+		return
+	}
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&deliveryLog))
+	_ = resp.Body.Close()
+	if !assert.Equal(t, 2, deliveryLog.Total) || !assert.Len(t, deliveryLog.Deliveries, 2) {
+		return
+	}
+	events := []string{deliveryLog.Deliveries[0].Event, deliveryLog.Deliveries[1].Event}
+	assert.ElementsMatch(t, []string{"post.created", "post.published"}, events)
+
+	// A delivery can be sent again with the same payload
+	resp = send("POST", "/api/v1/admin/webhooks/"+webhook.ID+"/deliveries/"+deliveryLog.Deliveries[0].ID.Hex()+"/redeliver", nil)
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		var redelivery models.WebhookDelivery
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&redelivery))
+		assert.Equal(t, deliveryLog.Deliveries[0].Payload, redelivery.Payload)
+		if assert.NotNil(t, redelivery.RedeliveryOf) {
+			assert.Equal(t, deliveryLog.Deliveries[0].ID, *redelivery.RedeliveryOf)
+		}
+		_ = resp.Body.Close()
+	}
+
+	resp = send("DELETE", "/api/v1/admin/webhooks/"+webhook.ID, nil)
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+	}
+}
+
+func TestE2ENewsletter(t *testing.T) {
+	cleanup := setupE2ETestDB()
+	defer cleanup()
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	send := func(method, path string, body interface{}, admin bool) *http.Response {
+		var reader io.Reader
+		if body != nil {
+			payload, _ := json.Marshal(body)
+			reader = bytes.NewBuffer(payload)
+		}
+		req, _ := http.NewRequest(method, getAPIBaseURL()+path, reader)
+		req.Header.Set(contentTypeHeader, applicationJSON)
+		if admin {
+			req.Header.Set(apiKeyHeader, getValidAPIKey())
+		}
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		return resp
+	}
+	expectStatus := func(resp *http.Response, status int) {
+		if assert.NotNil(t, resp, responseNotNil) {
+			assert.Equal(t, status, resp.StatusCode)
+			_ = resp.Body.Close()
+		}
+	}
+
+	expectStatus(send("POST", "/api/v1/newsletter/subscribe", map[string]interface{}{"email": "E2E.Reader@Example.com", "tags": []string{"go"}}, false), http.StatusAccepted)
+	expectStatus(send("POST", "/api/v1/newsletter/subscribe", map[string]interface{}{"email": "not-an-email"}, false), http.StatusBadRequest)
+
+	// The confirmation token is only sent by email: replace it with a known one
+	collection := database.Database.Collection("subscribers")
+	var subscriber models.Subscriber
+	if !assert.NoError(t, collection.FindOne(context.Background(), bson.M{"email": "e2e.reader@example.com"}).Decode(&subscriber)) {
+		return
+	}
+	assert.Equal(t, "pending", subscriber.Status)
+	assert.Equal(t, []string{"go"}, subscriber.Tags)
+	_, err := collection.UpdateOne(context.Background(), bson.M{"_id": subscriber.ID},
+		bson.M{"$set": bson.M{"confirm_token_hash": newsletter.HashToken("e2e-confirm-token")}})
+	assert.NoError(t, err)
+
+	expectStatus(send("GET", "/api/v1/newsletter/confirm?token=wrong", nil, false), http.StatusBadRequest)
+	expectStatus(send("GET", "/api/v1/newsletter/confirm?token=e2e-confirm-token", nil, false), http.StatusOK)
+	expectStatus(send("GET", "/api/v1/newsletter/confirm?token=e2e-confirm-token", nil, false), http.StatusBadRequest)
+
+	// Tag preferences are managed with the token from the emails' links
+	preferencesPath := "/api/v1/newsletter/preferences?token=" + subscriber.UnsubscribeToken
+	resp := send("PUT", preferencesPath, map[string]interface{}{"tags": []string{"go", "web", "go"}}, false)
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var preferences struct {
+			Status string   `json:"status"`
+			Tags   []string `json:"tags"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&preferences))
+		assert.Equal(t, "confirmed", preferences.Status)
+		assert.Equal(t, []string{"go", "web"}, preferences.Tags)
+		_ = resp.Body.Close()
+	}
+
+	// Publishing a post queues it for subscribers
+	expectStatus(send("POST", postsEndpoint, models.Post{Title: "E2E Newsletter Post", Content: "Hello", Slug: "e2e-newsletter-post", Tags: []string{"go"}, Published: true}, true), http.StatusCreated)
+	count, err := database.Database.Collection("newsletter_issues").CountDocuments(context.Background(), bson.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	resp = send("GET", "/api/v1/admin/subscribers?status=confirmed", nil, true)
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var list struct {
+			Total int `json:"total"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+		assert.Equal(t, 1, list.Total)
+		_ = resp.Body.Close()
+	}
+
+	// One-click unsubscribe
+	req, _ := http.NewRequest("POST", getAPIBaseURL()+"/api/v1/newsletter/unsubscribe?token="+subscriber.UnsubscribeToken,
+		strings.NewReader("List-Unsubscribe=One-Click"))
+	req.Header.Set(contentTypeHeader, "application/x-www-form-urlencoded")
+	resp, err = client.Do(req)
+	assert.NoError(t, err)
+	expectStatus(resp, http.StatusOK)
+	assert.NoError(t, collection.FindOne(context.Background(), bson.M{"_id": subscriber.ID}).Decode(&subscriber))
+	assert.Equal(t, "unsubscribed", subscriber.Status)
+
+	// Bounces are reported by admins
+	resp = send("POST", "/api/v1/admin/subscribers/bounces", map[string]interface{}{"email": "e2e.reader@example.com", "type": "complaint"}, true)
+	expectStatus(resp, http.StatusOK)
+	assert.NoError(t, collection.FindOne(context.Background(), bson.M{"_id": subscriber.ID}).Decode(&subscriber))
+	assert.Equal(t, "complained", subscriber.Status)
+}
+
+func TestE2EPostTranslations(t *testing.T) {
+	cleanup := setupE2ETestDB()
+	defer cleanup()
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	send := func(method, path string, body interface{}, headers map[string]string) *http.Response {
+		var reader io.Reader
+		if body != nil {
+			payload, _ := json.Marshal(body)
+			reader = bytes.NewBuffer(payload)
+		}
+		req, _ := http.NewRequest(method, getAPIBaseURL()+path, reader)
+		req.Header.Set(contentTypeHeader, applicationJSON)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		return resp
+	}
+	decodePost := func(resp *http.Response, status int) models.Post {
+		var post models.Post
+		if assert.NotNil(t, resp, responseNotNil) {
+			defer func() { _ = resp.Body.Close() }()
+			assert.Equal(t, status, resp.StatusCode)
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&post))
+		}
+		return post
+	}
+	admin := map[string]string{apiKeyHeader: getValidAPIKey()}
+
+	english := decodePost(send("POST", postsEndpoint, map[string]interface{}{
+		"title": "Hello, world", "content": "English content", "slug": "e2e-hello", "published": true,
+	}, admin), http.StatusCreated)
+	assert.Equal(t, "en", english.Lang, "Posts default to the default language")
+	assert.False(t, english.TranslationGroupID.IsZero())
+
+	// The translation shares the slug, which is unique per language
+	portuguese := decodePost(send("POST", postsEndpoint, map[string]interface{}{
+		"title": "Olá, mundo", "content": "Conteúdo em português", "slug": "e2e-hello", "published": true,
+		"lang": "pt", "translation_group_id": english.TranslationGroupID.Hex(),
+	}, admin), http.StatusCreated)
+	assert.Equal(t, english.TranslationGroupID, portuguese.TranslationGroupID)
+
+	// Unsupported languages and unknown groups are rejected
+	resp := send("POST", postsEndpoint, map[string]interface{}{"title": "Bonjour", "content": "Contenu", "lang": "xx"}, admin)
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		_ = resp.Body.Close()
+	}
+	resp = send("POST", postsEndpoint, map[string]interface{}{
+		"title": "Orphan", "content": "Content", "lang": "pt", "translation_group_id": primitive.NewObjectID().Hex(),
+	}, admin)
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		_ = resp.Body.Close()
+	}
+
+	// Slugs are negotiated with Accept-Language
+	resp = send("GET", postsEndpoint+"/e2e-hello", nil, map[string]string{"Accept-Language": "pt-BR, en;q=0.5"})
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, "pt", resp.Header.Get("Content-Language"))
+		assert.Contains(t, resp.Header.Get("Vary"), "Accept-Language")
+	}
+	negotiated := decodePost(resp, http.StatusOK)
+	assert.Equal(t, portuguese.ID, negotiated.ID)
+	if assert.Len(t, negotiated.Translations, 1) {
+		assert.Equal(t, "en", negotiated.Translations[0].Lang)
+		assert.Equal(t, english.ID, negotiated.Translations[0].ID)
+	}
+
+	// The lang parameter takes precedence over Accept-Language
+	negotiated = decodePost(send("GET", postsEndpoint+"/e2e-hello?lang=en", nil, map[string]string{"Accept-Language": "pt"}), http.StatusOK)
+	assert.Equal(t, english.ID, negotiated.ID)
+
+	// An ID names a specific post unless a language is asked for explicitly
+	negotiated = decodePost(send("GET", postsEndpoint+"/"+english.ID.Hex(), nil, map[string]string{"Accept-Language": "pt"}), http.StatusOK)
+	assert.Equal(t, english.ID, negotiated.ID)
+	negotiated = decodePost(send("GET", postsEndpoint+"/"+english.ID.Hex()+"?lang=pt", nil, nil), http.StatusOK)
+	assert.Equal(t, portuguese.ID, negotiated.ID)
+
+	// Lists filter by language and list each post's translations
+	resp = send("GET", postsEndpoint+"?lang=pt", nil, nil)
+	if assert.NotNil(t, resp, responseNotNil) {
+		defer func() { _ = resp.Body.Close() }()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var page struct {
+			Posts []models.Post `json:"posts"`
+			Total int64         `json:"total"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		assert.Equal(t, int64(1), page.Total)
+		if assert.Len(t, page.Posts, 1) {
+			assert.Equal(t, portuguese.ID, page.Posts[0].ID)
+			assert.Len(t, page.Posts[0].Translations, 1)
+		}
+	}
+}
+
+func TestE2EGetPostsSparseFieldsets(t *testing.T) {
+	cleanup := setupE2ETestDB()
+	defer cleanup()
+
+	author := models.Author{Name: "E2E Author", Slug: "e2e-author", APIKeyIDs: []string{"secret-key-id"}, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	result, err := database.Database.Collection("authors").InsertOne(context.Background(), author)
+	assert.NoError(t, err)
+	author.ID = result.InsertedID.(primitive.ObjectID)
+
+	_, err = database.Database.Collection("posts").InsertOne(context.Background(), models.Post{
+		Title:     "E2E Sparse Post",
+		Content:   "Content that sparse lists leave out",
+		Slug:      "e2e-sparse-post",
+		Summary:   "Summary",
+		AuthorIDs: []primitive.ObjectID{author.ID},
+		Published: true,
+		Views:     7,
+		Version:   1,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	assert.NoError(t, err)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	getPosts := func(query string) (int, []map[string]interface{}) {
+		resp, err := client.Get(getAPIBaseURL() + postsEndpoint + "?" + query)
+		assert.NoError(t, err)
+		if !assert.NotNil(t, resp, responseNotNil) {
+			return 0, nil
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		var page struct {
+			Posts []map[string]interface{} `json:"posts"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&page)
+		return resp.StatusCode, page.Posts
+	}
+
+	status, posts := getPosts("")
+	assert.Equal(t, http.StatusOK, status)
+	if assert.Len(t, posts, 1) {
+		assert.NotContains(t, posts[0], "content", "Lists exclude content by default")
+		assert.Contains(t, posts[0], "views")
+	}
+
+	status, posts = getPosts("fields=title,content&expand=authors")
+	assert.Equal(t, http.StatusOK, status)
+	if assert.Len(t, posts, 1) {
+		assert.Equal(t, "E2E Sparse Post", posts[0]["title"])
+		assert.Equal(t, "Content that sparse lists leave out", posts[0]["content"])
+		assert.Contains(t, posts[0], "id")
+		assert.NotContains(t, posts[0], "views")
+		assert.NotContains(t, posts[0], "summary")
+
+		authors, _ := posts[0]["authors"].([]interface{})
+		if assert.Len(t, authors, 1) {
+			embedded := authors[0].(map[string]interface{})
+			assert.Equal(t, "E2E Author", embedded["name"])
+			assert.NotContains(t, embedded, "api_key_ids", "Credentials stay private")
+		}
+	}
+
+	status, _ = getPosts("fields=title,password")
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = getPosts("expand=comments")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestE2EGetPostsSortingAndDateFilters(t *testing.T) {
+	cleanup := setupE2ETestDB()
+	defer cleanup()
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	collection := database.Database.Collection("posts")
+	for i, title := range []string{"Charlie", "Alpha", "Bravo"} {
+		_, err := collection.InsertOne(context.Background(), models.Post{
+			Title:     title,
+			Content:   "Content",
+			Slug:      "e2e-sort-" + strings.ToLower(title),
+			Published: true,
+			Views:     int64(10 * (i % 2)), // Charlie and Bravo tie on views
+			Version:   1,
+			CreatedAt: base.AddDate(0, i, 0),
+			UpdatedAt: base.AddDate(0, i, 0),
+		})
+		assert.NoError(t, err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	titles := func(query string) (int, []string) {
+		resp, err := client.Get(getAPIBaseURL() + postsEndpoint + "?" + query)
+		assert.NoError(t, err)
+		if !assert.NotNil(t, resp, responseNotNil) {
+			return 0, nil
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		var page struct {
+			Posts []models.Post `json:"posts"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&page)
+		var result []string
+		for _, post := range page.Posts {
+			result = append(result, post.Title)
+		}
+		return resp.StatusCode, result
+	}
+
+	status, result := titles("")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []string{"Bravo", "Alpha", "Charlie"}, result, "Newest first by default")
+
+	_, result = titles("sort=title")
+	assert.Equal(t, []string{"Alpha", "Bravo", "Charlie"}, result)
+
+	// Ties on views are broken by ID, in insertion order when ascending
+	_, result = titles("sort=views")
+	assert.Equal(t, []string{"Charlie", "Bravo", "Alpha"}, result)
+	_, result = titles("sort=-views")
+	assert.Equal(t, []string{"Alpha", "Bravo", "Charlie"}, result)
+
+	_, result = titles("created_after=2024-01-01T00:00:00Z&created_before=2024-03-01T00:00:00Z")
+	assert.Equal(t, []string{"Alpha"}, result, "Bounds are exclusive")
+	_, result = titles("updated_since=2024-02-01T00:00:00Z&sort=created_at")
+	assert.Equal(t, []string{"Alpha", "Bravo"}, result)
+
+	status, _ = titles("sort=content")
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = titles("created_after=yesterday")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestE2EArchive(t *testing.T) {
+	cleanup := setupE2ETestDB()
+	defer cleanup()
+
+	collection := database.Database.Collection("posts")
+	for i, post := range []models.Post{
+		{Title: "Late March", Published: true, CreatedAt: time.Date(2024, 3, 31, 23, 30, 0, 0, time.UTC)},
+		{Title: "Early March", Published: true, CreatedAt: time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)},
+		{Title: "New Year", Published: true, CreatedAt: time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)},
+		{Title: "Draft", Published: false, CreatedAt: time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)},
+	} {
+		post.Content = "Content"
+		post.Slug = fmt.Sprintf("e2e-archive-%d", i)
+		post.Version = 1
+		post.UpdatedAt = post.CreatedAt
+		_, err := collection.InsertOne(context.Background(), post)
+		assert.NoError(t, err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	get := func(path string, result interface{}) int {
+		resp, err := client.Get(getAPIBaseURL() + archiveEndpoint + path)
+		assert.NoError(t, err)
+		if !assert.NotNil(t, resp, responseNotNil) {
+			return 0
+		}
+		defer func() { _ = resp.Body.Close() }()
+		_ = json.NewDecoder(resp.Body).Decode(result)
+		return resp.StatusCode
+	}
+
+	type archive struct {
+		Years []struct {
+			Year   int `json:"year"`
+			Count  int `json:"count"`
+			Months []struct {
+				Month int `json:"month"`
+				Count int `json:"count"`
+			} `json:"months"`
+		} `json:"years"`
+		Total int `json:"total"`
+	}
+
+	t.Run("Counts published posts by month", func(t *testing.T) {
+		var result archive
+		assert.Equal(t, http.StatusOK, get("", &result))
+		assert.Equal(t, 3, result.Total, "Drafts are not counted")
+		if assert.Len(t, result.Years, 2) {
+			assert.Equal(t, 2025, result.Years[0].Year, "Most recent year first")
+			assert.Equal(t, 2, result.Years[1].Count)
+		}
+	})
+
+	t.Run("Month boundaries follow the timezone", func(t *testing.T) {
+		var result archive
+		assert.Equal(t, http.StatusOK, get("?tz=Europe/Lisbon", &result))
+		if assert.Len(t, result.Years, 2) && assert.Len(t, result.Years[1].Months, 2) {
+			// 23:30 UTC on March 31st is already April in Lisbon
+			assert.Equal(t, 4, result.Years[1].Months[0].Month)
+			assert.Equal(t, 3, result.Years[1].Months[1].Month)
+		}
+	})
+
+	t.Run("Lists the posts of a month in chronological order", func(t *testing.T) {
+		var result struct {
+			Posts []models.Post `json:"posts"`
+			Total int           `json:"total"`
+		}
+		assert.Equal(t, http.StatusOK, get("/2024/03", &result))
+		if assert.Len(t, result.Posts, 2) {
+			assert.Equal(t, "Early March", result.Posts[0].Title)
+			assert.Equal(t, "Late March", result.Posts[1].Title)
+			assert.Empty(t, result.Posts[0].Content)
+		}
+	})
+
+	t.Run("Rejects invalid months and timezones", func(t *testing.T) {
+		var result map[string]interface{}
+		assert.Equal(t, http.StatusBadRequest, get("/2024/13", &result))
+		assert.Equal(t, http.StatusBadRequest, get("?tz=Mars/Olympus", &result))
+	})
+}
+
+func TestE2EGraphQL(t *testing.T) {
+	cleanup := setupE2ETestDB()
+	defer cleanup()
+
+	author := models.Author{ID: primitive.NewObjectID(), Name: "Ada", Slug: "e2e-graphql-ada"}
+	_, err := database.Database.Collection("authors").InsertOne(context.Background(), author)
+	assert.NoError(t, err)
+
+	collection := database.Database.Collection("posts")
+	for i := 0; i < 3; i++ {
+		now := time.Now().Add(time.Duration(i) * time.Minute)
+		_, err := collection.InsertOne(context.Background(), models.Post{
+			Title:     fmt.Sprintf("GraphQL Post %d", i),
+			Content:   "Content",
+			Slug:      fmt.Sprintf("e2e-graphql-%d", i),
+			Tags:      []string{"graphql"},
+			AuthorIDs: []primitive.ObjectID{author.ID},
+			Published: true,
+			Version:   1,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+		assert.NoError(t, err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	type graphQLResponse struct {
+		Data   map[string]json.RawMessage `json:"data"`
+		Errors []struct {
+			Message    string                 `json:"message"`
+			Extensions map[string]interface{} `json:"extensions"`
+		} `json:"errors"`
+	}
+	query := func(apiKey, query string) (int, graphQLResponse) {
+		body, _ := json.Marshal(map[string]string{"query": query})
+		req, _ := http.NewRequest(http.MethodPost, getAPIBaseURL()+graphQLEndpoint, bytes.NewReader(body))
+		req.Header.Set(contentTypeHeader, applicationJSON)
+		if apiKey != "" {
+			req.Header.Set(apiKeyHeader, apiKey)
+		}
+		var result graphQLResponse
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		if !assert.NotNil(t, resp, responseNotNil) {
+			return 0, result
+		}
+		defer func() { _ = resp.Body.Close() }()
+		_ = json.NewDecoder(resp.Body).Decode(&result)
+		return resp.StatusCode, result
+	}
+
+	t.Run("Queries posts with their authors", func(t *testing.T) {
+		status, result := query("", `{ posts(tag: "graphql", limit: 2) { total items { title authors { name } } } }`)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Empty(t, result.Errors)
+
+		var page struct {
+			Total int `json:"total"`
+			Items []struct {
+				Title   string `json:"title"`
+				Authors []struct {
+					Name string `json:"name"`
+				} `json:"authors"`
+			} `json:"items"`
+		}
+		assert.NoError(t, json.Unmarshal(result.Data["posts"], &page))
+		assert.Equal(t, 3, page.Total)
+		if assert.Len(t, page.Items, 2) {
+			assert.Equal(t, "GraphQL Post 2", page.Items[0].Title, "Newest first")
+			if assert.Len(t, page.Items[0].Authors, 1) {
+				assert.Equal(t, "Ada", page.Items[0].Authors[0].Name)
+			}
+		}
+	})
+
+	t.Run("Fetches a post by slug", func(t *testing.T) {
+		_, result := query("", `{ post(slug: "e2e-graphql-1") { title content } missing: post(slug: "none") { title } }`)
+		assert.Empty(t, result.Errors)
+		assert.JSONEq(t, `{"title": "GraphQL Post 1", "content": "Content"}`, string(result.Data["post"]))
+		assert.Equal(t, "null", string(result.Data["missing"]))
+	})
+
+	t.Run("Mutations require an API key", func(t *testing.T) {
+		_, result := query("", `mutation { createPost(input: {title: "Denied", content: "Content"}) { id } }`)
+		if assert.Len(t, result.Errors, 1) {
+			assert.Equal(t, "UNAUTHORIZED", result.Errors[0].Extensions["code"])
+		}
+	})
+
+	t.Run("Creates and likes a post", func(t *testing.T) {
+		_, result := query(getValidAPIKey(), `mutation { createPost(input: {title: "Created over GraphQL", content: "# Hello", published: true}) { id slug version } }`)
+		assert.Empty(t, result.Errors)
+
+		var created struct {
+			ID      string `json:"id"`
+			Slug    string `json:"slug"`
+			Version int    `json:"version"`
+		}
+		assert.NoError(t, json.Unmarshal(result.Data["createPost"], &created))
+		assert.Equal(t, "created-over-graphql", created.Slug)
+		assert.Equal(t, 1, created.Version)
+
+		_, result = query("", fmt.Sprintf(`mutation { likePost(id: %q) }`, created.ID))
+		assert.Empty(t, result.Errors)
+		assert.Equal(t, "1", string(result.Data["likePost"]))
+	})
+
+	t.Run("Rejects queries that are too complex", func(t *testing.T) {
+		status, result := query("", `{ posts(limit: 100) { items { related(limit: 20) { authors { socialLinks { url } } } } } }`)
+		assert.Equal(t, http.StatusBadRequest, status)
+		if assert.Len(t, result.Errors, 1) {
+			assert.Equal(t, "QUERY_TOO_COMPLEX", result.Errors[0].Extensions["code"])
+		}
+	})
+}
+
+// TestE2EOpenAPI tests the served OpenAPI document and documentation page against live API
+func TestE2EOpenAPI(t *testing.T) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	resp, err := client.Get(getAPIBaseURL() + "/openapi.json")
+	assert.NoError(t, err)
+	if assert.NotNil(t, resp, responseNotNil) {
+		defer func() { _ = resp.Body.Close() }()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get(contentTypeHeader), applicationJSON)
+
+		var doc struct {
+			OpenAPI string                     `json:"openapi"`
+			Paths   map[string]json.RawMessage `json:"paths"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+		assert.Equal(t, "3.0.3", doc.OpenAPI)
+		assert.Contains(t, doc.Paths, "/api/v1/posts/{id}")
+	}
+
+	docsResp, err := client.Get(getAPIBaseURL() + "/docs")
+	assert.NoError(t, err)
+	if assert.NotNil(t, docsResp, responseNotNil) {
+		defer func() { _ = docsResp.Body.Close() }()
+		assert.Equal(t, http.StatusOK, docsResp.StatusCode)
+		assert.Contains(t, docsResp.Header.Get(contentTypeHeader), "text/html")
+	}
+}
+
+func TestE2EProblemDetails(t *testing.T) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	send := func(accept string) *http.Response {
+		req, _ := http.NewRequest("POST", getAPIBaseURL()+postsEndpoint, bytes.NewBufferString(`{"tags":["not valid"]}`))
+		req.Header.Set(contentTypeHeader, applicationJSON)
+		req.Header.Set(apiKeyHeader, getValidAPIKey())
+		req.Header.Set("X-Request-ID", "e2e-problem")
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	// Validation failures are broken down per field in both shapes
+	resp := send(applicationJSON)
+	if assert.NotNil(t, resp, responseNotNil) {
+		defer func() { _ = resp.Body.Close() }()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get(contentTypeHeader), applicationJSON)
+
+		var response apierrors.ErrorResponse
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		assert.Equal(t, apierrors.CodeValidationFailed, response.Error.Code)
+		assert.Contains(t, response.Error.Errors, apierrors.FieldError{Field: "title", Rule: "required", Message: "is required"})
+	}
+
+	problemResp := send("application/problem+json")
+	if assert.NotNil(t, problemResp, responseNotNil) {
+		defer func() { _ = problemResp.Body.Close() }()
+		assert.Equal(t, http.StatusBadRequest, problemResp.StatusCode)
+		assert.Equal(t, apierrors.ProblemContentType, problemResp.Header.Get(contentTypeHeader))
+
+		var problem apierrors.Problem
+		assert.NoError(t, json.NewDecoder(problemResp.Body).Decode(&problem))
+		assert.Equal(t, "/problems/validation-failed", problem.Type)
+		assert.Equal(t, http.StatusBadRequest, problem.Status)
+		assert.Equal(t, "/api/v1/posts#request-e2e-problem", problem.Instance)
+		assert.Contains(t, problem.Errors, apierrors.FieldError{Field: "content", Rule: "required", Message: "is required"})
+		assert.Contains(t, problem.Errors, apierrors.FieldError{Field: "tags[0]", Rule: "alphanum", Message: "must only contain letters and digits"})
+	}
+}
+
+func TestE2EUpdateAuthorsWithoutAPIKeys(t *testing.T) {
+	cleanup := setupE2ETestDB()
+	defer cleanup()
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	update := func(id primitive.ObjectID, body string) *http.Response {
+		req, _ := http.NewRequest("PUT", getAPIBaseURL()+authorsEndpoint+"/"+id.Hex(), bytes.NewBufferString(body))
+		req.Header.Set(contentTypeHeader, applicationJSON)
+		req.Header.Set(apiKeyHeader, getValidAPIKey())
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	// Authors without API keys must not collide on the unique API key index,
+	// whether the keys are left out or sent empty
+	bodies := []string{`{"name":"E2E Keyless One","slug":"e2e-keyless-one"}`, `{"name":"E2E Keyless Two","slug":"e2e-keyless-two","api_key_ids":[]}`}
+	for i, body := range bodies {
+		author := models.Author{Name: "E2E Keyless", Slug: fmt.Sprintf("e2e-keyless-%d", i), CreatedAt: time.Now(), UpdatedAt: time.Now()}
+		result, err := database.Database.Collection("authors").InsertOne(context.Background(), author)
+		assert.NoError(t, err)
+
+		resp := update(result.InsertedID.(primitive.ObjectID), body)
+		if assert.NotNil(t, resp, responseNotNil) {
+			assert.Equal(t, http.StatusOK, resp.StatusCode, "update of author %d", i+1)
+			_ = resp.Body.Close()
+		}
+	}
+}
+
+func TestE2EConditionalGetAfterLike(t *testing.T) {
+	cleanup := setupE2ETestDB()
+	defer cleanup()
+
+	post := models.Post{
+		Title:     "E2E Cached Like Post",
+		Content:   "Counters changing without an update",
+		Slug:      "e2e-cached-like-post",
+		Lang:      "en",
+		Published: true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	result, err := database.Database.Collection("posts").InsertOne(context.Background(), post)
+	assert.NoError(t, err)
+	postID := result.InsertedID.(primitive.ObjectID)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	get := func(path, etag string) (int, string) {
+		req, _ := http.NewRequest("GET", getAPIBaseURL()+path, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		if !assert.NotNil(t, resp, responseNotNil) {
+			return 0, ""
+		}
+		defer func() { _ = resp.Body.Close() }()
+		return resp.StatusCode, resp.Header.Get("ETag")
+	}
+
+	postPath := postsEndpoint + "/" + postID.Hex()
+	listPath := postsEndpoint + "?sort=-likes"
+	_, postETag := get(postPath, "")
+	_, listETag := get(listPath, "")
+	status, _ := get(postPath, postETag)
+	assert.Equal(t, http.StatusNotModified, status)
+
+	req, _ := http.NewRequest("PUT", getAPIBaseURL()+postPath+"/like", nil)
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	if assert.NotNil(t, resp, responseNotNil) {
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		_ = resp.Body.Close()
+	}
+
+	// Likes leave updated_at and the version alone, but must still invalidate cached copies
+	status, _ = get(postPath, postETag)
+	assert.Equal(t, http.StatusOK, status, "A liked post must not be reported as not modified")
+	status, _ = get(listPath, listETag)
+	assert.Equal(t, http.StatusOK, status, "A list with a liked post must not be reported as not modified")
+}
+
 // Example of how to run these tests:
 //
 // Terminal 1: Start the API
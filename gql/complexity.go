@@ -0,0 +1,121 @@
+package gql
+
+import (
+	"fmt"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// ListSize returns the number of items a field resolves to at most, given its
+// resolved arguments, or 1 for fields that aren't lists
+type ListSize func(field string, args map[string]interface{}) int
+
+// Operation is the operation of a query document selected for execution
+type Operation struct {
+	Type       ast.Operation // "query", "mutation" or "subscription"
+	Complexity int
+}
+
+// Analyze parses a query document and computes the complexity of the operation
+// named operationName, or of its only operation when the name is empty. Every
+// field costs 1, and the fields selected within a list cost once per item,
+// so that a page of posts with their related posts costs what it fetches.
+func Analyze(query, operationName string, variables map[string]interface{}, listSize ListSize) (Operation, error) {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil {
+		return Operation{}, err
+	}
+
+	var op *ast.OperationDefinition
+	switch {
+	case operationName != "":
+		op = doc.Operations.ForName(operationName)
+		if op == nil {
+			return Operation{}, fmt.Errorf("no operation named '%s'", operationName)
+		}
+	case len(doc.Operations) == 1:
+		op = doc.Operations[0]
+	default:
+		return Operation{}, fmt.Errorf("operationName is required for documents with %d operations", len(doc.Operations))
+	}
+
+	// Variables left out take their default value
+	values := make(map[string]interface{}, len(variables))
+	for name, value := range variables {
+		values[name] = value
+	}
+	for _, definition := range op.VariableDefinitions {
+		if _, ok := values[definition.Variable]; !ok && definition.DefaultValue != nil {
+			if value, err := definition.DefaultValue.Value(nil); err == nil {
+				values[definition.Variable] = value
+			}
+		}
+	}
+
+	a := analyzer{doc: doc, variables: values, listSize: listSize, visiting: map[string]bool{}}
+	return Operation{Type: op.Operation, Complexity: a.selectionSet(op.SelectionSet)}, nil
+}
+
+// analyzer computes the complexity of the selection sets of a document
+type analyzer struct {
+	doc       *ast.QueryDocument
+	variables map[string]interface{}
+	listSize  ListSize
+	visiting  map[string]bool // Fragments being expanded, to stop on cycles
+}
+
+func (a *analyzer) selectionSet(set ast.SelectionSet) int {
+	total := 0
+	for _, selection := range set {
+		switch s := selection.(type) {
+		case *ast.Field:
+			cost := 1
+			if len(s.SelectionSet) > 0 {
+				cost += a.listSize(s.Name, a.arguments(s.Arguments)) * a.selectionSet(s.SelectionSet)
+			}
+			total += cost
+		case *ast.InlineFragment:
+			total += a.selectionSet(s.SelectionSet)
+		case *ast.FragmentSpread:
+			fragment := a.doc.Fragments.ForName(s.Name)
+			if fragment == nil || a.visiting[s.Name] {
+				continue // Rejected by validation
+			}
+			a.visiting[s.Name] = true
+			total += a.selectionSet(fragment.SelectionSet)
+			a.visiting[s.Name] = false
+		}
+	}
+	return total
+}
+
+// arguments resolves the values of arguments, substituting variables
+func (a *analyzer) arguments(arguments ast.ArgumentList) map[string]interface{} {
+	values := make(map[string]interface{}, len(arguments))
+	for _, argument := range arguments {
+		if value, err := argument.Value.Value(a.variables); err == nil {
+			values[argument.Name] = value
+		}
+	}
+	return values
+}
+
+// IntArgument returns an integer argument resolved by Analyze, or fallback when
+// it is missing or not a number
+func IntArgument(args map[string]interface{}, name string, fallback int) int {
+	switch value := args[name].(type) {
+	case int64:
+		return int(value)
+	case float64: // Variables decoded from JSON
+		return int(value)
+	case int:
+		return value
+	}
+	return fallback
+}
+
+// ReadOnly reports whether the operation is a query, which may be sent with GET
+func (o Operation) ReadOnly() bool {
+	return o.Type == ast.Query
+}
@@ -0,0 +1,74 @@
+package gql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Unit tests for query complexity analysis
+
+// testListSize treats posts as a list of limit items and authors as a list of 3
+func testListSize(field string, args map[string]interface{}) int {
+	switch field {
+	case "posts":
+		return IntArgument(args, "limit", 10)
+	case "authors":
+		return 3
+	}
+	return 1
+}
+
+func TestAnalyzeComplexity(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		variables  map[string]interface{}
+		complexity int
+	}{
+		{"scalar fields", `{ post(id: "1") { id title } }`, nil, 3},
+		{"list multiplier", `{ posts(limit: 5) { id title } }`, nil, 11},
+		{"nested lists", `{ posts(limit: 2) { id authors { name } } }`, nil, 1 + 2*(1+1+3*1)},
+		{"default limit", `{ posts { id } }`, nil, 11},
+		{"variable", `query($n: Int) { posts(limit: $n) { id } }`, map[string]interface{}{"n": float64(20)}, 21},
+		{"variable default", `query($n: Int = 4) { posts(limit: $n) { id } }`, nil, 5},
+		{"fragment", `{ posts(limit: 2) { ...f } } fragment f on Post { id title }`, nil, 5},
+		{"inline fragment", `{ posts(limit: 2) { ... on Post { id } } }`, nil, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			operation, err := Analyze(tt.query, "", tt.variables, testListSize)
+			require.NoError(t, err)
+			assert.Equal(t, tt.complexity, operation.Complexity)
+			assert.True(t, operation.ReadOnly())
+		})
+	}
+}
+
+func TestAnalyzeOperationSelection(t *testing.T) {
+	document := `query A { post(id: "1") { id } } mutation B { likePost(id: "1") }`
+
+	_, err := Analyze(document, "", nil, testListSize)
+	assert.Error(t, err, "operationName is required with several operations")
+
+	_, err = Analyze(document, "C", nil, testListSize)
+	assert.Error(t, err)
+
+	operation, err := Analyze(document, "B", nil, testListSize)
+	require.NoError(t, err)
+	assert.Equal(t, ast.Mutation, operation.Type)
+	assert.False(t, operation.ReadOnly())
+	assert.Equal(t, 1, operation.Complexity)
+
+	_, err = Analyze(`{ post(id: "1") { id }`, "", nil, testListSize)
+	assert.Error(t, err, "syntax errors are reported")
+}
+
+func TestAnalyzeFragmentCycle(t *testing.T) {
+	query := `{ post(id: "1") { ...a } } fragment a on Post { id ...b } fragment b on Post { title ...a }`
+	operation, err := Analyze(query, "", nil, testListSize)
+	require.NoError(t, err)
+	assert.Equal(t, 3, operation.Complexity, "fragments expanded again are skipped")
+}
@@ -0,0 +1,151 @@
+package gql
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Batching defaults of loaders. Resolvers of a list run concurrently, so keys
+// requested within the wait end up in the same batch.
+const (
+	DefaultBatchWait = 2 * time.Millisecond
+	DefaultBatchSize = 100
+)
+
+// FetchFunc fetches the values of a batch of keys in one query. Keys without a
+// value are simply left out of the result.
+type FetchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader batches and caches the lookups of a request, in the manner of
+// DataLoader: the keys loaded by concurrent resolvers are fetched together, and
+// every key is fetched at most once. Loaders are meant to live for one request.
+type Loader[K comparable, V any] struct {
+	fetch FetchFunc[K, V]
+	wait  time.Duration
+	size  int
+
+	mu      sync.Mutex
+	results map[K]*result[V]
+	pending []K
+	timer   *time.Timer
+}
+
+// result is the outcome of loading a key, available once done is closed
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	found bool
+	err   error
+}
+
+// NewLoader returns a loader fetching batches of up to DefaultBatchSize keys
+// collected over DefaultBatchWait
+func NewLoader[K comparable, V any](fetch FetchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:   fetch,
+		wait:    DefaultBatchWait,
+		size:    DefaultBatchSize,
+		results: make(map[K]*result[V]),
+	}
+}
+
+// Load returns the value of key, and false when the fetch found none
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, bool, error) {
+	l.mu.Lock()
+	r, ok := l.results[key]
+	if !ok {
+		r = &result[V]{done: make(chan struct{})}
+		l.results[key] = r
+		l.pending = append(l.pending, key)
+		if len(l.pending) >= l.size {
+			l.dispatchLocked(ctx)
+		} else if l.timer == nil {
+			l.timer = time.AfterFunc(l.wait, func() {
+				l.mu.Lock()
+				defer l.mu.Unlock()
+				l.dispatchLocked(ctx)
+			})
+		}
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return r.value, r.found, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, false, ctx.Err()
+	}
+}
+
+// LoadMany returns the values of keys that were found, in the order of keys
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) ([]V, error) {
+	results := make([]V, len(keys))
+	found := make([]bool, len(keys))
+	errs := make([]error, len(keys))
+
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i int, key K) {
+			defer wg.Done()
+			results[i], found[i], errs[i] = l.Load(ctx, key)
+		}(i, key)
+	}
+	wg.Wait()
+
+	values := make([]V, 0, len(keys))
+	for i := range keys {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if found[i] {
+			values = append(values, results[i])
+		}
+	}
+	return values, nil
+}
+
+// Prime caches the value of key, so that loading it doesn't fetch it again.
+// Keys already loaded or being loaded keep their value.
+func (l *Loader[K, V]) Prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.results[key]; ok {
+		return
+	}
+	r := &result[V]{done: make(chan struct{}), value: value, found: true}
+	close(r.done)
+	l.results[key] = r
+}
+
+// dispatchLocked fetches the pending keys in the background. The caller holds l.mu.
+func (l *Loader[K, V]) dispatchLocked(ctx context.Context) {
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	if len(l.pending) == 0 {
+		return
+	}
+
+	keys := l.pending
+	batch := make([]*result[V], len(keys))
+	for i, key := range keys {
+		batch[i] = l.results[key]
+	}
+	l.pending = nil
+
+	go func() {
+		values, err := l.fetch(ctx, keys)
+		for i, key := range keys {
+			r := batch[i]
+			r.err = err
+			if err == nil {
+				r.value, r.found = values[key]
+			}
+			close(r.done)
+		}
+	}()
+}
@@ -0,0 +1,99 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for batching loaders
+
+func TestLoaderBatchesConcurrentLoads(t *testing.T) {
+	var calls int32
+	var batch []int
+	loader := NewLoader(func(ctx context.Context, keys []int) (map[int]string, error) {
+		atomic.AddInt32(&calls, 1)
+		batch = append([]int(nil), keys...)
+		values := make(map[int]string)
+		for _, key := range keys {
+			if key%2 == 0 {
+				values[key] = "even"
+			}
+		}
+		return values, nil
+	})
+
+	var wg sync.WaitGroup
+	for key := 1; key <= 4; key++ {
+		wg.Add(1)
+		go func(key int) {
+			defer wg.Done()
+			value, found, err := loader.Load(context.Background(), key)
+			require.NoError(t, err)
+			assert.Equal(t, key%2 == 0, found)
+			if found {
+				assert.Equal(t, "even", value)
+			}
+		}(key)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	sort.Ints(batch)
+	assert.Equal(t, []int{1, 2, 3, 4}, batch)
+
+	// Loaded keys are cached
+	_, found, err := loader.Load(context.Background(), 2)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestLoaderLoadMany(t *testing.T) {
+	loader := NewLoader(func(ctx context.Context, keys []string) (map[string]int, error) {
+		return map[string]int{"a": 1, "c": 3}, nil
+	})
+
+	values, err := loader.LoadMany(context.Background(), []string{"c", "b", "a"})
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 1}, values, "found values are returned in the order of keys")
+}
+
+func TestLoaderSplitsBatchesBySize(t *testing.T) {
+	var calls int32
+	loader := NewLoader(func(ctx context.Context, keys []int) (map[int]int, error) {
+		atomic.AddInt32(&calls, 1)
+		assert.LessOrEqual(t, len(keys), DefaultBatchSize)
+		return map[int]int{}, nil
+	})
+
+	keys := make([]int, DefaultBatchSize+1)
+	for i := range keys {
+		keys[i] = i
+	}
+	_, err := loader.LoadMany(context.Background(), keys)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&calls), int32(2))
+}
+
+func TestLoaderErrorsAndPrime(t *testing.T) {
+	failure := errors.New("database unavailable")
+	loader := NewLoader(func(ctx context.Context, keys []int) (map[int]int, error) {
+		return nil, failure
+	})
+
+	_, err := loader.LoadMany(context.Background(), []int{1, 2})
+	assert.ErrorIs(t, err, failure)
+
+	loader.Prime(3, 30)
+	value, found, err := loader.Load(context.Background(), 3)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 30, value)
+}
@@ -0,0 +1,106 @@
+package gql
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Errors of persisted queries, with the message and code clients of the Automatic
+// Persisted Queries protocol expect. An unknown hash isn't a failure: clients
+// retry with the query's text, so it is reported without an error status.
+var (
+	ErrPersistedQueryNotFound     = &Error{Message: "PersistedQueryNotFound", Code: "PERSISTED_QUERY_NOT_FOUND"}
+	ErrPersistedQueryHashMismatch = &Error{Message: "provided sha256Hash does not match query", Code: "BAD_REQUEST", Status: http.StatusBadRequest}
+	ErrPersistedQueryVersion      = &Error{Message: "unsupported persistedQuery version", Code: "BAD_REQUEST", Status: http.StatusBadRequest}
+)
+
+// PersistedQuery is the persistedQuery extension of a request, naming a query
+// by the SHA-256 hash of its text
+type PersistedQuery struct {
+	Version    int    `json:"version"`
+	SHA256Hash string `json:"sha256Hash"`
+}
+
+// PersistedQueries stores queries by hash, so that clients may send the hash of
+// a query instead of its text, keeping requests small and cacheable as GETs.
+// The least recently used queries are evicted beyond capacity; clients then
+// send the text again.
+type PersistedQueries struct {
+	capacity int
+
+	mu      sync.Mutex
+	order   *list.List // Hashes, most recently used first
+	queries map[string]*list.Element
+}
+
+// persistedEntry is a stored query
+type persistedEntry struct {
+	hash  string
+	query string
+}
+
+// NewPersistedQueries returns a store of up to capacity queries
+func NewPersistedQueries(capacity int) *PersistedQueries {
+	return &PersistedQueries{
+		capacity: capacity,
+		order:    list.New(),
+		queries:  make(map[string]*list.Element),
+	}
+}
+
+// Resolve returns the text of a request's query. A query sent with its hash is
+// stored; a hash sent alone is looked up, failing with ErrPersistedQueryNotFound
+// until its query has been sent once.
+func (p *PersistedQueries) Resolve(query string, extension *PersistedQuery) (string, error) {
+	if extension == nil {
+		return query, nil
+	}
+	if extension.Version != 1 {
+		return "", ErrPersistedQueryVersion
+	}
+	hash := strings.ToLower(extension.SHA256Hash)
+
+	if query == "" {
+		if stored, ok := p.get(hash); ok {
+			return stored, nil
+		}
+		return "", ErrPersistedQueryNotFound
+	}
+
+	sum := sha256.Sum256([]byte(query))
+	if hex.EncodeToString(sum[:]) != hash {
+		return "", ErrPersistedQueryHashMismatch
+	}
+	p.put(hash, query)
+	return query, nil
+}
+
+func (p *PersistedQueries) get(hash string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	element, ok := p.queries[hash]
+	if !ok {
+		return "", false
+	}
+	p.order.MoveToFront(element)
+	return element.Value.(persistedEntry).query, true
+}
+
+func (p *PersistedQueries) put(hash, query string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if element, ok := p.queries[hash]; ok {
+		p.order.MoveToFront(element)
+		return
+	}
+	p.queries[hash] = p.order.PushFront(persistedEntry{hash: hash, query: query})
+	for p.order.Len() > p.capacity {
+		oldest := p.order.Back()
+		p.order.Remove(oldest)
+		delete(p.queries, oldest.Value.(persistedEntry).hash)
+	}
+}
@@ -0,0 +1,74 @@
+package gql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for persisted queries
+
+func queryHash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestPersistedQueriesFlow(t *testing.T) {
+	store := NewPersistedQueries(10)
+	query := `{ posts { items { id } } }`
+	extension := &PersistedQuery{Version: 1, SHA256Hash: queryHash(query)}
+
+	// Plain queries pass through
+	resolved, err := store.Resolve(query, nil)
+	require.NoError(t, err)
+	assert.Equal(t, query, resolved)
+
+	// The hash alone is unknown until the query has been sent with it
+	_, err = store.Resolve("", extension)
+	assert.Equal(t, ErrPersistedQueryNotFound, err)
+
+	resolved, err = store.Resolve(query, extension)
+	require.NoError(t, err)
+	assert.Equal(t, query, resolved)
+
+	resolved, err = store.Resolve("", &PersistedQuery{Version: 1, SHA256Hash: strings.ToUpper(extension.SHA256Hash)})
+	require.NoError(t, err)
+	assert.Equal(t, query, resolved)
+}
+
+func TestPersistedQueriesRejectsInvalidExtensions(t *testing.T) {
+	store := NewPersistedQueries(10)
+	query := `{ tags { name } }`
+
+	_, err := store.Resolve(query, &PersistedQuery{Version: 1, SHA256Hash: queryHash("{ other }")})
+	assert.Equal(t, ErrPersistedQueryHashMismatch, err)
+
+	_, err = store.Resolve(query, &PersistedQuery{Version: 2, SHA256Hash: queryHash(query)})
+	assert.Equal(t, ErrPersistedQueryVersion, err)
+}
+
+func TestPersistedQueriesEvictsLeastRecentlyUsed(t *testing.T) {
+	store := NewPersistedQueries(2)
+	queries := []string{`{ a }`, `{ b }`, `{ c }`}
+
+	for _, query := range queries[:2] {
+		_, err := store.Resolve(query, &PersistedQuery{Version: 1, SHA256Hash: queryHash(query)})
+		require.NoError(t, err)
+	}
+	// Using the first query makes the second the least recently used
+	_, err := store.Resolve("", &PersistedQuery{Version: 1, SHA256Hash: queryHash(queries[0])})
+	require.NoError(t, err)
+	_, err = store.Resolve(queries[2], &PersistedQuery{Version: 1, SHA256Hash: queryHash(queries[2])})
+	require.NoError(t, err)
+
+	_, err = store.Resolve("", &PersistedQuery{Version: 1, SHA256Hash: queryHash(queries[1])})
+	assert.Equal(t, ErrPersistedQueryNotFound, err)
+	for _, query := range []string{queries[0], queries[2]} {
+		_, err = store.Resolve("", &PersistedQuery{Version: 1, SHA256Hash: queryHash(query)})
+		assert.NoError(t, err)
+	}
+}
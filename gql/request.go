@@ -0,0 +1,113 @@
+package gql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+// Defaults of the limits on queries
+const (
+	DefaultMaxDepth      = 10
+	DefaultMaxComplexity = 1000
+	MaxRequestBytes      = 1 << 20
+)
+
+// MaxDepth returns the maximum nesting of fields in a query from GRAPHQL_MAX_DEPTH
+func MaxDepth() int {
+	return envLimit("GRAPHQL_MAX_DEPTH", DefaultMaxDepth)
+}
+
+// MaxComplexity returns the maximum complexity of a query, as computed by
+// Analyze, from GRAPHQL_MAX_COMPLEXITY
+func MaxComplexity() int {
+	return envLimit("GRAPHQL_MAX_COMPLEXITY", DefaultMaxComplexity)
+}
+
+func envLimit(name string, fallback int) int {
+	if value := os.Getenv(name); value != "" {
+		if limit, err := strconv.Atoi(value); err == nil && limit > 0 {
+			return limit
+		}
+	}
+	return fallback
+}
+
+// Request is a GraphQL request, sent as a JSON body with POST or as query
+// parameters with GET
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Extensions    struct {
+		PersistedQuery *PersistedQuery `json:"persistedQuery"`
+	} `json:"extensions"`
+}
+
+// ParseRequest reads the GraphQL request of an HTTP request
+func ParseRequest(r *http.Request) (Request, error) {
+	var request Request
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		request.Query = query.Get("query")
+		request.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				return request, fmt.Errorf("variables must be a JSON object: %w", err)
+			}
+		}
+		if extensions := query.Get("extensions"); extensions != "" {
+			if err := json.Unmarshal([]byte(extensions), &request.Extensions); err != nil {
+				return request, fmt.Errorf("extensions must be a JSON object: %w", err)
+			}
+		}
+		return request, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, MaxRequestBytes+1))
+	if err != nil {
+		return request, err
+	}
+	if len(body) > MaxRequestBytes {
+		return request, fmt.Errorf("request body exceeds %d bytes", MaxRequestBytes)
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return request, fmt.Errorf("request body must be a JSON object: %w", err)
+	}
+	return request, nil
+}
+
+// Error is an error of a GraphQL response, reported with the code and HTTP
+// status the REST API uses for it
+type Error struct {
+	Message string
+	Code    string
+	Status  int
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Extensions exposes the code and status of the error in GraphQL responses
+func (e *Error) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": e.Code}
+	if e.Status != 0 {
+		extensions["status"] = e.Status
+	}
+	return extensions
+}
+
+// ErrorResponse is the body of a GraphQL response for a request that couldn't be executed
+func ErrorResponse(err error) map[string]interface{} {
+	message := map[string]interface{}{"message": err.Error()}
+	var gqlErr *Error
+	if errors.As(err, &gqlErr) {
+		message["extensions"] = gqlErr.Extensions()
+	}
+	return map[string]interface{}{"errors": []interface{}{message}}
+}
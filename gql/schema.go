@@ -0,0 +1,12 @@
+// Package gql provides the building blocks of the GraphQL API: its schema,
+// request parsing, query complexity analysis, persisted queries and batching
+// loaders. Resolvers live with the REST handlers, whose helpers they share, and
+// send mutations to the REST API through package dispatch.
+package gql
+
+import _ "embed"
+
+// Schema is the GraphQL schema of the blog API
+//
+//go:embed schema.graphql
+var Schema string
@@ -0,0 +1,123 @@
+package grpcapi
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+
+	"dbl-blog-backend/audit"
+	"dbl-blog-backend/middleware"
+	"dbl-blog-backend/models"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// apiKeyMetadata is the metadata key carrying the API key of a call
+const apiKeyMetadata = "x-api-key"
+
+// forwardedMetadata are the metadata keys passed on to the REST API as headers.
+// X-Forwarded-For and X-Real-IP aren't among them: the client IP of the REST
+// requests, which rate limits and audit entries use, is the peer of the call.
+var forwardedMetadata = []string{"user-agent", "accept-language"}
+
+// caller identifies the client of an authenticated call
+type caller struct {
+	header     http.Header // Headers of the REST requests made for the call
+	remoteAddr string
+	requestID  string
+	clientIP   string
+	role       middleware.Role
+}
+
+type callerKey struct{}
+
+// callerFrom returns the caller of an authenticated call
+func callerFrom(ctx context.Context) caller {
+	return ctx.Value(callerKey{}).(caller)
+}
+
+// authenticate requires a valid API key in the metadata of a call, as
+// RequirePermission does for REST requests. Permissions are checked by the REST
+// routes the call is dispatched to.
+func authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	c := caller{header: http.Header{}, requestID: first("x-request-id")}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		c.remoteAddr = p.Addr.String()
+		if host, _, err := net.SplitHostPort(c.remoteAddr); err == nil {
+			c.clientIP = host
+		}
+	}
+
+	key := first(apiKeyMetadata)
+	role, ok := middleware.Authenticate(key)
+	if !ok {
+		reason := "invalid API key"
+		if key == "" {
+			reason = "missing API key"
+		}
+		log.Printf("[SECURITY] gRPC: Rejected %s from %s - %s", fullMethod, c.clientIP, reason)
+		entry := models.AuditEntry{
+			Action:    audit.ActionAuthFailure,
+			ClientIP:  c.clientIP,
+			RequestID: c.requestID,
+			Method:    "GRPC",
+			Path:      fullMethod,
+			Reason:    reason,
+		}
+		if key != "" {
+			entry.ActorKeyID = middleware.APIKeyID(key)
+		}
+		audit.Record(entry)
+		return nil, status.Errorf(codes.Unauthenticated, "%s: send a valid API key in the %s metadata", reason, apiKeyMetadata)
+	}
+
+	c.role = role
+	c.header.Set("X-API-Key", key)
+	for _, name := range forwardedMetadata {
+		for _, value := range md.Get(name) {
+			c.header.Add(name, value)
+		}
+	}
+	return context.WithValue(ctx, callerKey{}, c), nil
+}
+
+// authUnary authenticates unary calls
+func authUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// authStream authenticates streaming calls
+func authStream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := authenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authenticatedStream is a stream carrying the caller in its context
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,132 @@
+package grpcapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"dbl-blog-backend/dispatch"
+	"dbl-blog-backend/grpcapi/postpb"
+	"dbl-blog-backend/models"
+	"dbl-blog-backend/webhooks"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// errorDomain is the domain of the ErrorInfo details of statuses
+const errorDomain = "dbl-blog-backend"
+
+// statusCodes maps the HTTP statuses of the REST API to gRPC codes
+var statusCodes = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusConflict:              codes.AlreadyExists,
+	http.StatusPreconditionFailed:    codes.Aborted, // Stale version
+	http.StatusPreconditionRequired:  codes.FailedPrecondition,
+	http.StatusRequestEntityTooLarge: codes.InvalidArgument,
+	http.StatusUnsupportedMediaType:  codes.InvalidArgument,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	http.StatusNotImplemented:        codes.Unimplemented,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+}
+
+// statusError converts an error response of the REST API into a status with the
+// REST error code as the reason of its ErrorInfo details
+func statusError(err error) error {
+	var dispatchErr *dispatch.Error
+	if !errors.As(err, &dispatchErr) {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	code, ok := statusCodes[dispatchErr.Status]
+	if !ok {
+		code = codes.Unknown
+		if dispatchErr.Status >= http.StatusInternalServerError {
+			code = codes.Internal
+		}
+	}
+	st := status.New(code, dispatchErr.Message)
+	detailed, detailsErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   dispatchErr.Code,
+		Domain:   errorDomain,
+		Metadata: map[string]string{"http_status": strconv.Itoa(dispatchErr.Status)},
+	})
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// toPost converts a post into its message
+func toPost(post models.Post) *postpb.Post {
+	message := &postpb.Post{
+		Id:                 post.ID.Hex(),
+		Title:              post.Title,
+		Content:            post.Content,
+		Slug:               post.Slug,
+		Summary:            post.Summary,
+		Tags:               post.Tags,
+		Published:          post.Published,
+		Views:              post.Views,
+		Likes:              post.Likes,
+		Version:            post.Version,
+		Lang:               post.Lang,
+		WordCount:          int32(post.WordCount),
+		ReadingTimeMinutes: int32(post.ReadingTimeMinutes),
+		CreatedAt:          timestamppb.New(post.CreatedAt),
+		UpdatedAt:          timestamppb.New(post.UpdatedAt),
+	}
+	if !post.TranslationGroupID.IsZero() {
+		message.TranslationGroupId = post.TranslationGroupID.Hex()
+	}
+	for _, id := range post.AuthorIDs {
+		message.AuthorIds = append(message.AuthorIds, id.Hex())
+	}
+	for _, entry := range post.TOC {
+		message.Toc = append(message.Toc, &postpb.TocEntry{Level: int32(entry.Level), Text: entry.Text, Id: entry.ID})
+	}
+	return message
+}
+
+// toPostEvent converts a post event into its message
+func toPostEvent(event webhooks.Event) *postpb.PostEvent {
+	return &postpb.PostEvent{
+		Id:        event.ID,
+		Type:      event.Type,
+		CreatedAt: timestamppb.New(event.CreatedAt),
+		Post:      toPost(event.Data.Post),
+	}
+}
+
+// postInputBody returns the JSON body of the REST request writing input. Empty
+// optional fields are left out, so that the REST API applies its defaults.
+func postInputBody(input *postpb.PostInput) map[string]interface{} {
+	body := map[string]interface{}{
+		"title":     input.GetTitle(),
+		"content":   input.GetContent(),
+		"published": input.GetPublished(),
+	}
+	optional := map[string]string{
+		"slug":                 input.GetSlug(),
+		"summary":              input.GetSummary(),
+		"lang":                 input.GetLang(),
+		"translation_group_id": input.GetTranslationGroupId(),
+	}
+	for name, value := range optional {
+		if value != "" {
+			body[name] = value
+		}
+	}
+	if len(input.GetTags()) > 0 {
+		body["tags"] = input.GetTags()
+	}
+	if len(input.GetAuthorIds()) > 0 {
+		body["author_ids"] = input.GetAuthorIds()
+	}
+	return body
+}
@@ -0,0 +1,210 @@
+package grpcapi
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"dbl-blog-backend/dispatch"
+	"dbl-blog-backend/grpcapi/postpb"
+	"dbl-blog-backend/middleware"
+	"dbl-blog-backend/models"
+	"dbl-blog-backend/watch"
+	"dbl-blog-backend/webhooks"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// postService implements PostService on the REST API
+type postService struct {
+	postpb.UnimplementedPostServiceServer
+	router http.Handler
+}
+
+// do sends a request to the REST API on behalf of the caller of ctx, converting
+// error responses into statuses
+func (s *postService) do(ctx context.Context, method, path string, body, out interface{}) error {
+	c := callerFrom(ctx)
+	err := dispatch.New(s.router, c.header, c.remoteAddr, c.requestID).Do(ctx, method, path, body, out)
+	if err != nil {
+		return statusError(err)
+	}
+	return nil
+}
+
+// postPath returns the REST path of a post, or of one of its actions
+func postPath(id, action string) (string, error) {
+	if id == "" {
+		return "", status.Error(codes.InvalidArgument, "id is required")
+	}
+	path := "/api/v1/posts/" + url.PathEscape(id)
+	if action != "" {
+		path += "/" + action
+	}
+	return path, nil
+}
+
+// Get returns a post through GetPost
+func (s *postService) Get(ctx context.Context, req *postpb.GetPostRequest) (*postpb.Post, error) {
+	path, err := postPath(req.GetId(), "")
+	if err != nil {
+		return nil, err
+	}
+	if req.GetLang() != "" {
+		path += "?lang=" + url.QueryEscape(req.GetLang())
+	}
+
+	var post models.Post
+	if err := s.do(ctx, http.MethodGet, path, nil, &post); err != nil {
+		return nil, err
+	}
+	return toPost(post), nil
+}
+
+// List returns a page of posts through GetPosts
+func (s *postService) List(ctx context.Context, req *postpb.ListPostsRequest) (*postpb.ListPostsResponse, error) {
+	query := url.Values{}
+	if req.GetPage() != 0 {
+		query.Set("page", strconv.Itoa(int(req.GetPage())))
+	}
+	if req.GetLimit() != 0 {
+		query.Set("limit", strconv.Itoa(int(req.GetLimit())))
+	}
+	if req.Published != nil {
+		query.Set("published", strconv.FormatBool(req.GetPublished()))
+	}
+	for name, value := range map[string]string{"tag": req.GetTag(), "lang": req.GetLang(), "sort": req.GetSort()} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	if len(req.GetFields()) > 0 {
+		query.Set("fields", strings.Join(req.GetFields(), ","))
+	}
+
+	var page struct {
+		Posts []models.Post `json:"posts"`
+		Page  int32         `json:"page"`
+		Limit int32         `json:"limit"`
+		Total int64         `json:"total"`
+	}
+	if err := s.do(ctx, http.MethodGet, "/api/v1/posts?"+query.Encode(), nil, &page); err != nil {
+		return nil, err
+	}
+
+	response := &postpb.ListPostsResponse{Page: page.Page, Limit: page.Limit, Total: page.Total}
+	for _, post := range page.Posts {
+		response.Posts = append(response.Posts, toPost(post))
+	}
+	return response, nil
+}
+
+// Create creates a post through CreatePost
+func (s *postService) Create(ctx context.Context, req *postpb.CreatePostRequest) (*postpb.Post, error) {
+	var post models.Post
+	if err := s.do(ctx, http.MethodPost, "/api/v1/posts", postInputBody(req.GetPost()), &post); err != nil {
+		return nil, err
+	}
+	return toPost(post), nil
+}
+
+// Update replaces a post through UpdatePost
+func (s *postService) Update(ctx context.Context, req *postpb.UpdatePostRequest) (*postpb.Post, error) {
+	path, err := postPath(req.GetId(), "")
+	if err != nil {
+		return nil, err
+	}
+	body := postInputBody(req.GetPost())
+	body["version"] = req.GetVersion()
+
+	var post models.Post
+	if err := s.do(ctx, http.MethodPut, path, body, &post); err != nil {
+		return nil, err
+	}
+	return toPost(post), nil
+}
+
+// Delete deletes a post through DeletePost
+func (s *postService) Delete(ctx context.Context, req *postpb.DeletePostRequest) (*postpb.DeletePostResponse, error) {
+	path, err := postPath(req.GetId(), "")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.do(ctx, http.MethodDelete, path, nil, nil); err != nil {
+		return nil, err
+	}
+	return &postpb.DeletePostResponse{}, nil
+}
+
+// Like likes a post through LikePost
+func (s *postService) Like(ctx context.Context, req *postpb.LikePostRequest) (*postpb.LikePostResponse, error) {
+	path, err := postPath(req.GetId(), "like")
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Likes int64 `json:"likes"`
+	}
+	if err := s.do(ctx, http.MethodPut, path, nil, &result); err != nil {
+		return nil, err
+	}
+	return &postpb.LikePostResponse{Likes: result.Likes}, nil
+}
+
+// View records a view of a post through TrackPostView
+func (s *postService) View(ctx context.Context, req *postpb.ViewPostRequest) (*postpb.ViewPostResponse, error) {
+	path, err := postPath(req.GetId(), "view")
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Views int64 `json:"views"`
+	}
+	if err := s.do(ctx, http.MethodPut, path, nil, &result); err != nil {
+		return nil, err
+	}
+	return &postpb.ViewPostResponse{Views: result.Views}, nil
+}
+
+// WatchPosts streams the post events committed by this instance until the
+// client cancels. Opening a stream counts against the admin rate limit.
+func (s *postService) WatchPosts(req *postpb.WatchPostsRequest, stream postpb.PostService_WatchPostsServer) error {
+	for _, eventType := range req.GetTypes() {
+		if !webhooks.ValidEvent(eventType) {
+			return status.Errorf(codes.InvalidArgument, "types must be among: %s", strings.Join(webhooks.Events, ", "))
+		}
+	}
+
+	c := callerFrom(stream.Context())
+	if !middleware.AllowAdminRequest(c.clientIP) {
+		log.Printf("[SECURITY] gRPC WatchPosts: Rate limit exceeded for IP %s", c.clientIP)
+		return status.Error(codes.ResourceExhausted, "Too many requests: please wait before trying again")
+	}
+
+	watcher := watch.Subscribe(req.GetTypes()...)
+	defer watcher.Close()
+	log.Printf("[INFO] gRPC WatchPosts: %s watching %v from %s", c.role, req.GetTypes(), c.clientIP)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			log.Printf("[INFO] gRPC WatchPosts: Watcher from %s disconnected", c.clientIP)
+			return nil
+		case event, ok := <-watcher.Events():
+			if !ok {
+				if watcher.Lagged() {
+					log.Printf("[ERROR] gRPC WatchPosts: Watcher from %s fell behind", c.clientIP)
+					return status.Error(codes.ResourceExhausted, "watcher fell behind and missed events; resubscribe and resynchronize with List")
+				}
+				return nil
+			}
+			if err := stream.Send(toPostEvent(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Package grpcapi serves the PostService of proto/blog/v1/post.proto to internal
+// consumers. Calls are executed by the REST handlers through package dispatch,
+// so both APIs share their validation, authorization, rate limiting, audit and
+// webhooks; WatchPosts streams the events published to package watch.
+package grpcapi
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"os"
+
+	"dbl-blog-backend/grpcapi/postpb"
+
+	"google.golang.org/grpc"
+)
+
+// NewServer returns a gRPC server of PostService executing calls on router
+func NewServer(router http.Handler) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(authUnary),
+		grpc.ChainStreamInterceptor(authStream),
+	)
+	postpb.RegisterPostServiceServer(server, &postService{router: router})
+	return server
+}
+
+// Start serves gRPC on GRPC_PORT in the background. The server is disabled when
+// GRPC_PORT isn't set, as on serverless deployments.
+func Start(router http.Handler) {
+	port := os.Getenv("GRPC_PORT")
+	if port == "" {
+		log.Printf("gRPC server disabled (GRPC_PORT not set)")
+		return
+	}
+
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Fatal("Failed to listen for gRPC:", err)
+	}
+
+	server := NewServer(router)
+	go func() {
+		log.Printf("gRPC server starting on port %s", port)
+		if err := server.Serve(listener); err != nil {
+			log.Fatal("Failed to serve gRPC:", err)
+		}
+	}()
+}
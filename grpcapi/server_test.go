@@ -0,0 +1,256 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/dispatch"
+	"dbl-blog-backend/grpcapi/postpb"
+	"dbl-blog-backend/models"
+	"dbl-blog-backend/watch"
+	"dbl-blog-backend/webhooks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Unit tests for the gRPC API, served over an in-memory connection to a fake
+// REST API
+
+const testAPIKey = "grpc-test-key"
+
+var testPostID = primitive.NewObjectID()
+
+func testRESTRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/posts/:id", func(c *gin.Context) {
+		if c.Param("id") != testPostID.Hex() {
+			apierrors.RespondPostNotFound(c)
+			return
+		}
+		c.JSON(http.StatusOK, models.Post{
+			ID:        testPostID,
+			Title:     "Hello",
+			Slug:      "hello",
+			Tags:      []string{"go"},
+			Published: true,
+			Lang:      c.Query("lang"),
+			Summary:   c.GetHeader("X-API-Key"),
+		})
+	})
+	router.GET("/api/v1/posts", func(c *gin.Context) {
+		// Filters by tag as GetPosts does
+		var posts []models.Post
+		for _, post := range []models.Post{
+			{ID: primitive.NewObjectID(), Title: "Go", Tags: []string{"go"}},
+			{ID: primitive.NewObjectID(), Title: "Rust", Tags: []string{"rust"}},
+			{ID: primitive.NewObjectID(), Title: "Go and Rust", Tags: []string{"rust", "go"}},
+		} {
+			if tag := c.Query("tag"); tag == "" || containsString(post.Tags, tag) {
+				posts = append(posts, post)
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"posts": posts, "page": 1, "limit": 10, "total": len(posts)})
+	})
+	router.POST("/api/v1/posts", func(c *gin.Context) {
+		var body map[string]interface{}
+		require.NoError(t, c.ShouldBindJSON(&body))
+		assert.Equal(t, map[string]interface{}{"title": "New", "content": "Body", "published": false, "tags": []interface{}{"go"}}, body)
+		c.JSON(http.StatusCreated, models.Post{ID: testPostID, Title: "New", Content: "Body", Tags: []string{"go"}, Version: 1})
+	})
+	return router
+}
+
+// testClient serves the gRPC API on router and returns a client of it
+func testClient(t *testing.T, router http.Handler) postpb.PostServiceClient {
+	t.Setenv("ADMIN_API_KEYS", testAPIKey)
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(router)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return postpb.NewPostServiceClient(conn)
+}
+
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadata, key)
+}
+
+func TestRequiresAPIKey(t *testing.T) {
+	client := testClient(t, testRESTRouter(t))
+
+	_, err := client.Get(context.Background(), &postpb.GetPostRequest{Id: testPostID.Hex()})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.Get(withKey("wrong-key"), &postpb.GetPostRequest{Id: testPostID.Hex()})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGetDispatchesToREST(t *testing.T) {
+	client := testClient(t, testRESTRouter(t))
+
+	post, err := client.Get(withKey(testAPIKey), &postpb.GetPostRequest{Id: testPostID.Hex(), Lang: "pt"})
+	require.NoError(t, err)
+	assert.Equal(t, testPostID.Hex(), post.GetId())
+	assert.Equal(t, "Hello", post.GetTitle())
+	assert.Equal(t, []string{"go"}, post.GetTags())
+	assert.Equal(t, "pt", post.GetLang())
+	assert.Equal(t, testAPIKey, post.GetSummary(), "the API key should be forwarded to the REST API")
+	assert.Empty(t, post.GetTranslationGroupId())
+}
+
+func TestClientIPComesFromPeer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/posts/:id", func(c *gin.Context) {
+		c.JSON(http.StatusOK, models.Post{
+			ID:      testPostID,
+			Summary: c.GetHeader("X-Forwarded-For") + c.GetHeader("X-Real-IP"),
+			Content: c.ClientIP(),
+		})
+	})
+	client := testClient(t, router)
+
+	ctx := metadata.AppendToOutgoingContext(withKey(testAPIKey), "x-forwarded-for", "203.0.113.9", "x-real-ip", "203.0.113.9")
+	post, err := client.Get(ctx, &postpb.GetPostRequest{Id: testPostID.Hex()})
+	require.NoError(t, err)
+	assert.Empty(t, post.GetSummary(), "proxy headers of the caller shouldn't reach the REST API")
+	assert.NotEqual(t, "203.0.113.9", post.GetContent())
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestListFiltersByTag(t *testing.T) {
+	client := testClient(t, testRESTRouter(t))
+
+	response, err := client.List(withKey(testAPIKey), &postpb.ListPostsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), response.GetTotal())
+
+	response, err = client.List(withKey(testAPIKey), &postpb.ListPostsRequest{Tag: "go"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), response.GetTotal())
+	var titles []string
+	for _, post := range response.GetPosts() {
+		titles = append(titles, post.GetTitle())
+	}
+	assert.Equal(t, []string{"Go", "Go and Rust"}, titles)
+}
+
+func TestRESTErrorsBecomeStatuses(t *testing.T) {
+	client := testClient(t, testRESTRouter(t))
+
+	_, err := client.Get(withKey(testAPIKey), &postpb.GetPostRequest{Id: primitive.NewObjectID().Hex()})
+	st := status.Convert(err)
+	assert.Equal(t, codes.NotFound, st.Code())
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, apierrors.CodeNotFound, info.GetReason())
+	assert.Equal(t, "404", info.GetMetadata()["http_status"])
+
+	_, err = client.Delete(withKey(testAPIKey), &postpb.DeletePostRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestCreateSendsPostInput(t *testing.T) {
+	client := testClient(t, testRESTRouter(t))
+
+	post, err := client.Create(withKey(testAPIKey), &postpb.CreatePostRequest{
+		Post: &postpb.PostInput{Title: "New", Content: "Body", Tags: []string{"go"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), post.GetVersion())
+}
+
+func TestWatchPostsStreamsPublishedEvents(t *testing.T) {
+	client := testClient(t, testRESTRouter(t))
+	ctx, cancel := context.WithTimeout(withKey(testAPIKey), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchPosts(ctx, &postpb.WatchPostsRequest{Types: []string{webhooks.EventPostCreated}})
+	require.NoError(t, err)
+
+	// Events published before the stream subscribed are missed, so keep
+	// publishing until one is received
+	post := models.Post{ID: testPostID, Title: "Hello"}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				watch.Publish(webhooks.NewEvent(webhooks.EventPostDeleted, post), webhooks.NewEvent(webhooks.EventPostCreated, post))
+			}
+		}
+	}()
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, webhooks.EventPostCreated, event.GetType())
+	assert.Equal(t, testPostID.Hex(), event.GetPost().GetId())
+	assert.NotEmpty(t, event.GetId())
+}
+
+func TestWatchPostsRejectsUnknownTypes(t *testing.T) {
+	client := testClient(t, testRESTRouter(t))
+
+	stream, err := client.WatchPosts(withKey(testAPIKey), &postpb.WatchPostsRequest{Types: []string{"post.exploded"}})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestStatusErrorCodes(t *testing.T) {
+	tests := []struct {
+		status int
+		code   codes.Code
+	}{
+		{http.StatusBadRequest, codes.InvalidArgument},
+		{http.StatusForbidden, codes.PermissionDenied},
+		{http.StatusConflict, codes.AlreadyExists},
+		{http.StatusPreconditionFailed, codes.Aborted},
+		{http.StatusTooManyRequests, codes.ResourceExhausted},
+		{http.StatusServiceUnavailable, codes.Unavailable},
+		{http.StatusBadGateway, codes.Internal},
+		{http.StatusTeapot, codes.Unknown},
+	}
+	for _, tt := range tests {
+		err := statusError(&dispatch.Error{Status: tt.status, Code: "CODE", Message: "message"})
+		assert.Equal(t, tt.code, status.Code(err), "HTTP %d", tt.status)
+	}
+}
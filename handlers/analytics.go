@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// postTotals holds the aggregated counters across all posts
+type postTotals struct {
+	Posts     int64 `json:"posts" bson:"posts"`
+	Published int64 `json:"published" bson:"published"`
+	Views     int64 `json:"views" bson:"views"`
+	Likes     int64 `json:"likes" bson:"likes"`
+}
+
+// GetAnalytics returns read-only engagement analytics for all posts
+func GetAnalytics(c *gin.Context) {
+	log.Printf("[INFO] GetAnalytics: Received request from %s", c.ClientIP())
+
+	top, _ := strconv.Atoi(c.DefaultQuery("top", "10"))
+	if top < 1 || top > 100 {
+		top = 10
+	}
+
+	collection := database.Database.Collection("posts")
+
+	// Aggregate totals across all posts
+	cursor, err := collection.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":       nil,
+			"posts":     bson.M{"$sum": 1},
+			"published": bson.M{"$sum": bson.M{"$cond": bson.A{"$published", 1, 0}}},
+			"views":     bson.M{"$sum": "$views"},
+			"likes":     bson.M{"$sum": "$likes"},
+		}}},
+	})
+	if err != nil {
+		log.Printf("[ERROR] GetAnalytics: Failed to aggregate totals - %s", err.Error())
+		apierrors.RespondFailedToFetchAnalytics(c)
+		return
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	var totals postTotals
+	if cursor.Next(context.Background()) {
+		if err := cursor.Decode(&totals); err != nil {
+			log.Printf("[ERROR] GetAnalytics: Failed to decode totals - %s", err.Error())
+			apierrors.RespondFailedToFetchAnalytics(c)
+			return
+		}
+	}
+
+	topByViews, ok := findTopPosts(c, "views", int64(top))
+	if !ok {
+		return
+	}
+	topByLikes, ok := findTopPosts(c, "likes", int64(top))
+	if !ok {
+		return
+	}
+
+	log.Printf("[SUCCESS] GetAnalytics: Aggregated analytics for %d posts", totals.Posts)
+	c.JSON(http.StatusOK, gin.H{
+		"totals":       totals,
+		"top_by_views": topByViews,
+		"top_by_likes": topByLikes,
+	})
+}
+
+// findTopPosts returns the posts with the highest value of field, without their content
+func findTopPosts(c *gin.Context, field string, limit int64) ([]models.Post, bool) {
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: field, Value: -1}, {Key: "_id", Value: 1}}).
+		SetLimit(limit).
+		SetProjection(bson.M{"content": 0})
+
+	collection := database.Database.Collection("posts")
+	cursor, err := collection.Find(context.Background(), bson.M{}, findOptions)
+	if err != nil {
+		log.Printf("[ERROR] GetAnalytics: Failed to find top posts by %s - %s", field, err.Error())
+		apierrors.RespondFailedToFetchAnalytics(c)
+		return nil, false
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	posts := []models.Post{}
+	if err = cursor.All(context.Background(), &posts); err != nil {
+		log.Printf("[ERROR] GetAnalytics: Failed to decode top posts by %s - %s", field, err.Error())
+		apierrors.RespondFailedToFetchAnalytics(c)
+		return nil, false
+	}
+	return posts, true
+}
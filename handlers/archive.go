@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/locale"
+	"dbl-blog-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// archiveMonth is the number of published posts of a month
+type archiveMonth struct {
+	Month int `json:"month"`
+	Count int `json:"count"`
+}
+
+// archiveYear is the number of published posts of a year, broken down by month
+// from the most recent
+type archiveYear struct {
+	Year   int            `json:"year"`
+	Count  int            `json:"count"`
+	Months []archiveMonth `json:"months"`
+}
+
+// archiveRow is a month of the archive as aggregated by MongoDB
+type archiveRow struct {
+	ID struct {
+		Year  int `bson:"year"`
+		Month int `bson:"month"`
+	} `bson:"_id"`
+	Count int `bson:"count"`
+}
+
+// GetArchive returns the number of published posts of each year and month, most
+// recent first, with month boundaries in the timezone of the tz query parameter
+func GetArchive(c *gin.Context) {
+	log.Printf("[INFO] GetArchive: Received request from %s", c.ClientIP())
+
+	location, invalid := archiveLocation(c.Query("tz"))
+	if invalid != "" {
+		log.Printf("[ERROR] GetArchive: Invalid timezone - %s", invalid)
+		apierrors.RespondWithValidationError(c, invalid)
+		return
+	}
+
+	match := archiveFilter(c)
+	createdAt := bson.M{"date": "$created_at", "timezone": location.String()}
+
+	collection := database.Database.Collection("posts")
+	cursor, err := collection.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"year":  bson.M{"$year": createdAt},
+				"month": bson.M{"$month": createdAt},
+			},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.year", Value: -1}, {Key: "_id.month", Value: -1}}}},
+	})
+	if err != nil {
+		log.Printf("[ERROR] GetArchive: Failed to aggregate archive - %s", err.Error())
+		apierrors.RespondFailedToFetchArchive(c)
+		return
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	var rows []archiveRow
+	if err = cursor.All(context.Background(), &rows); err != nil {
+		log.Printf("[ERROR] GetArchive: Failed to decode archive - %s", err.Error())
+		apierrors.RespondFailedToFetchArchive(c)
+		return
+	}
+
+	years, total := groupArchive(rows)
+
+	if respondNotModified(c, archiveETag(location, match, years), time.Time{}) {
+		log.Printf("[SUCCESS] GetArchive: Archive not modified (%d years, total %d)", len(years), total)
+		return
+	}
+
+	log.Printf("[SUCCESS] GetArchive: Aggregated %d posts over %d years", total, len(years))
+	c.JSON(http.StatusOK, gin.H{
+		"years":    years,
+		"timezone": location.String(),
+		"total":    total,
+	})
+}
+
+// GetArchiveMonth returns the published posts of a month in chronological order,
+// without their content, with month boundaries in the timezone of the tz query
+// parameter
+func GetArchiveMonth(c *gin.Context) {
+	log.Printf("[INFO] GetArchiveMonth: Received request for %s/%s from %s", c.Param("year"), c.Param("month"), c.ClientIP())
+
+	location, invalid := archiveLocation(c.Query("tz"))
+	if invalid != "" {
+		log.Printf("[ERROR] GetArchiveMonth: Invalid timezone - %s", invalid)
+		apierrors.RespondWithValidationError(c, invalid)
+		return
+	}
+
+	start, end, invalid := archiveMonthRange(c.Param("year"), c.Param("month"), location)
+	if invalid != "" {
+		log.Printf("[ERROR] GetArchiveMonth: Invalid month - %s", invalid)
+		apierrors.RespondWithValidationError(c, invalid)
+		return
+	}
+
+	match := archiveFilter(c)
+	match["created_at"] = bson.M{"$gte": start, "$lt": end}
+
+	collection := database.Database.Collection("posts")
+	cursor, err := collection.Aggregate(context.Background(), mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$project", Value: bson.M{"content": 0}}},
+	})
+	if err != nil {
+		log.Printf("[ERROR] GetArchiveMonth: Failed to aggregate posts - %s", err.Error())
+		apierrors.RespondFailedToFetchArchive(c)
+		return
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	posts := []models.Post{}
+	if err = cursor.All(context.Background(), &posts); err != nil {
+		log.Printf("[ERROR] GetArchiveMonth: Failed to decode posts - %s", err.Error())
+		apierrors.RespondFailedToDecodePosts(c)
+		return
+	}
+
+	if respondNotModified(c, postsETag(posts, int64(len(posts))), time.Time{}) {
+		log.Printf("[SUCCESS] GetArchiveMonth: Posts of %s not modified (total %d)", start.Format("2006-01"), len(posts))
+		return
+	}
+
+	log.Printf("[SUCCESS] GetArchiveMonth: Retrieved %d posts of %s", len(posts), start.Format("2006-01"))
+	c.JSON(http.StatusOK, gin.H{
+		"posts":    posts,
+		"year":     start.Year(),
+		"month":    int(start.Month()),
+		"timezone": location.String(),
+		"total":    len(posts),
+	})
+}
+
+// archiveFilter matches the published posts of the archive, restricted to the
+// language of the lang query parameter when given
+func archiveFilter(c *gin.Context) bson.M {
+	filter := bson.M{"published": true}
+	if lang := locale.Normalize(c.Query("lang")); lang != "" {
+		filter["lang"] = lang
+	}
+	return filter
+}
+
+// archiveLocation resolves the tz query parameter, an IANA timezone name such as
+// "Europe/Lisbon", defaulting to UTC. It returns an error message for unknown
+// timezones.
+func archiveLocation(tz string) (*time.Location, string) {
+	tz = strings.TrimSpace(tz)
+	if tz == "" {
+		return time.UTC, ""
+	}
+	// The server's local timezone means nothing to readers, nor to MongoDB
+	location, err := time.LoadLocation(tz)
+	if err != nil || tz == "Local" {
+		return nil, "tz must be an IANA timezone name such as 'Europe/Lisbon'"
+	}
+	return location, ""
+}
+
+// archiveMonthRange returns the start of a month in location and the start of
+// the next one. It returns an error message for invalid years and months.
+func archiveMonthRange(year, month string, location *time.Location) (time.Time, time.Time, string) {
+	y, err := strconv.Atoi(year)
+	if err != nil || y < 1 || y > 9999 {
+		return time.Time{}, time.Time{}, "year must be a number between 1 and 9999"
+	}
+	m, err := strconv.Atoi(month)
+	if err != nil || m < 1 || m > 12 {
+		return time.Time{}, time.Time{}, "month must be a number between 1 and 12"
+	}
+	start := time.Date(y, time.Month(m), 1, 0, 0, 0, 0, location)
+	return start, start.AddDate(0, 1, 0), ""
+}
+
+// groupArchive groups months sorted from the most recent into years, returning
+// them with the total number of posts
+func groupArchive(rows []archiveRow) ([]archiveYear, int) {
+	years := []archiveYear{}
+	total := 0
+	for _, row := range rows {
+		if len(years) == 0 || years[len(years)-1].Year != row.ID.Year {
+			years = append(years, archiveYear{Year: row.ID.Year, Months: []archiveMonth{}})
+		}
+		year := &years[len(years)-1]
+		year.Count += row.Count
+		year.Months = append(year.Months, archiveMonth{Month: row.ID.Month, Count: row.Count})
+		total += row.Count
+	}
+	return years, total
+}
+
+// archiveETag returns a strong ETag for the archive, derived from its counts and
+// the timezone and filter they were computed with
+func archiveETag(location *time.Location, filter bson.M, years []archiveYear) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s|%v", location.String(), filter["lang"])
+	for _, year := range years {
+		for _, month := range year.Months {
+			fmt.Fprintf(&b, "|%d-%d:%d", year.Year, month.Month, month.Count)
+		}
+	}
+	return hashETag(b.String())
+}
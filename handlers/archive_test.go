@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Unit tests for archive helpers
+
+func TestArchiveLocation(t *testing.T) {
+	location, invalid := archiveLocation("")
+	assert.Empty(t, invalid)
+	assert.Equal(t, time.UTC, location)
+
+	location, invalid = archiveLocation(" America/Sao_Paulo ")
+	assert.Empty(t, invalid)
+	assert.Equal(t, "America/Sao_Paulo", location.String())
+
+	for _, tz := range []string{"Mars/Olympus", "Local"} {
+		_, invalid = archiveLocation(tz)
+		assert.NotEmpty(t, invalid, tz)
+	}
+}
+
+func TestArchiveMonthRange(t *testing.T) {
+	lisbon, err := time.LoadLocation("Europe/Lisbon")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	start, end, invalid := archiveMonthRange("2024", "12", lisbon)
+	assert.Empty(t, invalid)
+	assert.Equal(t, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), start.UTC())
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), end.UTC(), "Months roll over into the next year")
+
+	start, _, _ = archiveMonthRange("2024", "7", lisbon)
+	assert.Equal(t, time.Date(2024, 6, 30, 23, 0, 0, 0, time.UTC), start.UTC(), "Month boundaries follow summer time")
+
+	for _, tt := range [][2]string{{"2024", "13"}, {"2024", "0"}, {"abc", "1"}, {"0", "1"}, {"2024", "jan"}} {
+		_, _, invalid = archiveMonthRange(tt[0], tt[1], time.UTC)
+		assert.NotEmpty(t, invalid, tt)
+	}
+}
+
+func TestGroupArchive(t *testing.T) {
+	row := func(year, month, count int) archiveRow {
+		var r archiveRow
+		r.ID.Year, r.ID.Month, r.Count = year, month, count
+		return r
+	}
+
+	years, total := groupArchive([]archiveRow{row(2025, 2, 1), row(2024, 11, 3), row(2024, 3, 2)})
+	assert.Equal(t, 6, total)
+	assert.Equal(t, []archiveYear{
+		{Year: 2025, Count: 1, Months: []archiveMonth{{Month: 2, Count: 1}}},
+		{Year: 2024, Count: 5, Months: []archiveMonth{{Month: 11, Count: 3}, {Month: 3, Count: 2}}},
+	}, years)
+
+	years, total = groupArchive(nil)
+	assert.Equal(t, 0, total)
+	assert.Equal(t, []archiveYear{}, years)
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/audit"
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/middleware"
+	"dbl-blog-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetAuditLog retrieves audit log entries, newest first, with filtering and pagination
+func GetAuditLog(c *gin.Context) {
+	log.Printf("[INFO] GetAuditLog: Received request from %s", c.ClientIP())
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	// Build filter from exact-match query parameters
+	filter := bson.M{}
+	for param, field := range map[string]string{
+		"action":     "action",
+		"actor":      "actor_key_id",
+		"request_id": "request_id",
+		"client_ip":  "client_ip",
+	} {
+		if value := c.Query(param); value != "" {
+			filter[field] = value
+		}
+	}
+
+	if postID := c.Query("post_id"); postID != "" {
+		objectID, err := primitive.ObjectIDFromHex(postID)
+		if err != nil {
+			apierrors.RespondInvalidPostID(c)
+			return
+		}
+		filter["target_post_id"] = objectID
+	}
+
+	if mediaID := c.Query("media_id"); mediaID != "" {
+		objectID, err := primitive.ObjectIDFromHex(mediaID)
+		if err != nil {
+			apierrors.RespondInvalidMediaID(c)
+			return
+		}
+		filter["target_media_id"] = objectID
+	}
+
+	createdAt := bson.M{}
+	for param, operator := range map[string]string{"since": "$gte", "until": "$lte"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			apierrors.RespondWithValidationError(c, "'"+param+"' must be an RFC 3339 timestamp")
+			return
+		}
+		createdAt[operator] = parsed
+	}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+
+	collection := database.Database.Collection(audit.CollectionName)
+
+	total, err := collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		log.Printf("[ERROR] GetAuditLog: Failed to count entries - %s", err.Error())
+		apierrors.RespondFailedToFetchAuditLog(c)
+		return
+	}
+
+	findOptions := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		log.Printf("[ERROR] GetAuditLog: Failed to find entries - %s", err.Error())
+		apierrors.RespondFailedToFetchAuditLog(c)
+		return
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	entries := []models.AuditEntry{}
+	if err = cursor.All(context.Background(), &entries); err != nil {
+		log.Printf("[ERROR] GetAuditLog: Failed to decode entries - %s", err.Error())
+		apierrors.RespondFailedToFetchAuditLog(c)
+		return
+	}
+
+	log.Printf("[SUCCESS] GetAuditLog: Retrieved %d entries (page %d, limit %d, total %d)", len(entries), page, limit, total)
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"page":    page,
+		"limit":   limit,
+		"total":   total,
+	})
+}
+
+// recordPostAudit appends a post action with its field-level diff to the audit log
+func recordPostAudit(c *gin.Context, action string, postID primitive.ObjectID, before, after *models.Post) {
+	entry := middleware.NewAuditEntry(c, action)
+	entry.TargetPostID = &postID
+	entry.Changes = audit.Diff(before, after)
+	audit.Record(entry)
+}
+
+// recordMediaAudit appends a media upload (before is nil) or deletion (after is nil) to the audit log
+func recordMediaAudit(c *gin.Context, action string, mediaID primitive.ObjectID, before, after *models.Media) {
+	entry := middleware.NewAuditEntry(c, action)
+	entry.TargetMediaID = &mediaID
+	entry.Changes = audit.Diff(before, after)
+	audit.Record(entry)
+}
+
+// recordOwnershipFailure appends a denied attempt to modify a post of another author to the audit log
+func recordOwnershipFailure(c *gin.Context, postID primitive.ObjectID) {
+	entry := middleware.NewAuditEntry(c, audit.ActionAuthFailure)
+	entry.Reason = "not an author of the post"
+	if !postID.IsZero() {
+		entry.TargetPostID = &postID
+	}
+	audit.Record(entry)
+}
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/middleware"
+	"dbl-blog-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreateAuthor creates a new author profile
+func CreateAuthor(c *gin.Context) {
+	log.Printf("[INFO] CreateAuthor: Received request from %s", c.ClientIP())
+
+	var author models.Author
+	if err := c.ShouldBindJSON(&author); err != nil {
+		log.Printf("[ERROR] CreateAuthor: Validation failed - %s", err.Error())
+		apierrors.RespondWithBindingError(c, err)
+		return
+	}
+
+	now := time.Now()
+	author.CreatedAt = now
+	author.UpdatedAt = now
+
+	collection := database.Database.Collection("authors")
+	result, err := collection.InsertOne(context.Background(), author)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			log.Printf("[ERROR] CreateAuthor: Duplicate key error for slug '%s'", author.Slug)
+			apierrors.RespondAuthorAlreadyExists(c)
+			return
+		}
+		log.Printf("[ERROR] CreateAuthor: Failed to insert author - %s", err.Error())
+		apierrors.RespondFailedToCreateAuthor(c)
+		return
+	}
+
+	author.ID = result.InsertedID.(primitive.ObjectID)
+	log.Printf("[SUCCESS] CreateAuthor: Created author with ID %s, name: '%s'", author.ID.Hex(), author.Name)
+	c.JSON(http.StatusCreated, author)
+}
+
+// GetAuthors retrieves all author profiles
+func GetAuthors(c *gin.Context) {
+	log.Printf("[INFO] GetAuthors: Received request from %s", c.ClientIP())
+
+	collection := database.Database.Collection("authors")
+	findOptions := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := collection.Find(context.Background(), bson.M{}, findOptions)
+	if err != nil {
+		log.Printf("[ERROR] GetAuthors: Failed to find authors - %s", err.Error())
+		apierrors.RespondFailedToFetchAuthors(c)
+		return
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	authors := []models.Author{}
+	if err = cursor.All(context.Background(), &authors); err != nil {
+		log.Printf("[ERROR] GetAuthors: Failed to decode authors - %s", err.Error())
+		apierrors.RespondFailedToFetchAuthors(c)
+		return
+	}
+
+	// API key IDs are internal and never exposed publicly
+	for i := range authors {
+		authors[i].APIKeyIDs = nil
+	}
+
+	log.Printf("[SUCCESS] GetAuthors: Retrieved %d authors", len(authors))
+	c.JSON(http.StatusOK, gin.H{
+		"authors": authors,
+		"total":   len(authors),
+	})
+}
+
+// GetAuthor retrieves a single author profile by slug
+func GetAuthor(c *gin.Context) {
+	slug := c.Param("slug")
+	log.Printf("[INFO] GetAuthor: Received request for slug '%s' from %s", slug, c.ClientIP())
+
+	author, ok := findAuthorBySlug(c, "GetAuthor", slug)
+	if !ok {
+		return
+	}
+
+	author.APIKeyIDs = nil
+	log.Printf("[SUCCESS] GetAuthor: Retrieved author '%s' (ID: %s)", author.Name, author.ID.Hex())
+	c.JSON(http.StatusOK, author)
+}
+
+// GetAuthorPosts retrieves the published posts of an author with pagination
+func GetAuthorPosts(c *gin.Context) {
+	slug := c.Param("slug")
+	log.Printf("[INFO] GetAuthorPosts: Received request for slug '%s' from %s", slug, c.ClientIP())
+
+	author, ok := findAuthorBySlug(c, "GetAuthorPosts", slug)
+	if !ok {
+		return
+	}
+
+	respondWithPostPage(c, "GetAuthorPosts", bson.M{
+		"author_ids": author.ID,
+		"published":  true,
+	})
+}
+
+// UpdateAuthor updates an existing author profile
+func UpdateAuthor(c *gin.Context) {
+	id := c.Param("id")
+	log.Printf("[INFO] UpdateAuthor: Received request for author ID '%s' from %s", id, c.ClientIP())
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		apierrors.RespondInvalidAuthorID(c)
+		return
+	}
+
+	var updates models.Author
+	if err := c.ShouldBindJSON(&updates); err != nil {
+		log.Printf("[ERROR] UpdateAuthor: Validation failed for author ID '%s' - %s", id, err.Error())
+		apierrors.RespondWithBindingError(c, err)
+		return
+	}
+
+	setFields := bson.M{
+		"name":         updates.Name,
+		"slug":         updates.Slug,
+		"bio":          updates.Bio,
+		"avatar_url":   updates.AvatarURL,
+		"social_links": updates.SocialLinks,
+		"updated_at":   time.Now(),
+	}
+	updateDoc := bson.M{"$set": setFields}
+	// Authors without API keys have no api_key_ids, as CreateAuthor leaves them
+	if len(updates.APIKeyIDs) > 0 {
+		setFields["api_key_ids"] = updates.APIKeyIDs
+	} else {
+		updateDoc["$unset"] = bson.M{"api_key_ids": ""}
+	}
+
+	collection := database.Database.Collection("authors")
+	var updatedAuthor models.Author
+	err = collection.FindOneAndUpdate(
+		context.Background(),
+		bson.M{"_id": objectID},
+		updateDoc,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updatedAuthor)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Printf("[ERROR] UpdateAuthor: Author not found for ID '%s'", id)
+			apierrors.RespondAuthorNotFound(c)
+			return
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			log.Printf("[ERROR] UpdateAuthor: Duplicate key error for author ID '%s'", id)
+			apierrors.RespondAuthorAlreadyExists(c)
+			return
+		}
+		log.Printf("[ERROR] UpdateAuthor: Failed to update author ID '%s' - %s", id, err.Error())
+		apierrors.RespondFailedToUpdateAuthor(c)
+		return
+	}
+
+	log.Printf("[SUCCESS] UpdateAuthor: Updated author ID '%s', name: '%s'", id, updatedAuthor.Name)
+	c.JSON(http.StatusOK, updatedAuthor)
+}
+
+// DeleteAuthor deletes an author profile and detaches it from its posts
+func DeleteAuthor(c *gin.Context) {
+	id := c.Param("id")
+	log.Printf("[INFO] DeleteAuthor: Received request to delete author ID '%s' from %s", id, c.ClientIP())
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		log.Printf("[ERROR] DeleteAuthor: Invalid author ID format '%s'", id)
+		apierrors.RespondInvalidAuthorID(c)
+		return
+	}
+
+	collection := database.Database.Collection("authors")
+	result, err := collection.DeleteOne(context.Background(), bson.M{"_id": objectID})
+	if err != nil {
+		log.Printf("[ERROR] DeleteAuthor: Failed to delete author ID '%s' - %s", id, err.Error())
+		apierrors.RespondFailedToDeleteAuthor(c)
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		log.Printf("[ERROR] DeleteAuthor: Author not found for ID '%s'", id)
+		apierrors.RespondAuthorNotFound(c)
+		return
+	}
+
+	// Remove the author from any posts that still reference it
+	_, err = database.Database.Collection("posts").UpdateMany(
+		context.Background(),
+		bson.M{"author_ids": objectID},
+		bson.M{"$pull": bson.M{"author_ids": objectID}},
+	)
+	if err != nil {
+		log.Printf("[ERROR] DeleteAuthor: Failed to detach author ID '%s' from posts - %s", id, err.Error())
+	}
+
+	log.Printf("[SUCCESS] DeleteAuthor: Successfully deleted author ID '%s'", id)
+	c.JSON(http.StatusOK, gin.H{"message": "Author deleted successfully"})
+}
+
+// findAuthorBySlug looks up an author by slug, responding with an error if it can't be found
+func findAuthorBySlug(c *gin.Context, handler, slug string) (models.Author, bool) {
+	var author models.Author
+	collection := database.Database.Collection("authors")
+	err := collection.FindOne(context.Background(), bson.M{"slug": slug}).Decode(&author)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Printf("[ERROR] %s: Author not found for slug '%s'", handler, slug)
+			apierrors.RespondAuthorNotFound(c)
+			return author, false
+		}
+		log.Printf("[ERROR] %s: Failed to fetch author for slug '%s' - %s", handler, slug, err.Error())
+		apierrors.RespondFailedToFetchAuthor(c)
+		return author, false
+	}
+	return author, true
+}
+
+// authorForRequest returns the author mapped to the API key that authenticated the request
+func authorForRequest(c *gin.Context) (models.Author, bool) {
+	var author models.Author
+
+	keyID := c.GetString(middleware.ContextAPIKeyID)
+	if keyID == "" {
+		return author, false
+	}
+
+	collection := database.Database.Collection("authors")
+	err := collection.FindOne(context.Background(), bson.M{"api_key_ids": keyID}).Decode(&author)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Printf("[ERROR] authorForRequest: Failed to fetch author for key ID '%s' - %s", keyID, err.Error())
+		}
+		return author, false
+	}
+	return author, true
+}
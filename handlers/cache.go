@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"dbl-blog-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// postETag returns a strong ETag exposing the post's version, qualified by a digest
+// of its ID, last update time, counters, series block and translations
+func postETag(post models.Post) string {
+	sum := sha256.Sum256([]byte(post.ID.Hex() + "|" + post.UpdatedAt.UTC().Format(time.RFC3339Nano) + countersETagPart(post) +
+		seriesETagPart(post.Series) + translationsETagPart(post.Translations)))
+	return fmt.Sprintf(`"%d-%s"`, post.Version, hex.EncodeToString(sum[:12]))
+}
+
+// countersETagPart covers the views and likes of a post, which change without
+// updating it
+func countersETagPart(post models.Post) string {
+	return fmt.Sprintf("|%d/%d", post.Views, post.Likes)
+}
+
+// postLastModified returns when a post or its counters last changed
+func postLastModified(post models.Post) time.Time {
+	if post.CountedAt.After(post.UpdatedAt) {
+		return post.CountedAt
+	}
+	return post.UpdatedAt
+}
+
+// versionFromETag extracts the post version from a strong ETag produced by postETag
+func versionFromETag(etag string) (int64, bool) {
+	if len(etag) < 2 || etag[0] != '"' || etag[len(etag)-1] != '"' {
+		return 0, false
+	}
+	versionPart, _, found := strings.Cut(etag[1:len(etag)-1], "-")
+	if !found {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(versionPart, 10, 64)
+	if err != nil || version < 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// postsETag returns a strong ETag for a page of posts, derived from each post's
+// ID, last update time, counters, translations and expanded data and from the
+// total so that additions and deletions elsewhere in the result set change it too
+func postsETag(posts []models.Post, total int64) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d", total)
+	for _, post := range posts {
+		b.WriteString("|" + post.ID.Hex() + "@" + post.UpdatedAt.UTC().Format(time.RFC3339Nano) + countersETagPart(post) +
+			translationsETagPart(post.Translations) + seriesETagPart(post.Series))
+		for _, author := range post.Authors {
+			b.WriteString("|" + author.ID.Hex() + "@" + author.UpdatedAt.UTC().Format(time.RFC3339Nano))
+		}
+	}
+	return hashETag(b.String())
+}
+
+// hashETag formats the digest of value as a quoted strong ETag
+func hashETag(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// respondNotModified sets the ETag and Last-Modified validators on the response and
+// evaluates the request's conditional headers. It writes a 304 and returns true when
+// the client's cached copy is still current. Lists pass a zero lastModified: no
+// update time reflects posts leaving them, so they are only validated by ETag.
+func respondNotModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	c.Header("ETag", etag)
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	// If-None-Match takes precedence over If-Modified-Since (RFC 9110 13.2.2)
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		if etagListMatches(ifNoneMatch, etag) {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+		return false
+	}
+
+	if ifModifiedSince := c.GetHeader("If-Modified-Since"); ifModifiedSince != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ifModifiedSince)
+		if err == nil && !lastModified.Truncate(time.Second).After(since) {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+// etagListMatches reports whether a comma-separated list of entity tags (or "*")
+// matches etag using weak comparison, as required for If-None-Match
+func etagListMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
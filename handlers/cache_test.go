@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"dbl-blog-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Unit tests for HTTP caching helpers
+
+func TestPostETag_ChangesWithUpdate(t *testing.T) {
+	post := models.Post{ID: primitive.NewObjectID(), UpdatedAt: time.Now()}
+	etag := postETag(post)
+
+	assert.Equal(t, etag, postETag(post), "ETag should be stable")
+	assert.Regexp(t, `^"0-[0-9a-f]{24}"$`, etag, "ETag should be a quoted strong validator")
+
+	post.UpdatedAt = post.UpdatedAt.Add(time.Millisecond)
+	assert.NotEqual(t, etag, postETag(post), "ETag should change when the post is updated")
+}
+
+func TestPostETag_ChangesWithCounters(t *testing.T) {
+	post := models.Post{ID: primitive.NewObjectID(), UpdatedAt: time.Now(), Version: 2}
+	etag := postETag(post)
+	posts := postsETag([]models.Post{post}, 1)
+
+	post.Likes++
+	assert.NotEqual(t, etag, postETag(post), "ETag should change when the post is liked")
+	assert.NotEqual(t, posts, postsETag([]models.Post{post}, 1), "List ETag should change when a post is liked")
+	assert.Regexp(t, `^"2-`, postETag(post), "Likes should not change the version")
+
+	liked := postETag(post)
+	post.Views++
+	assert.NotEqual(t, liked, postETag(post), "ETag should change when the post is viewed")
+}
+
+func TestPostLastModified(t *testing.T) {
+	updatedAt := time.Now().Add(-time.Hour)
+	post := models.Post{UpdatedAt: updatedAt}
+	assert.Equal(t, updatedAt, postLastModified(post))
+
+	post.CountedAt = updatedAt.Add(time.Minute)
+	assert.Equal(t, post.CountedAt, postLastModified(post), "Likes and views count as modifications")
+}
+
+func TestVersionFromETag(t *testing.T) {
+	post := models.Post{ID: primitive.NewObjectID(), Version: 42, UpdatedAt: time.Now()}
+
+	version, ok := versionFromETag(postETag(post))
+	assert.True(t, ok)
+	assert.Equal(t, int64(42), version)
+
+	for _, invalid := range []string{"", "42", `W/"42-abc"`, `"abc"`, `"x-abc"`, `"-1-abc"`} {
+		_, ok := versionFromETag(invalid)
+		assert.False(t, ok, "ETag %q should be rejected", invalid)
+	}
+}
+
+func TestPostsETag_ChangesWithTotal(t *testing.T) {
+	posts := []models.Post{{ID: primitive.NewObjectID(), UpdatedAt: time.Now()}}
+	assert.NotEqual(t, postsETag(posts, 1), postsETag(posts, 2))
+}
+
+func TestRespondNotModified(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	etag := `"abc123"`
+	lastModified := time.Date(2024, 1, 2, 15, 4, 5, 500, time.UTC)
+
+	testCases := []struct {
+		name     string
+		headers  map[string]string
+		expected bool
+	}{
+		{"no conditional headers", nil, false},
+		{"matching If-None-Match", map[string]string{"If-None-Match": etag}, true},
+		{"weak matching If-None-Match", map[string]string{"If-None-Match": `W/"abc123"`}, true},
+		{"If-None-Match list", map[string]string{"If-None-Match": `"other", "abc123"`}, true},
+		{"If-None-Match wildcard", map[string]string{"If-None-Match": "*"}, true},
+		{"stale If-None-Match", map[string]string{"If-None-Match": `"other"`}, false},
+		{"If-Modified-Since at last modification", map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)}, true},
+		{"If-Modified-Since before last modification", map[string]string{"If-Modified-Since": lastModified.Add(-time.Minute).Format(http.TimeFormat)}, false},
+		{"If-None-Match takes precedence", map[string]string{
+			"If-None-Match":     `"other"`,
+			"If-Modified-Since": lastModified.Format(http.TimeFormat),
+		}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/posts/example", nil)
+			for key, value := range tc.headers {
+				c.Request.Header.Set(key, value)
+			}
+
+			notModified := respondNotModified(c, etag, lastModified)
+			c.Writer.WriteHeaderNow()
+
+			assert.Equal(t, tc.expected, notModified)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			assert.Equal(t, lastModified.Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+			if tc.expected {
+				assert.Equal(t, http.StatusNotModified, w.Code)
+			}
+		})
+	}
+}
+
+func TestRespondNotModified_ListsIgnoreIfModifiedSince(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/posts", nil)
+	c.Request.Header.Set("If-Modified-Since", time.Now().Format(http.TimeFormat))
+
+	assert.False(t, respondNotModified(c, `"abc123"`, time.Time{}))
+	assert.Empty(t, w.Header().Get("Last-Modified"))
+}
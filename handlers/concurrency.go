@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// postUpdateRequest is the body of UpdatePost. Version shadows models.Post.Version
+// so that an omitted version can be told apart from version 0.
+type postUpdateRequest struct {
+	models.Post
+	Version *int64 `json:"version"`
+}
+
+// expectedPostVersion determines the post version an update is based on, from the
+// If-Match header or else the request body. It responds with an error and returns
+// false when neither is usable. A nil version means the client sent If-Match: *
+// and accepts any current version.
+func expectedPostVersion(c *gin.Context, handler string, bodyVersion *int64) (*int64, bool) {
+	if ifMatch := strings.TrimSpace(c.GetHeader("If-Match")); ifMatch != "" {
+		if ifMatch == "*" {
+			return nil, true
+		}
+		version, ok := versionFromETag(ifMatch)
+		if !ok {
+			log.Printf("[ERROR] %s: Unrecognized If-Match header '%s'", handler, ifMatch)
+			apierrors.RespondPreconditionFailed(c)
+			return nil, false
+		}
+		return &version, true
+	}
+
+	if bodyVersion != nil {
+		return bodyVersion, true
+	}
+
+	log.Printf("[ERROR] %s: Missing If-Match header and version from %s", handler, c.ClientIP())
+	apierrors.RespondPreconditionRequired(c)
+	return nil, false
+}
+
+// versionFilter matches documents at the given version. Posts created before
+// versioning have no version field and are treated as version 0.
+func versionFilter(version int64) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return version
+}
+
+// respondVersionMismatchOrNotFound responds to a versioned write that matched no
+// document, telling a missing post apart from a stale version
+func respondVersionMismatchOrNotFound(c *gin.Context, handler string, postID primitive.ObjectID) {
+	var current models.Post
+	err := database.Database.Collection("posts").FindOne(context.Background(), bson.M{"_id": postID}).Decode(&current)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Printf("[ERROR] %s: Post not found for ID '%s'", handler, postID.Hex())
+			apierrors.RespondPostNotFound(c)
+			return
+		}
+		log.Printf("[ERROR] %s: Failed to fetch post ID '%s' - %s", handler, postID.Hex(), err.Error())
+		apierrors.RespondFailedToFetchPost(c)
+		return
+	}
+
+	log.Printf("[ERROR] %s: Stale version for post ID '%s' (current version %d)", handler, postID.Hex(), current.Version)
+	c.Header("ETag", postETag(current))
+	apierrors.RespondPreconditionFailed(c)
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"io/fs"
+	"log"
+	"net/http"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetOpenAPISpec serves the OpenAPI document of the API
+func GetOpenAPISpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openapi.JSON())
+}
+
+// GetDocs serves the documentation page, which renders the OpenAPI document
+func GetDocs(c *gin.Context) {
+	page, err := fs.ReadFile(openapi.Docs(), "index.html")
+	if err != nil {
+		log.Printf("[ERROR] GetDocs: Failed to read the documentation page - %s", err.Error())
+		apierrors.RespondWithCustomError(c, http.StatusInternalServerError, apierrors.CodeInternalError, "Failed to load documentation", "")
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/markdown"
+	"dbl-blog-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Supported export formats
+const (
+	exportFormatNDJSON = "ndjson"
+	exportFormatTarGz  = "tar.gz"
+	exportFormatZip    = "zip"
+)
+
+// exportBatchSize is the number of posts fetched per round trip while exporting
+const exportBatchSize = 100
+
+// postExporter writes posts to an export stream one at a time
+type postExporter interface {
+	Write(post models.Post) error
+	Close() error
+}
+
+// ExportPosts streams all posts matching the optional since, until (RFC 3339, on
+// created_at) and tag filters as NDJSON, or as a tar.gz or zip archive of Markdown
+// files with YAML front matter. Posts are written as they are read from the database.
+func ExportPosts(c *gin.Context) {
+	format := c.DefaultQuery("format", exportFormatNDJSON)
+	log.Printf("[INFO] ExportPosts: Received request for format '%s' from %s", format, c.ClientIP())
+
+	var contentType string
+	switch format {
+	case exportFormatNDJSON:
+		contentType = "application/x-ndjson"
+	case exportFormatTarGz:
+		contentType = "application/gzip"
+	case exportFormatZip:
+		contentType = "application/zip"
+	default:
+		apierrors.RespondWithValidationError(c, "'format' must be one of ndjson, tar.gz or zip")
+		return
+	}
+
+	filter := bson.M{}
+	createdAt := bson.M{}
+	for param, operator := range map[string]string{"since": "$gte", "until": "$lte"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			apierrors.RespondWithValidationError(c, "'"+param+"' must be an RFC 3339 timestamp")
+			return
+		}
+		createdAt[operator] = parsed
+	}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+	if tag := c.Query("tag"); tag != "" {
+		filter["tags"] = tag
+	}
+
+	collection := database.Database.Collection("posts")
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetBatchSize(exportBatchSize)
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		log.Printf("[ERROR] ExportPosts: Failed to find posts - %s", err.Error())
+		apierrors.RespondFailedToFetchPosts(c)
+		return
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	filename := fmt.Sprintf("posts-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	var exporter postExporter
+	switch format {
+	case exportFormatNDJSON:
+		exporter = newNDJSONExporter(c.Writer)
+	case exportFormatTarGz:
+		exporter = newTarGzExporter(c.Writer)
+	default:
+		exporter = newZipExporter(c.Writer)
+	}
+
+	// The status is already sent, so failures past this point can only cut the
+	// stream short; archives are left without their trailer and fail to open
+	count := 0
+	for cursor.Next(context.Background()) {
+		var post models.Post
+		if err := cursor.Decode(&post); err != nil {
+			log.Printf("[ERROR] ExportPosts: Failed to decode post after %d posts - %s", count, err.Error())
+			return
+		}
+		if err := exporter.Write(post); err != nil {
+			log.Printf("[ERROR] ExportPosts: Failed to write post ID '%s' - %s", post.ID.Hex(), err.Error())
+			return
+		}
+		count++
+		if count%exportBatchSize == 0 {
+			c.Writer.Flush()
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		log.Printf("[ERROR] ExportPosts: Cursor failed after %d posts - %s", count, err.Error())
+		return
+	}
+	if err := exporter.Close(); err != nil {
+		log.Printf("[ERROR] ExportPosts: Failed to finish export - %s", err.Error())
+		return
+	}
+
+	log.Printf("[SUCCESS] ExportPosts: Exported %d posts as %s", count, format)
+}
+
+// ndjsonExporter writes one JSON document per line
+type ndjsonExporter struct {
+	encoder *json.Encoder
+}
+
+func newNDJSONExporter(w io.Writer) *ndjsonExporter {
+	return &ndjsonExporter{encoder: json.NewEncoder(w)}
+}
+
+func (e *ndjsonExporter) Write(post models.Post) error {
+	return e.encoder.Encode(post)
+}
+
+func (e *ndjsonExporter) Close() error {
+	return nil
+}
+
+// tarGzExporter writes a gzip-compressed tar archive of Markdown files
+type tarGzExporter struct {
+	gzip *gzip.Writer
+	tar  *tar.Writer
+}
+
+func newTarGzExporter(w io.Writer) *tarGzExporter {
+	gzipWriter := gzip.NewWriter(w)
+	return &tarGzExporter{gzip: gzipWriter, tar: tar.NewWriter(gzipWriter)}
+}
+
+func (e *tarGzExporter) Write(post models.Post) error {
+	// Tar headers need the size up front, so each post is rendered on its own first
+	var buf bytes.Buffer
+	if err := markdown.Encode(&buf, post); err != nil {
+		return err
+	}
+	header := &tar.Header{
+		Name:    markdown.FileName(post),
+		Mode:    0644,
+		Size:    int64(buf.Len()),
+		ModTime: post.UpdatedAt,
+	}
+	if err := e.tar.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := e.tar.Write(buf.Bytes())
+	return err
+}
+
+func (e *tarGzExporter) Close() error {
+	if err := e.tar.Close(); err != nil {
+		return err
+	}
+	return e.gzip.Close()
+}
+
+// zipExporter writes a zip archive of Markdown files
+type zipExporter struct {
+	zip *zip.Writer
+}
+
+func newZipExporter(w io.Writer) *zipExporter {
+	return &zipExporter{zip: zip.NewWriter(w)}
+}
+
+func (e *zipExporter) Write(post models.Post) error {
+	file, err := e.zip.CreateHeader(&zip.FileHeader{
+		Name:     markdown.FileName(post),
+		Method:   zip.Deflate,
+		Modified: post.UpdatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	return markdown.Encode(file, post)
+}
+
+func (e *zipExporter) Close() error {
+	return e.zip.Close()
+}
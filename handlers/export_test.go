@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"dbl-blog-backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Unit tests for export stream writers
+
+func exportTestPosts() []models.Post {
+	return []models.Post{
+		{Title: "First Post", Content: "First content", Slug: "first-post", UpdatedAt: time.Now()},
+		{Title: "Second Post", Content: "Second content", Slug: "second-post", Tags: []string{"go"}, UpdatedAt: time.Now()},
+	}
+}
+
+func writeExport(t *testing.T, exporter postExporter) {
+	for _, post := range exportTestPosts() {
+		assert.NoError(t, exporter.Write(post))
+	}
+	assert.NoError(t, exporter.Close())
+}
+
+func TestNDJSONExporter(t *testing.T) {
+	var buf bytes.Buffer
+	writeExport(t, newNDJSONExporter(&buf))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+
+	var post models.Post
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &post))
+	assert.Equal(t, "second-post", post.Slug)
+}
+
+func TestTarGzExporter(t *testing.T) {
+	var buf bytes.Buffer
+	writeExport(t, newTarGzExporter(&buf))
+
+	gzipReader, err := gzip.NewReader(&buf)
+	assert.NoError(t, err)
+	tarReader := tar.NewReader(gzipReader)
+
+	var names []string
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		names = append(names, header.Name)
+
+		content, err := io.ReadAll(tarReader)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(content), "---\ntitle: "), "Files should start with front matter")
+	}
+	assert.Equal(t, []string{"posts/first-post.md", "posts/second-post.md"}, names)
+}
+
+func TestZipExporter(t *testing.T) {
+	var buf bytes.Buffer
+	writeExport(t, newZipExporter(&buf))
+
+	zipReader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	if assert.Len(t, zipReader.File, 2) {
+		assert.Equal(t, "posts/second-post.md", zipReader.File[1].Name)
+
+		file, err := zipReader.File[1].Open()
+		assert.NoError(t, err)
+		content, err := io.ReadAll(file)
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), "\n---\n\nSecond content\n")
+	}
+}
@@ -0,0 +1,253 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// selectableFields maps the post fields that the fields parameter of lists may
+// select, by JSON name, to their BSON name. Derived fields have no BSON name.
+var selectableFields = map[string]string{
+	"id":                   "_id",
+	"title":                "title",
+	"content":              "content",
+	"slug":                 "slug",
+	"summary":              "summary",
+	"tags":                 "tags",
+	"author_ids":           "author_ids",
+	"published":            "published",
+	"views":                "views",
+	"likes":                "likes",
+	"version":              "version",
+	"created_at":           "created_at",
+	"updated_at":           "updated_at",
+	"lang":                 "lang",
+	"translation_group_id": "translation_group_id",
+	"word_count":           "word_count",
+	"reading_time_minutes": "reading_time_minutes",
+	"toc":                  "toc",
+	"translations":         "", // Listed from the post's translation group
+}
+
+// expansions lists the related data the expand parameter of lists may embed in each post
+var expansions = map[string]bool{
+	"authors": true, // Profiles of the post's authors
+	"series":  true, // Position of the post within its series
+}
+
+// postListView is the shape of the posts of a list, chosen with the fields and
+// expand query parameters
+type postListView struct {
+	fields map[string]bool // Selected fields; nil selects all fields but the content
+	expand map[string]bool
+}
+
+// parsePostListView parses the comma-separated fields and expand parameters,
+// returning an error message naming the accepted values when one is unknown
+func parsePostListView(fields, expand string) (postListView, string) {
+	var view postListView
+	if strings.TrimSpace(fields) != "" {
+		view.fields = map[string]bool{"id": true} // Posts are always identified
+		for _, field := range strings.Split(fields, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			if _, ok := selectableFields[field]; !ok {
+				return view, "fields must be a comma-separated list of: " + strings.Join(sortedKeys(selectableFields), ", ")
+			}
+			view.fields[field] = true
+		}
+	}
+
+	view.expand = map[string]bool{}
+	if strings.TrimSpace(expand) != "" {
+		for _, name := range strings.Split(expand, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if !expansions[name] {
+				return view, "expand must be a comma-separated list of: " + strings.Join(sortedKeys(expansions), ", ")
+			}
+			view.expand[name] = true
+		}
+	}
+	return view, ""
+}
+
+// selects reports whether the view includes a field
+func (v postListView) selects(field string) bool {
+	if v.fields == nil {
+		return field != "content"
+	}
+	return v.fields[field]
+}
+
+// projection returns the MongoDB projection of the view. Besides the selected
+// fields, it keeps those needed for validators and expansions.
+func (v postListView) projection() bson.M {
+	if v.fields == nil {
+		return bson.M{"content": 0} // Lists carry metadata, not content
+	}
+
+	projection := bson.M{"updated_at": 1} // Needed for the ETag
+	for field := range v.fields {
+		if bsonName := selectableFields[field]; bsonName != "" {
+			projection[bsonName] = 1
+		}
+	}
+	if v.fields["translations"] {
+		projection["translation_group_id"] = 1
+		projection["lang"] = 1
+	}
+	if v.expand["authors"] {
+		projection["author_ids"] = 1
+	}
+	return projection
+}
+
+// key returns a canonical description of the view, distinguishing the ETags of
+// differently shaped responses
+func (v postListView) key() string {
+	if v.fields == nil && len(v.expand) == 0 {
+		return ""
+	}
+	return "fields=" + strings.Join(sortedKeys(v.fields), ",") + ";expand=" + strings.Join(sortedKeys(v.expand), ",")
+}
+
+// render returns the JSON representation of a post restricted to the view's
+// fields and expansions. Posts are rendered as is when all fields are selected.
+func (v postListView) render(post models.Post) (interface{}, error) {
+	if v.fields == nil {
+		return post, nil
+	}
+
+	data, err := json.Marshal(post)
+	if err != nil {
+		return nil, err
+	}
+	var rendered map[string]json.RawMessage
+	if err := json.Unmarshal(data, &rendered); err != nil {
+		return nil, err
+	}
+	for field := range rendered {
+		if !v.fields[field] && !v.expand[field] {
+			delete(rendered, field)
+		}
+	}
+	return rendered, nil
+}
+
+// expandPosts embeds the related data requested by the view in a page of posts
+func expandPosts(posts []models.Post, view postListView) error {
+	if view.expand["authors"] {
+		if err := expandAuthors(posts); err != nil {
+			return err
+		}
+	}
+	if view.expand["series"] {
+		if err := expandSeries(posts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandAuthors embeds the public profiles of the authors of a page of posts,
+// fetched in one query
+func expandAuthors(posts []models.Post) error {
+	var ids []primitive.ObjectID
+	for _, post := range posts {
+		ids = append(ids, post.AuthorIDs...)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	cursor, err := database.Database.Collection("authors").Find(context.Background(), bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return err
+	}
+	var authors []models.Author
+	if err := cursor.All(context.Background(), &authors); err != nil {
+		return err
+	}
+
+	byID := make(map[primitive.ObjectID]models.Author, len(authors))
+	for _, author := range authors {
+		author.APIKeyIDs = nil // Credentials stay private
+		byID[author.ID] = author
+	}
+	for i := range posts {
+		for _, id := range posts[i].AuthorIDs {
+			if author, ok := byID[id]; ok {
+				posts[i].Authors = append(posts[i].Authors, author)
+			}
+		}
+	}
+	return nil
+}
+
+// expandSeries embeds the series block of each post of a page that belongs to a
+// series, fetching the series and all their parts in two queries
+func expandSeries(posts []models.Post) error {
+	ids := make([]primitive.ObjectID, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	cursor, err := database.Database.Collection("series").Find(context.Background(), bson.M{"post_ids": bson.M{"$in": ids}})
+	if err != nil {
+		return err
+	}
+	var seriesList []models.Series
+	if err := cursor.All(context.Background(), &seriesList); err != nil {
+		return err
+	}
+	if len(seriesList) == 0 {
+		return nil
+	}
+
+	// Parts of every series are fetched together
+	var all models.Series
+	seriesOf := make(map[primitive.ObjectID]models.Series)
+	for _, series := range seriesList {
+		all.PostIDs = append(all.PostIDs, series.PostIDs...)
+		for _, id := range series.PostIDs {
+			seriesOf[id] = series
+		}
+	}
+	parts, err := findSeriesPosts(all)
+	if err != nil {
+		return err
+	}
+
+	for i := range posts {
+		if series, ok := seriesOf[posts[i].ID]; ok {
+			posts[i].Series = buildPostSeries(series, parts, posts[i].ID)
+		}
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of a map in alphabetical order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
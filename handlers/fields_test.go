@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"dbl-blog-backend/models"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Unit tests for sparse fieldsets and expansions of post lists
+
+func TestParsePostListView(t *testing.T) {
+	view, invalid := parsePostListView("", "")
+	assert.Empty(t, invalid)
+	assert.Nil(t, view.fields, "All fields are selected by default")
+	assert.True(t, view.selects("translations"))
+	assert.False(t, view.selects("content"), "Lists exclude the content by default")
+	assert.Empty(t, view.key())
+
+	view, invalid = parsePostListView(" title, slug,,translations ", "authors")
+	assert.Empty(t, invalid)
+	assert.Equal(t, map[string]bool{"id": true, "title": true, "slug": true, "translations": true}, view.fields, "The ID is always selected")
+	assert.True(t, view.expand["authors"])
+	assert.Equal(t, "fields=id,slug,title,translations;expand=authors", view.key())
+
+	_, invalid = parsePostListView("title,password", "")
+	assert.Contains(t, invalid, "fields must be a comma-separated list of: author_ids, content")
+
+	_, invalid = parsePostListView("", "comments")
+	assert.Equal(t, "expand must be a comma-separated list of: authors, series", invalid)
+}
+
+func TestPostListViewProjection(t *testing.T) {
+	view, _ := parsePostListView("", "")
+	assert.Equal(t, bson.M{"content": 0}, view.projection())
+
+	view, _ = parsePostListView("title,content,translations", "authors")
+	assert.Equal(t, bson.M{
+		"_id":                  1,
+		"title":                1,
+		"content":              1,
+		"updated_at":           1,
+		"translation_group_id": 1,
+		"lang":                 1,
+		"author_ids":           1,
+	}, view.projection(), "Fields needed for validators, translations and expansions are projected too")
+}
+
+func TestPostListViewRender(t *testing.T) {
+	post := models.Post{
+		ID:        primitive.NewObjectID(),
+		Title:     "Sparse",
+		Summary:   "Only some fields",
+		Views:     42,
+		UpdatedAt: time.Now(),
+		Authors:   []models.Author{{Name: "Ada"}},
+	}
+
+	view, _ := parsePostListView("", "")
+	rendered, err := view.render(post)
+	assert.NoError(t, err)
+	assert.Equal(t, post, rendered, "Posts are rendered as is without fields")
+
+	view, _ = parsePostListView("title", "authors")
+	rendered, err = view.render(post)
+	assert.NoError(t, err)
+
+	data, _ := json.Marshal(rendered)
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &fields))
+	assert.Len(t, fields, 3)
+	assert.Equal(t, post.ID.Hex(), fields["id"])
+	assert.Equal(t, "Sparse", fields["title"])
+	assert.NotNil(t, fields["authors"], "Expanded data is kept")
+}
@@ -0,0 +1,573 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/dispatch"
+	"dbl-blog-backend/gql"
+	"dbl-blog-backend/locale"
+	"dbl-blog-backend/middleware"
+	"dbl-blog-backend/models"
+	"dbl-blog-backend/related"
+
+	"github.com/gin-gonic/gin"
+	graphql "github.com/graph-gophers/graphql-go"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Limits of GraphQL lists, matching those of the REST API
+const (
+	defaultGraphQLLimit = 10
+	maxGraphQLLimit     = 100
+	defaultTagsLimit    = 50
+	maxSearchLength     = 200
+)
+
+// graphQLPersistedQueries keeps the queries sent by clients with their hash
+var graphQLPersistedQueries = gql.NewPersistedQueries(1000)
+
+// GraphQL serves the GraphQL API. Queries read the database directly, batching
+// the lookups of nested fields; mutations are sent to the REST handlers of
+// router, so that they go through the same authentication, rate limiting and
+// validation. Depth and complexity are limited, and queries may be persisted.
+func GraphQL(router *gin.Engine) gin.HandlerFunc {
+	schema := graphql.MustParseSchema(gql.Schema, &graphQLResolver{},
+		graphql.UseStringDescriptions(),
+		graphql.MaxDepth(gql.MaxDepth()),
+		graphql.MaxParallelism(gql.DefaultBatchSize),
+	)
+	maxComplexity := gql.MaxComplexity()
+
+	return func(c *gin.Context) {
+		log.Printf("[INFO] GraphQL: Received %s request from %s", c.Request.Method, c.ClientIP())
+
+		request, err := gql.ParseRequest(c.Request)
+		if err != nil {
+			log.Printf("[ERROR] GraphQL: Invalid request - %s", err.Error())
+			respondGraphQLError(c, graphQLValidationError(err.Error()))
+			return
+		}
+
+		query, err := graphQLPersistedQueries.Resolve(request.Query, request.Extensions.PersistedQuery)
+		if err != nil {
+			log.Printf("[INFO] GraphQL: Persisted query not resolved - %s", err.Error())
+			respondGraphQLError(c, err)
+			return
+		}
+		if strings.TrimSpace(query) == "" {
+			log.Printf("[ERROR] GraphQL: Missing query from %s", c.ClientIP())
+			respondGraphQLError(c, graphQLValidationError("query is required"))
+			return
+		}
+
+		operation, err := gql.Analyze(query, request.OperationName, request.Variables, graphQLListSize)
+		if err != nil {
+			log.Printf("[ERROR] GraphQL: Invalid query - %s", err.Error())
+			respondGraphQLError(c, graphQLValidationError(err.Error()))
+			return
+		}
+		if c.Request.Method == http.MethodGet && !operation.ReadOnly() {
+			log.Printf("[ERROR] GraphQL: %s sent with GET from %s", operation.Type, c.ClientIP())
+			respondGraphQLError(c, &gql.Error{Message: "mutations must be sent with POST", Code: apierrors.CodeBadRequest, Status: http.StatusMethodNotAllowed})
+			return
+		}
+		if operation.Complexity > maxComplexity {
+			log.Printf("[ERROR] GraphQL: Query complexity %d exceeds %d", operation.Complexity, maxComplexity)
+			respondGraphQLError(c, &gql.Error{
+				Message: "query complexity " + strconv.Itoa(operation.Complexity) + " exceeds the maximum of " + strconv.Itoa(maxComplexity),
+				Code:    "QUERY_TOO_COMPLEX",
+				Status:  http.StatusBadRequest,
+			})
+			return
+		}
+
+		dispatcher := dispatch.New(router, c.Request.Header, c.Request.RemoteAddr, c.GetString(middleware.ContextRequestID))
+		ctx := context.WithValue(c.Request.Context(), graphQLRequestKey{}, newGraphQLRequest(dispatcher))
+		response := schema.Exec(ctx, query, request.OperationName, request.Variables)
+
+		if len(response.Errors) > 0 {
+			log.Printf("[ERROR] GraphQL: %s completed with %d errors, first: %s", operation.Type, len(response.Errors), response.Errors[0].Message)
+		} else {
+			log.Printf("[SUCCESS] GraphQL: Executed %s of complexity %d", operation.Type, operation.Complexity)
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// respondGraphQLError responds to a request that couldn't be executed, with the
+// error's status or 200 for errors without one
+func respondGraphQLError(c *gin.Context, err error) {
+	status := http.StatusOK
+	if gqlErr, ok := err.(*gql.Error); ok && gqlErr.Status != 0 {
+		status = gqlErr.Status
+	}
+	c.JSON(status, gql.ErrorResponse(err))
+}
+
+// graphQLListSize bounds the number of items of the list fields of the schema,
+// for the complexity of queries
+func graphQLListSize(field string, args map[string]interface{}) int {
+	bound := func(value, max int) int {
+		if value < 1 {
+			return 1
+		}
+		if value > max {
+			return max
+		}
+		return value
+	}
+
+	switch field {
+	case "posts", "search":
+		return bound(gql.IntArgument(args, "limit", defaultGraphQLLimit), maxGraphQLLimit)
+	case "tags":
+		return bound(gql.IntArgument(args, "limit", defaultTagsLimit), maxGraphQLLimit)
+	case "related":
+		return bound(gql.IntArgument(args, "limit", defaultRelatedLimit), maxRelatedLimit)
+	case "authors":
+		return 10 // As limited by models.Post
+	case "translations":
+		return len(locale.Supported())
+	}
+	return 1
+}
+
+// graphQLValidationError reports invalid arguments, as VALIDATION_FAILED errors do in the REST API
+func graphQLValidationError(message string) *gql.Error {
+	return &gql.Error{Message: message, Code: apierrors.CodeValidationFailed, Status: http.StatusBadRequest}
+}
+
+// graphQLAPIError reports one of the predefined errors of the REST API
+func graphQLAPIError(status int, apiError apierrors.APIError) *gql.Error {
+	return &gql.Error{Message: apiError.Message, Code: apiError.Code, Status: status}
+}
+
+// graphQLRequestKey is the context key of the state of a GraphQL request
+type graphQLRequestKey struct{}
+
+// graphQLRequest holds the loaders of a GraphQL request, which batch and cache
+// the lookups of its resolvers, and its dispatcher of mutations
+type graphQLRequest struct {
+	dispatcher   *dispatch.Dispatcher
+	content      *gql.Loader[primitive.ObjectID, string]
+	posts        *gql.Loader[primitive.ObjectID, models.Post] // Published posts, without their content
+	authors      *gql.Loader[primitive.ObjectID, models.Author]
+	translations *gql.Loader[primitive.ObjectID, []models.Post] // Published posts by translation group
+	series       *gql.Loader[primitive.ObjectID, *models.PostSeries]
+	related      *gql.Loader[primitive.ObjectID, []related.Candidate]
+}
+
+func newGraphQLRequest(dispatcher *dispatch.Dispatcher) *graphQLRequest {
+	return &graphQLRequest{
+		dispatcher: dispatcher,
+
+		content: gql.NewLoader(func(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]string, error) {
+			posts, err := findGraphQLPosts(ctx, bson.M{"_id": bson.M{"$in": ids}}, bson.M{"content": 1})
+			if err != nil {
+				return nil, err
+			}
+			content := make(map[primitive.ObjectID]string, len(posts))
+			for _, post := range posts {
+				content[post.ID] = post.Content
+			}
+			return content, nil
+		}),
+
+		posts: gql.NewLoader(func(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]models.Post, error) {
+			posts, err := findGraphQLPosts(ctx, bson.M{"_id": bson.M{"$in": ids}, "published": true}, bson.M{"content": 0})
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[primitive.ObjectID]models.Post, len(posts))
+			for _, post := range posts {
+				byID[post.ID] = post
+			}
+			return byID, nil
+		}),
+
+		authors: gql.NewLoader(func(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]models.Author, error) {
+			cursor, err := database.Database.Collection("authors").Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+			if err != nil {
+				return nil, err
+			}
+			var authors []models.Author
+			if err := cursor.All(ctx, &authors); err != nil {
+				return nil, err
+			}
+			byID := make(map[primitive.ObjectID]models.Author, len(authors))
+			for _, author := range authors {
+				author.APIKeyIDs = nil // Credentials stay private
+				byID[author.ID] = author
+			}
+			return byID, nil
+		}),
+
+		translations: gql.NewLoader(func(ctx context.Context, groupIDs []primitive.ObjectID) (map[primitive.ObjectID][]models.Post, error) {
+			members, err := findTranslationGroups(groupIDs)
+			if err != nil {
+				return nil, err
+			}
+			groups := make(map[primitive.ObjectID][]models.Post)
+			for _, member := range members {
+				groups[member.TranslationGroupID] = append(groups[member.TranslationGroupID], member)
+			}
+			return groups, nil
+		}),
+
+		series: gql.NewLoader(func(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]*models.PostSeries, error) {
+			posts := make([]models.Post, len(ids))
+			for i, id := range ids {
+				posts[i].ID = id
+			}
+			if err := expandSeries(posts); err != nil {
+				return nil, err
+			}
+			series := make(map[primitive.ObjectID]*models.PostSeries)
+			for _, post := range posts {
+				if post.Series != nil {
+					series[post.ID] = post.Series
+				}
+			}
+			return series, nil
+		}),
+
+		related: gql.NewLoader(related.CandidatesOf),
+	}
+}
+
+// graphQLRequestFrom returns the state of the GraphQL request of ctx
+func graphQLRequestFrom(ctx context.Context) *graphQLRequest {
+	return ctx.Value(graphQLRequestKey{}).(*graphQLRequest)
+}
+
+// findGraphQLPosts fetches the posts matching filter with the given projection
+func findGraphQLPosts(ctx context.Context, filter, projection bson.M, opts ...*options.FindOptions) ([]models.Post, error) {
+	opts = append(opts, options.Find().SetProjection(projection))
+	cursor, err := database.Database.Collection("posts").Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	posts := []models.Post{}
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// graphQLResolver resolves the queries and mutations of the GraphQL schema
+type graphQLResolver struct{}
+
+// Post resolves a post by ID or slug, preferring the requested language
+func (r *graphQLResolver) Post(ctx context.Context, args struct {
+	ID   *graphql.ID
+	Slug *string
+	Lang *string
+}) (*postResolver, error) {
+	var preferences []string
+	if args.Lang != nil && locale.Normalize(*args.Lang) != "" {
+		preferences = []string{locale.Normalize(*args.Lang)}
+	}
+
+	var filters []bson.M
+	switch {
+	case args.ID != nil:
+		objectID, err := primitive.ObjectIDFromHex(string(*args.ID))
+		if err != nil {
+			return nil, graphQLAPIError(http.StatusBadRequest, apierrors.ErrInvalidPostID)
+		}
+		filters = []bson.M{{"_id": objectID}}
+	case args.Slug != nil:
+		// Current slugs take precedence over previous ones
+		filters = []bson.M{{"slug": *args.Slug}, {"slugs": *args.Slug}}
+	default:
+		return nil, graphQLValidationError("post requires an id or a slug")
+	}
+
+	var posts []models.Post
+	for _, filter := range filters {
+		var err error
+		posts, err = findGraphQLPosts(ctx, filter, nil, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+		if err != nil {
+			log.Printf("[ERROR] GraphQL: Failed to fetch post - %s", err.Error())
+			return nil, graphQLAPIError(http.StatusInternalServerError, apierrors.ErrFailedToFetchPost)
+		}
+		if len(posts) > 0 {
+			break
+		}
+	}
+	if len(posts) == 0 {
+		return nil, nil
+	}
+
+	post := preferredPost(posts, preferences)
+	if preferences != nil {
+		var err error
+		if post, err = negotiateTranslation(post, preferences); err != nil {
+			log.Printf("[ERROR] GraphQL: Failed to fetch translations of post ID '%s' - %s", post.ID.Hex(), err.Error())
+			return nil, graphQLAPIError(http.StatusInternalServerError, apierrors.ErrFailedToFetchPost)
+		}
+	}
+	return &postResolver{post: post, withContent: true}, nil
+}
+
+// Posts resolves a page of posts
+func (r *graphQLResolver) Posts(ctx context.Context, args struct {
+	Page      int32
+	Limit     int32
+	Published *bool
+	Author    *string
+	Tag       *string
+	Lang      *string
+	Sort      string
+}) (*postPageResolver, error) {
+	if args.Page < 1 {
+		return nil, graphQLValidationError("page must be at least 1")
+	}
+	if args.Limit < 1 || args.Limit > maxGraphQLLimit {
+		return nil, graphQLValidationError("limit must be between 1 and " + strconv.Itoa(maxGraphQLLimit))
+	}
+	sort, invalid := parsePostSort(args.Sort)
+	if invalid != "" {
+		return nil, graphQLValidationError(invalid)
+	}
+
+	filter := bson.M{}
+	if args.Published != nil {
+		filter["published"] = *args.Published
+	}
+	if args.Tag != nil {
+		filter["tags"] = *args.Tag
+	}
+	if args.Lang != nil && locale.Normalize(*args.Lang) != "" {
+		filter["lang"] = locale.Normalize(*args.Lang)
+	}
+	if args.Author != nil {
+		var author models.Author
+		err := database.Database.Collection("authors").FindOne(ctx, bson.M{"slug": *args.Author}).Decode(&author)
+		if err == mongo.ErrNoDocuments {
+			return nil, graphQLAPIError(http.StatusNotFound, apierrors.ErrAuthorNotFound)
+		}
+		if err != nil {
+			log.Printf("[ERROR] GraphQL: Failed to fetch author '%s' - %s", *args.Author, err.Error())
+			return nil, graphQLAPIError(http.StatusInternalServerError, apierrors.ErrFailedToFetchAuthor)
+		}
+		filter["author_ids"] = author.ID
+	}
+
+	total, err := database.Database.Collection("posts").CountDocuments(ctx, filter)
+	if err != nil {
+		log.Printf("[ERROR] GraphQL: Failed to count posts - %s", err.Error())
+		return nil, graphQLAPIError(http.StatusInternalServerError, apierrors.ErrFailedToCountPosts)
+	}
+
+	posts, err := findGraphQLPosts(ctx, filter, bson.M{"content": 0}, options.Find().
+		SetSort(sort).
+		SetSkip(int64(args.Page-1)*int64(args.Limit)).
+		SetLimit(int64(args.Limit)))
+	if err != nil {
+		log.Printf("[ERROR] GraphQL: Failed to fetch posts - %s", err.Error())
+		return nil, graphQLAPIError(http.StatusInternalServerError, apierrors.ErrFailedToFetchPosts)
+	}
+
+	return &postPageResolver{posts: posts, page: args.Page, limit: args.Limit, total: total}, nil
+}
+
+// Search resolves the published posts matching a full-text search
+func (r *graphQLResolver) Search(ctx context.Context, args struct {
+	Query string
+	Lang  *string
+	Limit int32
+}) ([]*postResolver, error) {
+	query := strings.TrimSpace(args.Query)
+	if query == "" || len(query) > maxSearchLength {
+		return nil, graphQLValidationError("query must be between 1 and " + strconv.Itoa(maxSearchLength) + " characters")
+	}
+	if args.Limit < 1 || args.Limit > maxGraphQLLimit {
+		return nil, graphQLValidationError("limit must be between 1 and " + strconv.Itoa(maxGraphQLLimit))
+	}
+
+	filter := bson.M{"$text": bson.M{"$search": query}, "published": true}
+	if args.Lang != nil && locale.Normalize(*args.Lang) != "" {
+		filter["lang"] = locale.Normalize(*args.Lang)
+	}
+	score := bson.M{"$meta": "textScore"}
+	posts, err := findGraphQLPosts(ctx, filter, bson.M{"content": 0, "score": score}, options.Find().
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(args.Limit)))
+	if err != nil {
+		log.Printf("[ERROR] GraphQL: Failed to search posts for '%s' - %s", query, err.Error())
+		return nil, graphQLAPIError(http.StatusInternalServerError, apierrors.ErrFailedToFetchPosts)
+	}
+	return postResolvers(posts), nil
+}
+
+// Tags resolves the tags of published posts with their number of posts
+func (r *graphQLResolver) Tags(ctx context.Context, args struct {
+	Lang  *string
+	Limit int32
+}) ([]*tagResolver, error) {
+	if args.Limit < 1 || args.Limit > maxGraphQLLimit {
+		return nil, graphQLValidationError("limit must be between 1 and " + strconv.Itoa(maxGraphQLLimit))
+	}
+
+	match := bson.M{"published": true}
+	if args.Lang != nil && locale.Normalize(*args.Lang) != "" {
+		match["lang"] = locale.Normalize(*args.Lang)
+	}
+	cursor, err := database.Database.Collection("posts").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$unwind", Value: "$tags"}},
+		{{Key: "$group", Value: bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: args.Limit}},
+	})
+	if err != nil {
+		log.Printf("[ERROR] GraphQL: Failed to aggregate tags - %s", err.Error())
+		return nil, graphQLAPIError(http.StatusInternalServerError, apierrors.ErrFailedToFetchPosts)
+	}
+	var tags []tagResolver
+	if err := cursor.All(ctx, &tags); err != nil {
+		log.Printf("[ERROR] GraphQL: Failed to decode tags - %s", err.Error())
+		return nil, graphQLAPIError(http.StatusInternalServerError, apierrors.ErrFailedToFetchPosts)
+	}
+
+	resolvers := make([]*tagResolver, len(tags))
+	for i := range tags {
+		resolvers[i] = &tags[i]
+	}
+	return resolvers, nil
+}
+
+// dispatchMutation sends a mutation to the REST API, reporting its errors with
+// their REST code and status
+func dispatchMutation(ctx context.Context, method, path string, body, out interface{}) error {
+	err := graphQLRequestFrom(ctx).dispatcher.Do(ctx, method, path, body, out)
+	var dispatchErr *dispatch.Error
+	if errors.As(err, &dispatchErr) {
+		return &gql.Error{Message: dispatchErr.Message, Code: dispatchErr.Code, Status: dispatchErr.Status}
+	}
+	return err
+}
+
+// postMutationPath returns the REST path of a post, or of one of its actions
+func postMutationPath(id graphql.ID, action string) string {
+	path := "/api/v1/posts/" + url.PathEscape(string(id))
+	if action != "" {
+		path += "/" + action
+	}
+	return path
+}
+
+// LikePost likes a post through LikePost
+func (r *graphQLResolver) LikePost(ctx context.Context, args struct{ ID graphql.ID }) (int32, error) {
+	var result struct {
+		Likes int64 `json:"likes"`
+	}
+	err := dispatchMutation(ctx, http.MethodPut, postMutationPath(args.ID, "like"), nil, &result)
+	return int32(result.Likes), err
+}
+
+// DislikePost withdraws a like from a post through DislikePost
+func (r *graphQLResolver) DislikePost(ctx context.Context, args struct{ ID graphql.ID }) (int32, error) {
+	var result struct {
+		Likes int64 `json:"likes"`
+	}
+	err := dispatchMutation(ctx, http.MethodPut, postMutationPath(args.ID, "dislike"), nil, &result)
+	return int32(result.Likes), err
+}
+
+// ViewPost records a view of a post through ViewPost
+func (r *graphQLResolver) ViewPost(ctx context.Context, args struct{ ID graphql.ID }) (int32, error) {
+	var result struct {
+		Views int64 `json:"views"`
+	}
+	err := dispatchMutation(ctx, http.MethodPut, postMutationPath(args.ID, "view"), nil, &result)
+	return int32(result.Views), err
+}
+
+// postInput is the PostInput of mutations
+type postInput struct {
+	Title              string
+	Content            string
+	Slug               *string
+	Summary            *string
+	Tags               *[]string
+	Published          *bool
+	Lang               *string
+	TranslationGroupID *graphql.ID
+	AuthorIDs          *[]graphql.ID
+}
+
+// body returns the JSON body of the REST request carrying the input
+func (input postInput) body() map[string]interface{} {
+	body := map[string]interface{}{
+		"title":   input.Title,
+		"content": input.Content,
+	}
+	if input.Slug != nil {
+		body["slug"] = *input.Slug
+	}
+	if input.Summary != nil {
+		body["summary"] = *input.Summary
+	}
+	if input.Tags != nil {
+		body["tags"] = *input.Tags
+	}
+	if input.Published != nil {
+		body["published"] = *input.Published
+	}
+	if input.Lang != nil {
+		body["lang"] = *input.Lang
+	}
+	if input.TranslationGroupID != nil {
+		body["translation_group_id"] = *input.TranslationGroupID
+	}
+	if input.AuthorIDs != nil {
+		body["author_ids"] = *input.AuthorIDs
+	}
+	return body
+}
+
+// CreatePost creates a post through CreatePost
+func (r *graphQLResolver) CreatePost(ctx context.Context, args struct{ Input postInput }) (*postResolver, error) {
+	var post models.Post
+	if err := dispatchMutation(ctx, http.MethodPost, "/api/v1/posts", args.Input.body(), &post); err != nil {
+		return nil, err
+	}
+	return &postResolver{post: post, withContent: true}, nil
+}
+
+// UpdatePost replaces a post through UpdatePost
+func (r *graphQLResolver) UpdatePost(ctx context.Context, args struct {
+	ID      graphql.ID
+	Version int32
+	Input   postInput
+}) (*postResolver, error) {
+	body := args.Input.body()
+	body["version"] = args.Version
+
+	var post models.Post
+	if err := dispatchMutation(ctx, http.MethodPut, postMutationPath(args.ID, ""), body, &post); err != nil {
+		return nil, err
+	}
+	return &postResolver{post: post, withContent: true}, nil
+}
+
+// DeletePost deletes a post through DeletePost
+func (r *graphQLResolver) DeletePost(ctx context.Context, args struct{ ID graphql.ID }) (bool, error) {
+	if err := dispatchMutation(ctx, http.MethodDelete, postMutationPath(args.ID, ""), nil, nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
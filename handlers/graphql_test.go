@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"dbl-blog-backend/apierrors"
+
+	"github.com/gin-gonic/gin"
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for the GraphQL endpoint, covering the checks made before execution
+
+func graphQLTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/graphql", GraphQL(router))
+	router.GET("/graphql", GraphQL(router))
+	return router
+}
+
+// graphQLErrorCode performs a request and returns its status and first error code
+func graphQLErrorCode(t *testing.T, router *gin.Engine, req *http.Request) (int, string) {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response struct {
+		Errors []struct {
+			Message    string                 `json:"message"`
+			Extensions map[string]interface{} `json:"extensions"`
+		} `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.NotEmpty(t, response.Errors)
+	code, _ := response.Errors[0].Extensions["code"].(string)
+	return w.Code, code
+}
+
+func postGraphQL(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestGraphQLRejectsInvalidRequests(t *testing.T) {
+	router := graphQLTestRouter()
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		status int
+		code   string
+	}{
+		{"malformed body", postGraphQL(`{"query":`), http.StatusBadRequest, apierrors.CodeValidationFailed},
+		{"missing query", postGraphQL(`{}`), http.StatusBadRequest, apierrors.CodeValidationFailed},
+		{"syntax error", postGraphQL(`{"query":"{ posts {"}`), http.StatusBadRequest, apierrors.CodeValidationFailed},
+		{
+			"mutation over GET",
+			httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`mutation { likePost(id: "1") }`), nil),
+			http.StatusMethodNotAllowed, apierrors.CodeBadRequest,
+		},
+		{
+			"too complex",
+			postGraphQL(`{"query":"{ posts(limit: 100) { items { related(limit: 20) { authors { socialLinks { url } } } } } }"}`),
+			http.StatusBadRequest, "QUERY_TOO_COMPLEX",
+		},
+		{
+			"unknown persisted query",
+			postGraphQL(`{"extensions":{"persistedQuery":{"version":1,"sha256Hash":"` + strings.Repeat("0", 64) + `"}}}`),
+			http.StatusOK, "PERSISTED_QUERY_NOT_FOUND",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, code := graphQLErrorCode(t, router, tt.req)
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.code, code)
+		})
+	}
+}
+
+func TestGraphQLSchemaMatchesResolvers(t *testing.T) {
+	// Binding fails when a field of the schema has no matching resolver method
+	assert.NotPanics(t, func() { GraphQL(gin.New()) })
+}
+
+func TestGraphQLListSize(t *testing.T) {
+	assert.Equal(t, defaultGraphQLLimit, graphQLListSize("posts", nil))
+	assert.Equal(t, 25, graphQLListSize("search", map[string]interface{}{"limit": int64(25)}))
+	assert.Equal(t, maxGraphQLLimit, graphQLListSize("posts", map[string]interface{}{"limit": float64(1000)}))
+	assert.Equal(t, defaultTagsLimit, graphQLListSize("tags", nil))
+	assert.Equal(t, defaultRelatedLimit, graphQLListSize("related", nil))
+	assert.Equal(t, maxRelatedLimit, graphQLListSize("related", map[string]interface{}{"limit": int64(500)}))
+	assert.Equal(t, 1, graphQLListSize("posts", map[string]interface{}{"limit": int64(0)}))
+	assert.Equal(t, 1, graphQLListSize("series", nil))
+}
+
+func TestPostInputBody(t *testing.T) {
+	published := true
+	tags := []string{"go"}
+	group := graphql.ID("64b7f0c2e4b0a1a2b3c4d5e6")
+	input := postInput{Title: "Title", Content: "Content", Published: &published, Tags: &tags, TranslationGroupID: &group}
+
+	assert.Equal(t, map[string]interface{}{
+		"title":                "Title",
+		"content":              "Content",
+		"published":            true,
+		"tags":                 []string{"go"},
+		"translation_group_id": group,
+	}, input.body(), "fields left out of the input are left out of the body")
+}
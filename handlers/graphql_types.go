@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/models"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// graphQLID converts an ObjectID into a GraphQL ID
+func graphQLID(id primitive.ObjectID) graphql.ID {
+	return graphql.ID(id.Hex())
+}
+
+// postResolver resolves a Post. Nested fields are loaded through the loaders of
+// the request, so that a page of posts costs one query per field rather than
+// one per post.
+type postResolver struct {
+	post        models.Post
+	withContent bool // Whether post was fetched with its content
+}
+
+func postResolvers(posts []models.Post) []*postResolver {
+	resolvers := make([]*postResolver, len(posts))
+	for i, post := range posts {
+		resolvers[i] = &postResolver{post: post}
+	}
+	return resolvers
+}
+
+func (r *postResolver) ID() graphql.ID   { return graphQLID(r.post.ID) }
+func (r *postResolver) Title() string    { return r.post.Title }
+func (r *postResolver) Slug() string     { return r.post.Slug }
+func (r *postResolver) Summary() string  { return r.post.Summary }
+func (r *postResolver) Published() bool  { return r.post.Published }
+func (r *postResolver) Views() int32     { return int32(r.post.Views) }
+func (r *postResolver) Likes() int32     { return int32(r.post.Likes) }
+func (r *postResolver) Version() int32   { return int32(r.post.Version) }
+func (r *postResolver) Lang() string     { return r.post.Lang }
+func (r *postResolver) WordCount() int32 { return int32(r.post.WordCount) }
+
+func (r *postResolver) ReadingTimeMinutes() int32 { return int32(r.post.ReadingTimeMinutes) }
+func (r *postResolver) CreatedAt() graphql.Time   { return graphql.Time{Time: r.post.CreatedAt} }
+func (r *postResolver) UpdatedAt() graphql.Time   { return graphql.Time{Time: r.post.UpdatedAt} }
+
+func (r *postResolver) Tags() []string {
+	if r.post.Tags == nil {
+		return []string{}
+	}
+	return r.post.Tags
+}
+
+func (r *postResolver) TranslationGroupID() *graphql.ID {
+	if r.post.TranslationGroupID.IsZero() {
+		return nil
+	}
+	id := graphQLID(r.post.TranslationGroupID)
+	return &id
+}
+
+func (r *postResolver) TOC() []*tocEntryResolver {
+	resolvers := make([]*tocEntryResolver, len(r.post.TOC))
+	for i := range r.post.TOC {
+		resolvers[i] = &tocEntryResolver{entry: r.post.TOC[i]}
+	}
+	return resolvers
+}
+
+// Content is only fetched for posts listed without it
+func (r *postResolver) Content(ctx context.Context) (string, error) {
+	if r.withContent {
+		return r.post.Content, nil
+	}
+	content, _, err := graphQLRequestFrom(ctx).content.Load(ctx, r.post.ID)
+	if err != nil {
+		log.Printf("[ERROR] GraphQL: Failed to fetch content of post ID '%s' - %s", r.post.ID.Hex(), err.Error())
+		return "", graphQLAPIError(http.StatusInternalServerError, apierrors.ErrFailedToFetchPost)
+	}
+	return content, nil
+}
+
+func (r *postResolver) Authors(ctx context.Context) ([]*authorResolver, error) {
+	authors, err := graphQLRequestFrom(ctx).authors.LoadMany(ctx, r.post.AuthorIDs)
+	if err != nil {
+		log.Printf("[ERROR] GraphQL: Failed to fetch authors of post ID '%s' - %s", r.post.ID.Hex(), err.Error())
+		return nil, graphQLAPIError(http.StatusInternalServerError, apierrors.ErrFailedToFetchAuthors)
+	}
+	resolvers := make([]*authorResolver, len(authors))
+	for i := range authors {
+		resolvers[i] = &authorResolver{author: authors[i]}
+	}
+	return resolvers, nil
+}
+
+func (r *postResolver) Translations(ctx context.Context) ([]*translationResolver, error) {
+	resolvers := []*translationResolver{}
+	if r.post.TranslationGroupID.IsZero() {
+		return resolvers, nil
+	}
+	members, _, err := graphQLRequestFrom(ctx).translations.Load(ctx, r.post.TranslationGroupID)
+	if err != nil {
+		log.Printf("[ERROR] GraphQL: Failed to fetch translations of post ID '%s' - %s", r.post.ID.Hex(), err.Error())
+		return nil, graphQLAPIError(http.StatusInternalServerError, apierrors.ErrFailedToFetchPost)
+	}
+	for _, translation := range translationsOf(r.post, members) {
+		resolvers = append(resolvers, &translationResolver{translation: translation})
+	}
+	return resolvers, nil
+}
+
+func (r *postResolver) Series(ctx context.Context) (*postSeriesResolver, error) {
+	series, found, err := graphQLRequestFrom(ctx).series.Load(ctx, r.post.ID)
+	if err != nil {
+		log.Printf("[ERROR] GraphQL: Failed to fetch series of post ID '%s' - %s", r.post.ID.Hex(), err.Error())
+		return nil, graphQLAPIError(http.StatusInternalServerError, apierrors.ErrFailedToFetchSeries)
+	}
+	if !found || series == nil {
+		return nil, nil
+	}
+	return &postSeriesResolver{series: series}, nil
+}
+
+// Related ranks the candidates of the post as GetRelatedPosts does
+func (r *postResolver) Related(ctx context.Context, args struct{ Limit int32 }) ([]*postResolver, error) {
+	if args.Limit < 1 || int(args.Limit) > maxRelatedLimit {
+		return nil, graphQLValidationError("limit must be between 1 and " + strconv.Itoa(maxRelatedLimit))
+	}
+
+	request := graphQLRequestFrom(ctx)
+	candidates, _, err := request.related.Load(ctx, r.post.ID)
+	if err != nil {
+		log.Printf("[ERROR] GraphQL: Failed to fetch related posts of post ID '%s' - %s", r.post.ID.Hex(), err.Error())
+		return nil, graphQLAPIError(http.StatusInternalServerError, apierrors.ErrFailedToFetchRelatedPosts)
+	}
+	ids := make([]primitive.ObjectID, len(candidates))
+	for i, candidate := range candidates {
+		ids[i] = candidate.PostID
+	}
+	posts, err := request.posts.LoadMany(ctx, ids)
+	if err != nil {
+		log.Printf("[ERROR] GraphQL: Failed to fetch related posts of post ID '%s' - %s", r.post.ID.Hex(), err.Error())
+		return nil, graphQLAPIError(http.StatusInternalServerError, apierrors.ErrFailedToFetchRelatedPosts)
+	}
+
+	ranked := rankRelatedPosts(candidates, posts, time.Now(), int(args.Limit))
+	resolvers := make([]*postResolver, len(ranked))
+	for i, post := range ranked {
+		resolvers[i] = &postResolver{post: post.Post}
+	}
+	return resolvers, nil
+}
+
+// postPageResolver resolves a PostPage
+type postPageResolver struct {
+	posts []models.Post
+	page  int32
+	limit int32
+	total int64
+}
+
+func (r *postPageResolver) Items() []*postResolver { return postResolvers(r.posts) }
+func (r *postPageResolver) Page() int32            { return r.page }
+func (r *postPageResolver) Limit() int32           { return r.limit }
+func (r *postPageResolver) Total() int32           { return int32(r.total) }
+
+// tocEntryResolver resolves a TOCEntry
+type tocEntryResolver struct {
+	entry models.TOCEntry
+}
+
+func (r *tocEntryResolver) Level() int32 { return int32(r.entry.Level) }
+func (r *tocEntryResolver) Text() string { return r.entry.Text }
+func (r *tocEntryResolver) ID() string   { return r.entry.ID }
+
+// authorResolver resolves an Author
+type authorResolver struct {
+	author models.Author
+}
+
+func (r *authorResolver) ID() graphql.ID    { return graphQLID(r.author.ID) }
+func (r *authorResolver) Name() string      { return r.author.Name }
+func (r *authorResolver) Slug() string      { return r.author.Slug }
+func (r *authorResolver) Bio() string       { return r.author.Bio }
+func (r *authorResolver) AvatarURL() string { return r.author.AvatarURL }
+
+func (r *authorResolver) SocialLinks() []*socialLinkResolver {
+	resolvers := make([]*socialLinkResolver, len(r.author.SocialLinks))
+	for i := range r.author.SocialLinks {
+		resolvers[i] = &socialLinkResolver{link: r.author.SocialLinks[i]}
+	}
+	return resolvers
+}
+
+// socialLinkResolver resolves a SocialLink
+type socialLinkResolver struct {
+	link models.SocialLink
+}
+
+func (r *socialLinkResolver) Platform() string { return r.link.Platform }
+func (r *socialLinkResolver) URL() string      { return r.link.URL }
+
+// translationResolver resolves a Translation
+type translationResolver struct {
+	translation models.PostTranslation
+}
+
+func (r *translationResolver) ID() graphql.ID { return graphQLID(r.translation.ID) }
+func (r *translationResolver) Lang() string   { return r.translation.Lang }
+func (r *translationResolver) Slug() string   { return r.translation.Slug }
+func (r *translationResolver) Title() string  { return r.translation.Title }
+
+// postSeriesResolver resolves a PostSeries
+type postSeriesResolver struct {
+	series *models.PostSeries
+}
+
+func (r *postSeriesResolver) ID() graphql.ID { return graphQLID(r.series.ID) }
+func (r *postSeriesResolver) Title() string  { return r.series.Title }
+func (r *postSeriesResolver) Slug() string   { return r.series.Slug }
+func (r *postSeriesResolver) Part() int32    { return int32(r.series.Part) }
+func (r *postSeriesResolver) Total() int32   { return int32(r.series.Total) }
+
+func (r *postSeriesResolver) Previous() *seriesPartResolver {
+	if r.series.Previous == nil {
+		return nil
+	}
+	return &seriesPartResolver{part: *r.series.Previous}
+}
+
+func (r *postSeriesResolver) Next() *seriesPartResolver {
+	if r.series.Next == nil {
+		return nil
+	}
+	return &seriesPartResolver{part: *r.series.Next}
+}
+
+// seriesPartResolver resolves a SeriesPart
+type seriesPartResolver struct {
+	part models.SeriesPart
+}
+
+func (r *seriesPartResolver) Part() int32    { return int32(r.part.Part) }
+func (r *seriesPartResolver) ID() graphql.ID { return graphQLID(r.part.ID) }
+func (r *seriesPartResolver) Title() string  { return r.part.Title }
+func (r *seriesPartResolver) Slug() string   { return r.part.Slug }
+
+// tagResolver resolves a Tag, as aggregated by Tags
+type tagResolver struct {
+	TagName  string `bson:"_id"`
+	TagCount int64  `bson:"count"`
+}
+
+func (r *tagResolver) Name() string { return r.TagName }
+func (r *tagResolver) Count() int32 { return int32(r.TagCount) }
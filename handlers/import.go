@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/audit"
+	"dbl-blog-backend/importer"
+	"dbl-blog-backend/models"
+	"dbl-blog-backend/related"
+	"dbl-blog-backend/webhooks"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxImportBodyBytes limits the size of uploaded import archives
+const maxImportBodyBytes = 32 << 20
+
+// ImportPosts upserts posts by slug from a zip, tar or tar.gz archive of Markdown
+// files with YAML or TOML front matter. The archive is sent as the raw request body
+// or as the "file" field of a multipart form. With dry_run=true nothing is written
+// and the report describes what would happen.
+func ImportPosts(c *gin.Context) {
+	log.Printf("[INFO] ImportPosts: Received request from %s", c.ClientIP())
+
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+
+	data, ok := readImportUpload(c, "ImportPosts")
+	if !ok {
+		return
+	}
+
+	files, err := importer.ReadArchive(data)
+	if err != nil {
+		log.Printf("[ERROR] ImportPosts: Failed to read archive - %s", err.Error())
+		apierrors.RespondInvalidImport(c, err.Error())
+		return
+	}
+
+	report := importer.Import(context.Background(), importer.ParseMarkdown(files), importOptions(c, dryRun))
+
+	log.Printf("[SUCCESS] ImportPosts: Processed %d files (dry run: %t) - %d created, %d updated, %d unchanged, %d invalid, %d failed",
+		report.Total, dryRun, report.Created, report.Updated, report.Unchanged, report.Invalid, report.Failed)
+	c.JSON(http.StatusOK, report)
+}
+
+// ImportWordPress upserts posts by slug from a WordPress WXR export, sent as the raw
+// request body or as the "file" field of a multipart form. With dry_run=true nothing
+// is written and the report describes what would happen.
+func ImportWordPress(c *gin.Context) {
+	log.Printf("[INFO] ImportWordPress: Received request from %s", c.ClientIP())
+
+	dryRun, _ := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+
+	data, ok := readImportUpload(c, "ImportWordPress")
+	if !ok {
+		return
+	}
+
+	candidates, report, err := importer.ParseWordPress(bytes.NewReader(data))
+	if err != nil {
+		log.Printf("[ERROR] ImportWordPress: Failed to parse export - %s", err.Error())
+		apierrors.RespondInvalidImport(c, err.Error())
+		return
+	}
+
+	report.Report = importer.Import(context.Background(), candidates, importOptions(c, dryRun))
+
+	log.Printf("[SUCCESS] ImportWordPress: Processed %d posts (dry run: %t) - %d created, %d updated, %d unchanged, %d invalid, %d failed, %d items skipped",
+		report.Total, dryRun, report.Created, report.Updated, report.Unchanged, report.Invalid, report.Failed, report.Skipped)
+	c.JSON(http.StatusOK, report)
+}
+
+// readImportUpload reads an uploaded import file from the raw body or a multipart form
+func readImportUpload(c *gin.Context, handler string) ([]byte, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodyBytes)
+
+	var reader io.Reader = c.Request.Body
+	if mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type")); mediaType == "multipart/form-data" {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			log.Printf("[ERROR] %s: Missing 'file' form field - %s", handler, err.Error())
+			apierrors.RespondInvalidImport(c, "multipart uploads must include a 'file' field")
+			return nil, false
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			log.Printf("[ERROR] %s: Failed to open upload - %s", handler, err.Error())
+			apierrors.RespondInvalidImport(c, err.Error())
+			return nil, false
+		}
+		defer func() { _ = file.Close() }()
+		reader = file
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		log.Printf("[ERROR] %s: Failed to read upload - %s", handler, err.Error())
+		apierrors.RespondInvalidImport(c, err.Error())
+		return nil, false
+	}
+	if len(data) == 0 {
+		apierrors.RespondInvalidImport(c, "request body is empty")
+		return nil, false
+	}
+	return data, true
+}
+
+// importOptions attributes imported posts to the caller's author profile,
+// queues webhook events, records every write in the audit log, publishes it to
+// watchers and schedules a related posts rebuild
+func importOptions(c *gin.Context, dryRun bool) importer.Options {
+	opts := importer.Options{
+		DryRun: dryRun,
+		Events: webhooks.EnqueuePostEvents,
+		OnWrite: func(action string, before, after *models.Post) {
+			if action == importer.ActionCreate {
+				recordPostAudit(c, audit.ActionPostCreate, after.ID, nil, after)
+			} else {
+				recordPostAudit(c, audit.ActionPostUpdate, after.ID, before, after)
+			}
+			publishPostEvents(before, after)
+			related.Schedule()
+		},
+	}
+	if author, ok := authorForRequest(c); ok {
+		opts.AuthorIDs = []primitive.ObjectID{author.ID}
+	}
+	return opts
+}
@@ -0,0 +1,454 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/audit"
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/media"
+	"dbl-blog-backend/middleware"
+	"dbl-blog-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mediaPath is the path media and their variants are served from. Post content
+// references media by these URLs, which stay valid whichever storage backend is used.
+const mediaPath = "/api/v1/media/"
+
+// multipartOverheadBytes allows for multipart boundaries and form fields on top of
+// the upload size limit
+const multipartOverheadBytes = 64 << 10
+
+// Limits of the metadata sent with an upload
+const (
+	maxMediaAltLength      = 500
+	maxMediaFileNameLength = 255
+)
+
+// mediaCacheControl is used when serving media, which never change once uploaded
+const mediaCacheControl = "public, max-age=31536000, immutable"
+
+// mediaObject is a file written to media storage during an upload
+type mediaObject struct {
+	key         string
+	contentType string
+	data        []byte
+}
+
+// UploadMedia stores a file from the "file" field of a multipart form, with an
+// optional "alt" text. The content type is sniffed from the file itself, and
+// resized and WebP variants are generated for images.
+func UploadMedia(c *gin.Context) {
+	log.Printf("[INFO] UploadMedia: Received request from %s", c.ClientIP())
+
+	maxBytes := media.MaxUploadBytes()
+	tooLarge := fmt.Sprintf("uploads are limited to %d bytes", maxBytes)
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+multipartOverheadBytes)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			log.Printf("[ERROR] UploadMedia: Upload exceeds %d bytes", maxBytes)
+			apierrors.RespondUploadTooLarge(c, tooLarge)
+			return
+		}
+		log.Printf("[ERROR] UploadMedia: Missing 'file' form field - %s", err.Error())
+		apierrors.RespondInvalidUpload(c, "uploads must be multipart/form-data with a 'file' field")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Printf("[ERROR] UploadMedia: Failed to open upload - %s", err.Error())
+		apierrors.RespondInvalidUpload(c, err.Error())
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		log.Printf("[ERROR] UploadMedia: Failed to read upload - %s", err.Error())
+		apierrors.RespondInvalidUpload(c, err.Error())
+		return
+	}
+	if int64(len(data)) > maxBytes {
+		log.Printf("[ERROR] UploadMedia: Upload exceeds %d bytes", maxBytes)
+		apierrors.RespondUploadTooLarge(c, tooLarge)
+		return
+	}
+	if len(data) == 0 {
+		apierrors.RespondInvalidUpload(c, "the uploaded file is empty")
+		return
+	}
+
+	contentType, extension, ok := media.Sniff(data)
+	if !ok {
+		log.Printf("[ERROR] UploadMedia: Rejected upload of type '%s'", contentType)
+		apierrors.RespondUnsupportedMediaUpload(c)
+		return
+	}
+
+	alt := strings.TrimSpace(c.PostForm("alt"))
+	if utf8.RuneCountInString(alt) > maxMediaAltLength {
+		apierrors.RespondWithValidationError(c, fmt.Sprintf("'alt' must be at most %d characters", maxMediaAltLength))
+		return
+	}
+
+	item := models.Media{
+		ID:          primitive.NewObjectID(),
+		FileName:    mediaFileName(fileHeader.Filename, extension),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Alt:         alt,
+		UploadedBy:  c.GetString(middleware.ContextAPIKeyID),
+		CreatedAt:   time.Now(),
+	}
+	item.Key = item.ID.Hex() + "/original" + extension
+	objects := []mediaObject{{key: item.Key, contentType: contentType, data: data}}
+
+	if media.IsImage(contentType) {
+		width, height, variants, err := media.Variants(data, contentType)
+		if err != nil {
+			log.Printf("[ERROR] UploadMedia: Failed to process image - %s", err.Error())
+			apierrors.RespondInvalidUpload(c, err.Error())
+			return
+		}
+		item.Width, item.Height = width, height
+		for _, variant := range variants {
+			key := item.ID.Hex() + "/" + variant.Name + media.Extension(variant.ContentType)
+			objects = append(objects, mediaObject{key: key, contentType: variant.ContentType, data: variant.Data})
+			item.Variants = append(item.Variants, models.MediaVariant{
+				Name:        variant.Name,
+				ContentType: variant.ContentType,
+				Size:        int64(len(variant.Data)),
+				Width:       variant.Width,
+				Height:      variant.Height,
+				Key:         key,
+			})
+		}
+	}
+
+	storage, err := media.Default()
+	if err != nil {
+		log.Printf("[ERROR] UploadMedia: Media storage is misconfigured - %s", err.Error())
+		apierrors.RespondFailedToStoreMedia(c)
+		return
+	}
+
+	ctx := context.Background()
+	stored := make([]string, 0, len(objects))
+	for _, object := range objects {
+		if err := storage.Put(ctx, object.key, object.data, object.contentType); err != nil {
+			log.Printf("[ERROR] UploadMedia: Failed to store '%s' - %s", object.key, err.Error())
+			deleteMediaObjects(storage, stored)
+			apierrors.RespondFailedToStoreMedia(c)
+			return
+		}
+		stored = append(stored, object.key)
+	}
+
+	collection := database.Database.Collection("media")
+	if _, err := collection.InsertOne(ctx, item); err != nil {
+		log.Printf("[ERROR] UploadMedia: Failed to insert media - %s", err.Error())
+		deleteMediaObjects(storage, stored)
+		apierrors.RespondFailedToSaveMedia(c)
+		return
+	}
+
+	recordMediaAudit(c, audit.ActionMediaUpload, item.ID, nil, &item)
+
+	setMediaURLs(&item)
+	log.Printf("[SUCCESS] UploadMedia: Stored media ID %s ('%s', %s, %d bytes, %d variants)",
+		item.ID.Hex(), item.FileName, item.ContentType, item.Size, len(item.Variants))
+	c.Header("Location", item.URL)
+	c.JSON(http.StatusCreated, item)
+}
+
+// GetMedia serves an uploaded file, or one of its variants when a variant name is
+// given. When MEDIA_PUBLIC_URL is set, the client is redirected to the file instead.
+func GetMedia(c *gin.Context) {
+	id := c.Param("id")
+	variantName := c.Param("variant")
+	log.Printf("[INFO] GetMedia: Received request for media ID '%s' (variant '%s') from %s", id, variantName, c.ClientIP())
+
+	item, ok := findMedia(c, "GetMedia", id)
+	if !ok {
+		return
+	}
+
+	key, contentType, size := item.Key, item.ContentType, item.Size
+	if variantName != "" {
+		variant, found := mediaVariant(item, variantName)
+		if !found {
+			log.Printf("[ERROR] GetMedia: Variant '%s' not found for media ID '%s'", variantName, id)
+			apierrors.RespondMediaNotFound(c)
+			return
+		}
+		key, contentType, size = variant.Key, variant.ContentType, variant.Size
+	}
+
+	if publicURL := media.PublicURL(key); publicURL != "" {
+		c.Header("Cache-Control", mediaCacheControl)
+		c.Redirect(http.StatusFound, publicURL)
+		return
+	}
+
+	c.Header("Cache-Control", mediaCacheControl)
+	if respondNotModified(c, hashETag(key), item.CreatedAt) {
+		return
+	}
+
+	storage, err := media.Default()
+	if err != nil {
+		log.Printf("[ERROR] GetMedia: Media storage is misconfigured - %s", err.Error())
+		apierrors.RespondFailedToReadMedia(c)
+		return
+	}
+
+	reader, err := storage.Open(context.Background(), key)
+	if err != nil {
+		if err == media.ErrNotFound {
+			log.Printf("[ERROR] GetMedia: Object '%s' missing from media storage", key)
+			apierrors.RespondMediaNotFound(c)
+			return
+		}
+		log.Printf("[ERROR] GetMedia: Failed to open '%s' - %s", key, err.Error())
+		apierrors.RespondFailedToReadMedia(c)
+		return
+	}
+	defer func() { _ = reader.Close() }()
+
+	c.DataFromReader(http.StatusOK, size, contentType, reader, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("inline", map[string]string{"filename": item.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// ListMedia retrieves uploaded media, newest first, with pagination
+func ListMedia(c *gin.Context) {
+	log.Printf("[INFO] ListMedia: Received request from %s", c.ClientIP())
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filter := bson.M{}
+	if contentType := c.Query("content_type"); contentType != "" {
+		filter["content_type"] = contentType
+	}
+
+	collection := database.Database.Collection("media")
+	total, err := collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		log.Printf("[ERROR] ListMedia: Failed to count media - %s", err.Error())
+		apierrors.RespondFailedToFetchMedia(c)
+		return
+	}
+
+	findOptions := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		log.Printf("[ERROR] ListMedia: Failed to find media - %s", err.Error())
+		apierrors.RespondFailedToFetchMedia(c)
+		return
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	items := []models.Media{}
+	if err = cursor.All(context.Background(), &items); err != nil {
+		log.Printf("[ERROR] ListMedia: Failed to decode media - %s", err.Error())
+		apierrors.RespondFailedToFetchMedia(c)
+		return
+	}
+	for i := range items {
+		setMediaURLs(&items[i])
+	}
+
+	log.Printf("[SUCCESS] ListMedia: Retrieved %d media (page %d, limit %d, total %d)", len(items), page, limit, total)
+	c.JSON(http.StatusOK, gin.H{
+		"media": items,
+		"page":  page,
+		"limit": limit,
+		"total": total,
+	})
+}
+
+// DeleteMedia deletes an uploaded file and its variants. Media still referenced
+// from post content are only deleted with force=true.
+func DeleteMedia(c *gin.Context) {
+	id := c.Param("id")
+	log.Printf("[INFO] DeleteMedia: Received request to delete media ID '%s' from %s", id, c.ClientIP())
+
+	item, ok := findMedia(c, "DeleteMedia", id)
+	if !ok {
+		return
+	}
+
+	if force, _ := strconv.ParseBool(c.DefaultQuery("force", "false")); !force {
+		slugs, err := postsReferencingMedia(item.ID)
+		if err != nil {
+			log.Printf("[ERROR] DeleteMedia: Failed to find posts referencing media ID '%s' - %s", id, err.Error())
+			apierrors.RespondFailedToFetchPosts(c)
+			return
+		}
+		if len(slugs) > 0 {
+			log.Printf("[ERROR] DeleteMedia: Media ID '%s' is referenced by %d posts", id, len(slugs))
+			apierrors.RespondMediaInUse(c, "Referenced by posts: "+strings.Join(slugs, ", ")+
+				". Remove the references first, or delete with force=true")
+			return
+		}
+	}
+
+	collection := database.Database.Collection("media")
+	result, err := collection.DeleteOne(context.Background(), bson.M{"_id": item.ID})
+	if err != nil {
+		log.Printf("[ERROR] DeleteMedia: Failed to delete media ID '%s' - %s", id, err.Error())
+		apierrors.RespondFailedToDeleteMedia(c)
+		return
+	}
+	if result.DeletedCount == 0 {
+		apierrors.RespondMediaNotFound(c)
+		return
+	}
+
+	// The metadata is gone, so the media can no longer be served; files left
+	// behind by a storage failure are only logged
+	keys := []string{item.Key}
+	for _, variant := range item.Variants {
+		keys = append(keys, variant.Key)
+	}
+	if storage, err := media.Default(); err != nil {
+		log.Printf("[ERROR] DeleteMedia: Media storage is misconfigured, files of media ID '%s' were kept - %s", id, err.Error())
+	} else {
+		deleteMediaObjects(storage, keys)
+	}
+
+	recordMediaAudit(c, audit.ActionMediaDelete, item.ID, &item, nil)
+
+	log.Printf("[SUCCESS] DeleteMedia: Successfully deleted media ID '%s'", id)
+	c.JSON(http.StatusOK, gin.H{"message": "Media deleted successfully"})
+}
+
+// findMedia fetches media metadata by ID, responding with an error if it can't
+func findMedia(c *gin.Context, handler, id string) (models.Media, bool) {
+	var item models.Media
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		log.Printf("[ERROR] %s: Invalid media ID format '%s'", handler, id)
+		apierrors.RespondInvalidMediaID(c)
+		return item, false
+	}
+
+	collection := database.Database.Collection("media")
+	err = collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&item)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Printf("[ERROR] %s: Media not found for ID '%s'", handler, id)
+			apierrors.RespondMediaNotFound(c)
+			return item, false
+		}
+		log.Printf("[ERROR] %s: Failed to fetch media ID '%s' - %s", handler, id, err.Error())
+		apierrors.RespondFailedToFetchMedia(c)
+		return item, false
+	}
+	return item, true
+}
+
+// postsReferencingMedia returns the slugs of posts whose content contains the media
+// ID, which appears in both API and public storage URLs of the media
+func postsReferencingMedia(id primitive.ObjectID) ([]string, error) {
+	collection := database.Database.Collection("posts")
+	cursor, err := collection.Find(context.Background(),
+		bson.M{"content": bson.M{"$regex": id.Hex()}},
+		options.Find().SetProjection(bson.M{"slug": 1}).SetLimit(20),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	var posts []models.Post
+	if err := cursor.All(context.Background(), &posts); err != nil {
+		return nil, err
+	}
+	slugs := make([]string, 0, len(posts))
+	for _, post := range posts {
+		slugs = append(slugs, post.Slug)
+	}
+	return slugs, nil
+}
+
+// deleteMediaObjects removes objects from media storage, logging failures
+func deleteMediaObjects(storage media.Storage, keys []string) {
+	for _, key := range keys {
+		if err := storage.Delete(context.Background(), key); err != nil {
+			log.Printf("[ERROR] deleteMediaObjects: Failed to delete '%s' - %s", key, err.Error())
+		}
+	}
+}
+
+// mediaVariant returns the variant of media with the given name
+func mediaVariant(item models.Media, name string) (models.MediaVariant, bool) {
+	for _, variant := range item.Variants {
+		if variant.Name == name {
+			return variant, true
+		}
+	}
+	return models.MediaVariant{}, false
+}
+
+// setMediaURLs sets the URLs media and their variants are served from
+func setMediaURLs(item *models.Media) {
+	item.URL = mediaPath + item.ID.Hex()
+	for i := range item.Variants {
+		item.Variants[i].URL = item.URL + "/" + item.Variants[i].Name
+	}
+}
+
+// mediaFileName cleans the client's file name for display and downloads, falling
+// back to "upload" with the extension of the sniffed type
+func mediaFileName(name, extension string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" || name == "." || name == "/" {
+		return "upload" + extension
+	}
+	if utf8.RuneCountInString(name) > maxMediaFileNameLength {
+		name = string([]rune(name)[:maxMediaFileNameLength])
+	}
+	return name
+}
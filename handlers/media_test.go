@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"testing"
+
+	"dbl-blog-backend/models"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Unit tests for media helpers
+
+func TestMediaFileName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"plain name", "photo.jpg", "photo.jpg"},
+		{"unix path", "/home/user/photo.jpg", "photo.jpg"},
+		{"windows path", `C:\Users\me\photo.jpg`, "photo.jpg"},
+		{"quotes and control characters", "my \"photo\"\n.jpg", "my photo.jpg"},
+		{"empty name", "", "upload.png"},
+		{"dot", ".", "upload.png"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, mediaFileName(tc.input, ".png"))
+		})
+	}
+}
+
+func TestSetMediaURLsAndVariant(t *testing.T) {
+	id, _ := primitive.ObjectIDFromHex("64b7f0c2a1b2c3d4e5f60718")
+	item := models.Media{ID: id, Variants: []models.MediaVariant{{Name: "small"}, {Name: "small-webp"}}}
+
+	setMediaURLs(&item)
+	assert.Equal(t, "/api/v1/media/64b7f0c2a1b2c3d4e5f60718", item.URL)
+	assert.Equal(t, "/api/v1/media/64b7f0c2a1b2c3d4e5f60718/small-webp", item.Variants[1].URL)
+
+	variant, ok := mediaVariant(item, "small")
+	assert.True(t, ok)
+	assert.Equal(t, "small", variant.Name)
+	_, ok = mediaVariant(item, "huge")
+	assert.False(t, ok)
+}
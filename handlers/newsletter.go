@@ -0,0 +1,481 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/models"
+	"dbl-blog-backend/newsletter"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// subscribeMessage is the response to every subscribe request, so that it doesn't
+// reveal whether an address is already subscribed
+const subscribeMessage = "Check your inbox for a link to confirm your subscription"
+
+// subscribeRequest is the body of subscribe requests
+type subscribeRequest struct {
+	Email string   `json:"email" binding:"required,email,max=254"`
+	Tags  []string `json:"tags" binding:"max=20,dive,max=50"` // Empty to receive all posts
+}
+
+// preferencesRequest is the body of preference updates
+type preferencesRequest struct {
+	Tags []string `json:"tags" binding:"max=20,dive,max=50"`
+}
+
+// bounceRequest reports a bounce or spam complaint for an address, e.g. from the
+// notifications of an email provider
+type bounceRequest struct {
+	Email  string `json:"email" binding:"required,email,max=254"`
+	Type   string `json:"type" binding:"required,oneof=bounce complaint"`
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// Subscribe starts a newsletter subscription and sends the email confirming it
+// (double opt-in). Confirmed and complained addresses are left unchanged.
+func Subscribe(c *gin.Context) {
+	log.Printf("[INFO] Subscribe: Received request from %s", c.ClientIP())
+
+	var request subscribeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("[ERROR] Subscribe: Validation failed - %s", err.Error())
+		apierrors.RespondWithBindingError(c, err)
+		return
+	}
+	email := newsletter.NormalizeEmail(request.Email)
+
+	collection := database.Database.Collection(newsletter.SubscribersCollectionName)
+	var existing models.Subscriber
+	err := collection.FindOne(context.Background(), bson.M{"email": email}).Decode(&existing)
+	if err != nil && err != mongo.ErrNoDocuments {
+		log.Printf("[ERROR] Subscribe: Failed to look up subscriber - %s", err.Error())
+		apierrors.RespondFailedToSubscribe(c)
+		return
+	}
+	found := err == nil
+
+	now := time.Now()
+	switch {
+	case found && (existing.Status == newsletter.SubscriberConfirmed || existing.Status == newsletter.SubscriberComplained):
+		log.Printf("[INFO] Subscribe: Subscriber ID '%s' is %s, nothing to do", existing.ID.Hex(), existing.Status)
+		c.JSON(http.StatusAccepted, gin.H{"message": subscribeMessage})
+		return
+	case found && existing.ConfirmSentAt != nil && now.Sub(*existing.ConfirmSentAt) < newsletter.ConfirmResendInterval:
+		log.Printf("[INFO] Subscribe: Confirmation recently sent to subscriber ID '%s', not sending another", existing.ID.Hex())
+		c.JSON(http.StatusAccepted, gin.H{"message": subscribeMessage})
+		return
+	}
+
+	token, err := newsletter.NewToken()
+	if err != nil {
+		log.Printf("[ERROR] Subscribe: Failed to generate token - %s", err.Error())
+		apierrors.RespondFailedToSubscribe(c)
+		return
+	}
+	expiresAt := now.Add(newsletter.ConfirmTokenLifetime)
+	pending := bson.M{
+		"status":                   newsletter.SubscriberPending,
+		"tags":                     newsletter.NormalizeTags(request.Tags),
+		"confirm_token_hash":       newsletter.HashToken(token),
+		"confirm_token_expires_at": expiresAt,
+		"confirm_sent_at":          now,
+		"updated_at":               now,
+	}
+
+	if found {
+		_, err = collection.UpdateOne(context.Background(), bson.M{"_id": existing.ID}, bson.M{"$set": pending})
+	} else {
+		unsubscribeToken, tokenErr := newsletter.NewToken()
+		if tokenErr != nil {
+			log.Printf("[ERROR] Subscribe: Failed to generate token - %s", tokenErr.Error())
+			apierrors.RespondFailedToSubscribe(c)
+			return
+		}
+		pending["email"] = email
+		pending["unsubscribe_token"] = unsubscribeToken
+		pending["bounces"] = 0
+		pending["created_at"] = now
+		_, err = collection.InsertOne(context.Background(), pending)
+	}
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			// A concurrent request subscribed the same address and sent its confirmation
+			c.JSON(http.StatusAccepted, gin.H{"message": subscribeMessage})
+			return
+		}
+		log.Printf("[ERROR] Subscribe: Failed to save subscriber - %s", err.Error())
+		apierrors.RespondFailedToSubscribe(c)
+		return
+	}
+
+	mailer, err := newsletter.Default()
+	if err == nil {
+		var msg newsletter.Message
+		if msg, err = newsletter.ConfirmationMessage(email, token); err == nil {
+			err = mailer.Send(c.Request.Context(), msg)
+		}
+	}
+	if err != nil {
+		log.Printf("[ERROR] Subscribe: Failed to send confirmation email - %s", err.Error())
+		// Allow another attempt right away
+		_, _ = collection.UpdateOne(context.Background(), bson.M{"email": email}, bson.M{"$unset": bson.M{"confirm_sent_at": ""}})
+		apierrors.RespondFailedToSendConfirmation(c)
+		return
+	}
+
+	log.Printf("[SUCCESS] Subscribe: Sent confirmation email (new subscriber: %t)", !found)
+	c.JSON(http.StatusAccepted, gin.H{"message": subscribeMessage})
+}
+
+// ConfirmSubscription confirms a pending subscription with the token from its
+// confirmation email
+func ConfirmSubscription(c *gin.Context) {
+	log.Printf("[INFO] ConfirmSubscription: Received request from %s", c.ClientIP())
+
+	token := c.Query("token")
+	if token == "" {
+		apierrors.RespondInvalidNewsletterToken(c)
+		return
+	}
+
+	now := time.Now()
+	var subscriber models.Subscriber
+	err := database.Database.Collection(newsletter.SubscribersCollectionName).FindOneAndUpdate(
+		context.Background(),
+		bson.M{
+			"confirm_token_hash":       newsletter.HashToken(token),
+			"confirm_token_expires_at": bson.M{"$gt": now},
+			"status":                   newsletter.SubscriberPending,
+		},
+		bson.M{
+			"$set":   bson.M{"status": newsletter.SubscriberConfirmed, "confirmed_at": now, "updated_at": now},
+			"$unset": bson.M{"confirm_token_hash": "", "confirm_token_expires_at": "", "status_reason": "", "unsubscribed_at": ""},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&subscriber)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Printf("[ERROR] ConfirmSubscription: Invalid or expired token")
+			apierrors.RespondInvalidNewsletterToken(c)
+			return
+		}
+		log.Printf("[ERROR] ConfirmSubscription: Failed to confirm subscription - %s", err.Error())
+		apierrors.RespondFailedToUpdateSubscriber(c)
+		return
+	}
+
+	log.Printf("[SUCCESS] ConfirmSubscription: Confirmed subscriber ID '%s'", subscriber.ID.Hex())
+	c.JSON(http.StatusOK, gin.H{"message": "Subscription confirmed"})
+}
+
+// Unsubscribe ends a subscription with the token from the unsubscribe link of an
+// email. It accepts GET for links and POST for one-click unsubscribe (RFC 8058).
+func Unsubscribe(c *gin.Context) {
+	log.Printf("[INFO] Unsubscribe: Received request from %s", c.ClientIP())
+
+	subscriber, ok := findSubscriberByToken(c, "Unsubscribe")
+	if !ok {
+		return
+	}
+
+	// Bounced and complained subscribers keep their status, which says more
+	if subscriber.Status == newsletter.SubscriberPending || subscriber.Status == newsletter.SubscriberConfirmed {
+		now := time.Now()
+		_, err := database.Database.Collection(newsletter.SubscribersCollectionName).UpdateOne(context.Background(),
+			bson.M{"_id": subscriber.ID},
+			bson.M{
+				"$set":   bson.M{"status": newsletter.SubscriberUnsubscribed, "unsubscribed_at": now, "updated_at": now},
+				"$unset": bson.M{"confirm_token_hash": "", "confirm_token_expires_at": ""},
+			},
+		)
+		if err != nil {
+			log.Printf("[ERROR] Unsubscribe: Failed to unsubscribe subscriber ID '%s' - %s", subscriber.ID.Hex(), err.Error())
+			apierrors.RespondFailedToUpdateSubscriber(c)
+			return
+		}
+	}
+
+	log.Printf("[SUCCESS] Unsubscribe: Unsubscribed subscriber ID '%s'", subscriber.ID.Hex())
+	c.JSON(http.StatusOK, gin.H{"message": "You have been unsubscribed"})
+}
+
+// GetNewsletterPreferences returns the subscription of the token from an email's
+// preferences link
+func GetNewsletterPreferences(c *gin.Context) {
+	log.Printf("[INFO] GetNewsletterPreferences: Received request from %s", c.ClientIP())
+
+	subscriber, ok := findSubscriberByToken(c, "GetNewsletterPreferences")
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"email":  subscriber.Email,
+		"status": subscriber.Status,
+		"tags":   subscriber.Tags,
+	})
+}
+
+// UpdateNewsletterPreferences replaces the tags a subscriber receives posts for.
+// An empty list receives all posts.
+func UpdateNewsletterPreferences(c *gin.Context) {
+	log.Printf("[INFO] UpdateNewsletterPreferences: Received request from %s", c.ClientIP())
+
+	subscriber, ok := findSubscriberByToken(c, "UpdateNewsletterPreferences")
+	if !ok {
+		return
+	}
+
+	var request preferencesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("[ERROR] UpdateNewsletterPreferences: Validation failed - %s", err.Error())
+		apierrors.RespondWithBindingError(c, err)
+		return
+	}
+	tags := newsletter.NormalizeTags(request.Tags)
+
+	_, err := database.Database.Collection(newsletter.SubscribersCollectionName).UpdateOne(context.Background(),
+		bson.M{"_id": subscriber.ID},
+		bson.M{"$set": bson.M{"tags": tags, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		log.Printf("[ERROR] UpdateNewsletterPreferences: Failed to update subscriber ID '%s' - %s", subscriber.ID.Hex(), err.Error())
+		apierrors.RespondFailedToUpdateSubscriber(c)
+		return
+	}
+
+	log.Printf("[SUCCESS] UpdateNewsletterPreferences: Updated %d tags of subscriber ID '%s'", len(tags), subscriber.ID.Hex())
+	c.JSON(http.StatusOK, gin.H{
+		"email":  subscriber.Email,
+		"status": subscriber.Status,
+		"tags":   tags,
+	})
+}
+
+// GetSubscribers lists subscribers, newest first. Filters: status, tag.
+func GetSubscribers(c *gin.Context) {
+	log.Printf("[INFO] GetSubscribers: Received request from %s", c.ClientIP())
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filter := bson.M{}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+	if tag := c.Query("tag"); tag != "" {
+		filter["tags"] = tag
+	}
+
+	collection := database.Database.Collection(newsletter.SubscribersCollectionName)
+	total, err := collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		log.Printf("[ERROR] GetSubscribers: Failed to count subscribers - %s", err.Error())
+		apierrors.RespondFailedToFetchSubscribers(c)
+		return
+	}
+
+	findOptions := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		log.Printf("[ERROR] GetSubscribers: Failed to find subscribers - %s", err.Error())
+		apierrors.RespondFailedToFetchSubscribers(c)
+		return
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	subscribers := []models.Subscriber{}
+	if err = cursor.All(context.Background(), &subscribers); err != nil {
+		log.Printf("[ERROR] GetSubscribers: Failed to decode subscribers - %s", err.Error())
+		apierrors.RespondFailedToFetchSubscribers(c)
+		return
+	}
+
+	log.Printf("[SUCCESS] GetSubscribers: Retrieved %d subscribers (page %d, limit %d, total %d)", len(subscribers), page, limit, total)
+	c.JSON(http.StatusOK, gin.H{
+		"subscribers": subscribers,
+		"page":        page,
+		"limit":       limit,
+		"total":       total,
+	})
+}
+
+// DeleteSubscriber permanently deletes a subscriber, e.g. on an erasure request.
+// Unlike unsubscribing, nothing stops the address from subscribing again.
+func DeleteSubscriber(c *gin.Context) {
+	id := c.Param("id")
+	log.Printf("[INFO] DeleteSubscriber: Received request to delete subscriber ID '%s' from %s", id, c.ClientIP())
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		log.Printf("[ERROR] DeleteSubscriber: Invalid subscriber ID format '%s'", id)
+		apierrors.RespondInvalidSubscriberID(c)
+		return
+	}
+
+	result, err := database.Database.Collection(newsletter.SubscribersCollectionName).DeleteOne(context.Background(), bson.M{"_id": objectID})
+	if err != nil {
+		log.Printf("[ERROR] DeleteSubscriber: Failed to delete subscriber ID '%s' - %s", id, err.Error())
+		apierrors.RespondFailedToDeleteSubscriber(c)
+		return
+	}
+	if result.DeletedCount == 0 {
+		log.Printf("[ERROR] DeleteSubscriber: Subscriber not found for ID '%s'", id)
+		apierrors.RespondSubscriberNotFound(c)
+		return
+	}
+
+	log.Printf("[SUCCESS] DeleteSubscriber: Successfully deleted subscriber ID '%s'", id)
+	c.JSON(http.StatusOK, gin.H{"message": "Subscriber deleted successfully"})
+}
+
+// ReportSubscriberBounce records a bounce or spam complaint for an address.
+// Either stops posts being sent to it.
+func ReportSubscriberBounce(c *gin.Context) {
+	log.Printf("[INFO] ReportSubscriberBounce: Received request from %s", c.ClientIP())
+
+	var request bounceRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Printf("[ERROR] ReportSubscriberBounce: Validation failed - %s", err.Error())
+		apierrors.RespondWithBindingError(c, err)
+		return
+	}
+
+	collection := database.Database.Collection(newsletter.SubscribersCollectionName)
+	var subscriber models.Subscriber
+	err := collection.FindOne(context.Background(), bson.M{"email": newsletter.NormalizeEmail(request.Email)}).Decode(&subscriber)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Printf("[ERROR] ReportSubscriberBounce: No subscriber with the reported address")
+			apierrors.RespondSubscriberNotFound(c)
+			return
+		}
+		log.Printf("[ERROR] ReportSubscriberBounce: Failed to look up subscriber - %s", err.Error())
+		apierrors.RespondFailedToFetchSubscribers(c)
+		return
+	}
+
+	if request.Type == "complaint" {
+		err = newsletter.RecordComplaint(context.Background(), subscriber.ID, request.Reason)
+	} else {
+		err = newsletter.RecordBounce(context.Background(), subscriber.ID, request.Reason)
+	}
+	if err != nil {
+		log.Printf("[ERROR] ReportSubscriberBounce: Failed to record %s for subscriber ID '%s' - %s", request.Type, subscriber.ID.Hex(), err.Error())
+		apierrors.RespondFailedToUpdateSubscriber(c)
+		return
+	}
+
+	if err = collection.FindOne(context.Background(), bson.M{"_id": subscriber.ID}).Decode(&subscriber); err != nil {
+		log.Printf("[ERROR] ReportSubscriberBounce: Failed to fetch subscriber ID '%s' - %s", subscriber.ID.Hex(), err.Error())
+		apierrors.RespondFailedToFetchSubscribers(c)
+		return
+	}
+
+	log.Printf("[SUCCESS] ReportSubscriberBounce: Recorded %s for subscriber ID '%s'", request.Type, subscriber.ID.Hex())
+	c.JSON(http.StatusOK, subscriber)
+}
+
+// GetNewsletterIssues lists the posts sent or being sent to subscribers, newest
+// first, with their progress. Filter: status.
+func GetNewsletterIssues(c *gin.Context) {
+	log.Printf("[INFO] GetNewsletterIssues: Received request from %s", c.ClientIP())
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filter := bson.M{}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+
+	collection := database.Database.Collection(newsletter.IssuesCollectionName)
+	total, err := collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		log.Printf("[ERROR] GetNewsletterIssues: Failed to count issues - %s", err.Error())
+		apierrors.RespondFailedToFetchNewsletterIssues(c)
+		return
+	}
+
+	findOptions := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		log.Printf("[ERROR] GetNewsletterIssues: Failed to find issues - %s", err.Error())
+		apierrors.RespondFailedToFetchNewsletterIssues(c)
+		return
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	issues := []models.NewsletterIssue{}
+	if err = cursor.All(context.Background(), &issues); err != nil {
+		log.Printf("[ERROR] GetNewsletterIssues: Failed to decode issues - %s", err.Error())
+		apierrors.RespondFailedToFetchNewsletterIssues(c)
+		return
+	}
+
+	log.Printf("[SUCCESS] GetNewsletterIssues: Retrieved %d issues (page %d, limit %d, total %d)", len(issues), page, limit, total)
+	c.JSON(http.StatusOK, gin.H{
+		"issues": issues,
+		"page":   page,
+		"limit":  limit,
+		"total":  total,
+	})
+}
+
+// findSubscriberByToken fetches the subscriber whose unsubscribe token is in the
+// token query parameter, responding with an error when there is none
+func findSubscriberByToken(c *gin.Context, handler string) (models.Subscriber, bool) {
+	var subscriber models.Subscriber
+
+	token := c.Query("token")
+	if token == "" {
+		apierrors.RespondInvalidNewsletterToken(c)
+		return subscriber, false
+	}
+
+	err := database.Database.Collection(newsletter.SubscribersCollectionName).FindOne(context.Background(), bson.M{"unsubscribe_token": token}).Decode(&subscriber)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Printf("[ERROR] %s: Invalid token", handler)
+			apierrors.RespondInvalidNewsletterToken(c)
+			return subscriber, false
+		}
+		log.Printf("[ERROR] %s: Failed to fetch subscriber - %s", handler, err.Error())
+		apierrors.RespondFailedToFetchSubscribers(c)
+		return subscriber, false
+	}
+	return subscriber, true
+}
@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"time"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/audit"
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/jsonpatch"
+	"dbl-blog-backend/markdown"
+	"dbl-blog-backend/middleware"
+	"dbl-blog-backend/models"
+	"dbl-blog-backend/related"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxPatchBodyBytes limits the size of PATCH request bodies
+const maxPatchBodyBytes = 1 << 20
+
+// patchableFields lists the post fields (by JSON name, identical to their BSON
+// name) that PATCH may change. All other fields are read-only.
+var patchableFields = map[string]bool{
+	"title":      true,
+	"content":    true,
+	"slug":       true,
+	"summary":    true,
+	"tags":       true,
+	"author_ids": true,
+	"published":  true,
+
+	"lang":                 true,
+	"translation_group_id": true,
+}
+
+// contentMetadataFields are derived from the content and updated along with it
+var contentMetadataFields = []string{"word_count", "reading_time_minutes", "toc"}
+
+// PatchPost partially updates a blog post with a JSON Merge Patch (RFC 7386) or a
+// JSON Patch (RFC 6902), validating the patched post and updating only changed fields
+func PatchPost(c *gin.Context) {
+	id := c.Param("id")
+	log.Printf("[INFO] PatchPost: Received request for post ID '%s' from %s", id, c.ClientIP())
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		apierrors.RespondInvalidPostID(c)
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if mediaType != jsonpatch.MergePatchMediaType && mediaType != jsonpatch.JSONPatchMediaType {
+		log.Printf("[ERROR] PatchPost: Unsupported Content-Type '%s' for post ID '%s'", mediaType, id)
+		c.Header("Accept-Patch", jsonpatch.MergePatchMediaType+", "+jsonpatch.JSONPatchMediaType)
+		apierrors.RespondUnsupportedPatchFormat(c)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxPatchBodyBytes))
+	if err != nil {
+		log.Printf("[ERROR] PatchPost: Failed to read body for post ID '%s' - %s", id, err.Error())
+		apierrors.RespondInvalidPatch(c, err.Error())
+		return
+	}
+
+	if !authorizePostChange(c, "PatchPost", objectID, middleware.PermEditAnyPost) {
+		return
+	}
+
+	collection := database.Database.Collection("posts")
+	var current models.Post
+	err = collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&current)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Printf("[ERROR] PatchPost: Post not found for ID '%s'", id)
+			apierrors.RespondPostNotFound(c)
+			return
+		}
+		log.Printf("[ERROR] PatchPost: Failed to fetch post ID '%s' - %s", id, err.Error())
+		apierrors.RespondFailedToFetchPost(c)
+		return
+	}
+
+	// If-Match is optional for PATCH, but must be current when provided
+	if c.GetHeader("If-Match") != "" {
+		expectedVersion, ok := expectedPostVersion(c, "PatchPost", nil)
+		if !ok {
+			return
+		}
+		if expectedVersion != nil && *expectedVersion != current.Version {
+			log.Printf("[ERROR] PatchPost: Stale If-Match version %d for post ID '%s' (current version %d)", *expectedVersion, id, current.Version)
+			c.Header("ETag", postETag(current))
+			apierrors.RespondPreconditionFailed(c)
+			return
+		}
+	}
+
+	patched, changedFields, err := applyPostPatch(current, mediaType, body)
+	if err != nil {
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			log.Printf("[ERROR] PatchPost: Test operation failed for post ID '%s' - %s", id, err.Error())
+			apierrors.RespondPatchTestFailed(c, err.Error())
+			return
+		}
+		log.Printf("[ERROR] PatchPost: Invalid patch for post ID '%s' - %s", id, err.Error())
+		apierrors.RespondInvalidPatch(c, err.Error())
+		return
+	}
+
+	if err := binding.Validator.ValidateStruct(&patched); err != nil {
+		log.Printf("[ERROR] PatchPost: Validation failed for post ID '%s' - %s", id, err.Error())
+		apierrors.RespondWithBindingError(c, err)
+		return
+	}
+
+	if changedFields["slug"] && patched.Slug == "" {
+		log.Printf("[ERROR] PatchPost: Attempt to remove the slug of post ID '%s'", id)
+		apierrors.RespondWithValidationError(c, "slug cannot be removed")
+		return
+	}
+
+	if changedFields["lang"] {
+		lang, ok := postLanguage(c, "PatchPost", patched.Lang)
+		if !ok {
+			return
+		}
+		patched.Lang = lang
+	}
+
+	if changedFields["translation_group_id"] {
+		if patched.TranslationGroupID.IsZero() {
+			log.Printf("[ERROR] PatchPost: Attempt to remove the translation group of post ID '%s'", id)
+			apierrors.RespondWithValidationError(c, "translation_group_id cannot be removed")
+			return
+		}
+		if !validateTranslationGroup(c, "PatchPost", patched.TranslationGroupID) {
+			return
+		}
+	}
+
+	if changedFields["author_ids"] && !middleware.Can(c, middleware.PermEditAnyPost) {
+		log.Printf("[SECURITY] PatchPost: API key of %s may not change the authors of post ID '%s'", c.ClientIP(), id)
+		recordOwnershipFailure(c, objectID)
+		apierrors.RespondForbidden(c)
+		return
+	}
+
+	if len(changedFields) == 0 {
+		log.Printf("[SUCCESS] PatchPost: No changes for post ID '%s'", id)
+		c.Header("ETag", postETag(current))
+		c.JSON(http.StatusOK, current)
+		return
+	}
+
+	updateDoc, err := postFieldsUpdate(patched, changedFields)
+	if err != nil {
+		log.Printf("[ERROR] PatchPost: Failed to build update for post ID '%s' - %s", id, err.Error())
+		apierrors.RespondFailedToUpdatePost(c)
+		return
+	}
+
+	// The version read above guards against concurrent writes between read and update
+	var previousPost, updatedPost models.Post
+	err = updatePostWithEvents(objectID, bson.M{"_id": objectID, "version": versionFilter(current.Version)},
+		updateDoc, &previousPost, &updatedPost)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			respondVersionMismatchOrNotFound(c, "PatchPost", objectID)
+			return
+		}
+		if isTranslationConflict(err) {
+			log.Printf("[ERROR] PatchPost: Translation group of post ID '%s' already has a post in its language", id)
+			apierrors.RespondTranslationAlreadyExists(c)
+			return
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			log.Printf("[ERROR] PatchPost: Duplicate key error for post ID '%s'", id)
+			apierrors.RespondPostAlreadyExists(c)
+			return
+		}
+		if errors.Is(err, errFetchUpdatedPost) {
+			log.Printf("[ERROR] PatchPost: Failed to fetch updated post ID '%s' - %s", id, err.Error())
+			apierrors.RespondFailedToFetchUpdatedPost(c)
+			return
+		}
+		log.Printf("[ERROR] PatchPost: Failed to update post ID '%s' - %s", id, err.Error())
+		apierrors.RespondFailedToUpdatePost(c)
+		return
+	}
+
+	recordPostAudit(c, audit.ActionPostUpdate, objectID, &previousPost, &updatedPost)
+	publishPostEvents(&previousPost, &updatedPost)
+	related.Schedule()
+
+	log.Printf("[SUCCESS] PatchPost: Patched %d fields of post ID '%s'", len(changedFields), id)
+	c.Header("ETag", postETag(updatedPost))
+	c.JSON(http.StatusOK, updatedPost)
+}
+
+// applyPostPatch applies a patch document of the given media type to the JSON
+// representation of post, returning the patched post and the fields that changed.
+// Changes to read-only or unknown fields are rejected.
+func applyPostPatch(post models.Post, mediaType string, body []byte) (models.Post, map[string]bool, error) {
+	var patched models.Post
+
+	original, err := json.Marshal(post)
+	if err != nil {
+		return patched, nil, err
+	}
+	before, err := jsonpatch.Decode(original)
+	if err != nil {
+		return patched, nil, err
+	}
+
+	var after interface{}
+	switch mediaType {
+	case jsonpatch.MergePatchMediaType:
+		patch, err := jsonpatch.Decode(body)
+		if err != nil {
+			return patched, nil, fmt.Errorf("invalid merge patch: %w", err)
+		}
+		after = jsonpatch.MergePatch(before, patch)
+	default:
+		var operations []jsonpatch.Operation
+		if err := json.Unmarshal(body, &operations); err != nil {
+			return patched, nil, fmt.Errorf("invalid JSON patch: %w", err)
+		}
+		if after, err = jsonpatch.Apply(before, operations); err != nil {
+			return patched, nil, err
+		}
+	}
+
+	beforeObject := before.(map[string]interface{})
+	afterObject, ok := after.(map[string]interface{})
+	if !ok {
+		return patched, nil, errors.New("patched post must be a JSON object")
+	}
+
+	changed := make(map[string]bool)
+	for field := range beforeObject {
+		if _, exists := afterObject[field]; !exists {
+			afterObject[field] = nil // Removed fields reset to their zero value
+		}
+	}
+	for field, value := range afterObject {
+		if jsonpatch.Equal(beforeObject[field], value) {
+			continue
+		}
+		if !patchableFields[field] {
+			if _, known := beforeObject[field]; known {
+				return patched, nil, fmt.Errorf("field '%s' is read-only", field)
+			}
+			return patched, nil, fmt.Errorf("unknown field '%s'", field)
+		}
+		changed[field] = true
+	}
+
+	data, err := json.Marshal(afterObject)
+	if err != nil {
+		return patched, nil, err
+	}
+	if err := json.Unmarshal(data, &patched); err != nil {
+		return patched, nil, fmt.Errorf("invalid patched post: %w", err)
+	}
+
+	return patched, changed, nil
+}
+
+// postFieldsUpdate builds an update document that sets the given fields of post,
+// unsets those that are empty, bumps the version and refreshes updated_at.
+// A changed slug is also added to the post's slug history, and changed content
+// refreshes the content metadata.
+func postFieldsUpdate(post models.Post, fields map[string]bool) (bson.M, error) {
+	if fields["content"] {
+		markdown.ApplyMetadata(&post)
+	}
+	data, err := bson.Marshal(post)
+	if err != nil {
+		return nil, err
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	setFields := bson.M{"updated_at": time.Now()}
+	unsetFields := bson.M{}
+	for field := range fields {
+		if value, ok := doc[field]; ok {
+			setFields[field] = value
+		} else {
+			unsetFields[field] = ""
+		}
+	}
+	if fields["content"] {
+		for _, field := range contentMetadataFields {
+			setFields[field] = doc[field]
+		}
+	}
+
+	update := bson.M{
+		"$set": setFields,
+		"$inc": bson.M{"version": 1},
+	}
+	if len(unsetFields) > 0 {
+		update["$unset"] = unsetFields
+	}
+	if fields["slug"] {
+		update["$addToSet"] = bson.M{"slugs": post.Slug}
+	}
+	return update, nil
+}
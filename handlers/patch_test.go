@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"dbl-blog-backend/jsonpatch"
+	"dbl-blog-backend/models"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Unit tests for PATCH document handling
+
+func patchTestPost() models.Post {
+	return models.Post{
+		ID:        primitive.NewObjectID(),
+		Title:     "Original Title",
+		Content:   "Original content",
+		Slug:      "original-title",
+		Tags:      []string{"go", "mongo"},
+		Version:   3,
+		CreatedAt: time.Now().Add(-time.Hour),
+		UpdatedAt: time.Now(),
+	}
+}
+
+func TestApplyPostPatch_MergePatch(t *testing.T) {
+	post := patchTestPost()
+
+	patched, changed, err := applyPostPatch(post, jsonpatch.MergePatchMediaType, []byte(`{"published": true}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"published": true}, changed)
+	assert.True(t, patched.Published)
+	assert.Equal(t, post.Tags, patched.Tags, "Omitted fields should be kept")
+	assert.Equal(t, post.Title, patched.Title)
+}
+
+func TestApplyPostPatch_MergePatchNullRemovesField(t *testing.T) {
+	post := patchTestPost()
+
+	patched, changed, err := applyPostPatch(post, jsonpatch.MergePatchMediaType, []byte(`{"tags": null}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"tags": true}, changed)
+	assert.Empty(t, patched.Tags)
+}
+
+func TestApplyPostPatch_JSONPatch(t *testing.T) {
+	post := patchTestPost()
+	body := []byte(`[
+		{"op": "test", "path": "/title", "value": "Original Title"},
+		{"op": "add", "path": "/tags/-", "value": "api"},
+		{"op": "replace", "path": "/title", "value": "New Title"}
+	]`)
+
+	patched, changed, err := applyPostPatch(post, jsonpatch.JSONPatchMediaType, body)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"tags": true, "title": true}, changed)
+	assert.Equal(t, "New Title", patched.Title)
+	assert.Equal(t, []string{"go", "mongo", "api"}, patched.Tags)
+}
+
+func TestApplyPostPatch_JSONPatchNullValue(t *testing.T) {
+	post := patchTestPost()
+	post.Summary = "Original summary"
+
+	patched, changed, err := applyPostPatch(post, jsonpatch.JSONPatchMediaType, []byte(`[{"op":"replace","path":"/summary","value":null}]`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"summary": true}, changed)
+	assert.Empty(t, patched.Summary)
+}
+
+func TestApplyPostPatch_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		mediaType  string
+		body       string
+		testFailed bool
+	}{
+		{"read-only field", jsonpatch.MergePatchMediaType, `{"views": 100}`, false},
+		{"version field", jsonpatch.JSONPatchMediaType, `[{"op": "replace", "path": "/version", "value": 9}]`, false},
+		{"unknown field", jsonpatch.MergePatchMediaType, `{"nonexistent": "value"}`, false},
+		{"malformed merge patch", jsonpatch.MergePatchMediaType, `{"title": `, false},
+		{"non-object result", jsonpatch.MergePatchMediaType, `"replacement"`, false},
+		{"failed test", jsonpatch.JSONPatchMediaType, `[{"op": "test", "path": "/title", "value": "Other"}]`, true},
+		{"wrong field type", jsonpatch.MergePatchMediaType, `{"published": "yes"}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := applyPostPatch(patchTestPost(), tt.mediaType, []byte(tt.body))
+
+			assert.Error(t, err)
+			assert.Equal(t, tt.testFailed, errors.Is(err, jsonpatch.ErrTestFailed))
+		})
+	}
+}
+
+func TestPostFieldsUpdate(t *testing.T) {
+	post := patchTestPost()
+	post.Summary = ""
+	post.Published = true
+
+	update, err := postFieldsUpdate(post, map[string]bool{"published": true, "summary": true})
+
+	assert.NoError(t, err)
+	set := update["$set"].(bson.M)
+	assert.Equal(t, true, set["published"])
+	assert.Contains(t, set, "updated_at")
+	assert.NotContains(t, set, "title", "Unchanged fields should not be written")
+	assert.Equal(t, bson.M{"summary": ""}, update["$unset"], "Empty omitempty fields should be unset")
+	assert.Equal(t, bson.M{"version": 1}, update["$inc"])
+	assert.NotContains(t, update, "$addToSet", "Slug history should only change with the slug")
+
+	post.Slug = "renamed-post"
+	update, err = postFieldsUpdate(post, map[string]bool{"slug": true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, bson.M{"slugs": "renamed-post"}, update["$addToSet"])
+}
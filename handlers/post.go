@@ -2,15 +2,27 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/audit"
 	"dbl-blog-backend/database"
+	"dbl-blog-backend/locale"
+	"dbl-blog-backend/markdown"
+	"dbl-blog-backend/middleware"
 	"dbl-blog-backend/models"
+	"dbl-blog-backend/newsletter"
+	"dbl-blog-backend/related"
+	"dbl-blog-backend/slug"
+	"dbl-blog-backend/watch"
+	"dbl-blog-backend/webhooks"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
@@ -27,26 +39,90 @@ func CreatePost(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&post); err != nil {
 		log.Printf("[ERROR] CreatePost: Validation failed - %s", err.Error())
-		apierrors.RespondWithValidationError(c, err.Error())
+		apierrors.RespondWithBindingError(c, err)
 		return
 	}
 
-	// Generate slug from title if not provided
-	if post.Slug == "" {
-		post.Slug = generateSlug(post.Title)
+	post.Series = nil // Series membership is managed through the series endpoints
+	post.Authors = nil
+	post.Translations = nil
+	markdown.ApplyMetadata(&post)
+
+	lang, ok := postLanguage(c, "CreatePost", post.Lang)
+	if !ok {
+		return
+	}
+	post.Lang = lang
+
+	// Attribute the post to the author mapped to the request's API key.
+	// Roles limited to their own posts can't attribute posts to anyone else.
+	if !middleware.Can(c, middleware.PermEditAnyPost) {
+		author, ok := authorForRequest(c)
+		if !ok {
+			log.Printf("[ERROR] CreatePost: No author mapped to the API key of %s", c.ClientIP())
+			recordOwnershipFailure(c, primitive.NilObjectID)
+			apierrors.RespondNotPostOwner(c)
+			return
+		}
+		post.AuthorIDs = []primitive.ObjectID{author.ID}
+	} else if len(post.AuthorIDs) == 0 {
+		if author, ok := authorForRequest(c); ok {
+			post.AuthorIDs = []primitive.ObjectID{author.ID}
+		}
 	}
 
-	// Set timestamps
+	// A translation joins the group of the post it translates; other posts
+	// start a group of their own
+	if post.TranslationGroupID.IsZero() {
+		post.TranslationGroupID = primitive.NewObjectID()
+	} else if !validateTranslationGroup(c, "CreatePost", post.TranslationGroupID) {
+		return
+	}
+
+	// Set timestamps and initial version
 	now := time.Now()
 	post.CreatedAt = now
 	post.UpdatedAt = now
+	post.Version = 1
 
-	// Insert into MongoDB
+	// Insert into MongoDB, generating a slug from the title if not provided.
+	// Slugs are unique per language. A generated slug taken by a concurrent
+	// insert is generated again. The post and its events are committed together.
+	generateSlug := post.Slug == ""
 	collection := database.Database.Collection("posts")
-	result, err := collection.InsertOne(context.Background(), post)
+	var err error
+	for attempt := 1; ; attempt++ {
+		if generateSlug {
+			if post.Slug, err = uniquePostSlug(post.Title, post.Lang); err != nil {
+				log.Printf("[ERROR] CreatePost: Failed to generate slug - %s", err.Error())
+				apierrors.RespondFailedToCreatePost(c)
+				return
+			}
+		}
+		post.Slugs = []string{post.Slug}
+
+		err = database.WithTransaction(context.Background(), func(ctx context.Context) error {
+			post.ID = primitive.NilObjectID
+			result, err := collection.InsertOne(ctx, post)
+			if err != nil {
+				return err
+			}
+			post.ID = result.InsertedID.(primitive.ObjectID)
+			return enqueuePostEvents(ctx, nil, &post)
+		})
+		if err == nil || !generateSlug || !mongo.IsDuplicateKeyError(err) || isTranslationConflict(err) || attempt == maxSlugAttempts {
+			break
+		}
+		log.Printf("[INFO] CreatePost: Generated slug '%s' was taken concurrently, retrying", post.Slug)
+	}
 	if err != nil {
+		if isTranslationConflict(err) {
+			log.Printf("[ERROR] CreatePost: Translation group '%s' already has a post in '%s'", post.TranslationGroupID.Hex(), post.Lang)
+			apierrors.RespondTranslationAlreadyExists(c)
+			return
+		}
 		if mongo.IsDuplicateKeyError(err) {
-			log.Printf("[ERROR] CreatePost: Duplicate key error for slug '%s'", post.Slug)
+			log.Printf("[ERROR] CreatePost: Duplicate key error for slug '%s' in '%s'", post.Slug, post.Lang)
 			apierrors.RespondPostAlreadyExists(c)
 			return
 		}
@@ -55,8 +131,12 @@ func CreatePost(c *gin.Context) {
 		return
 	}
 
-	post.ID = result.InsertedID.(primitive.ObjectID)
+	recordPostAudit(c, audit.ActionPostCreate, post.ID, nil, &post)
+	publishPostEvents(nil, &post)
+	related.Schedule()
+
 	log.Printf("[SUCCESS] CreatePost: Created post with ID %s, title: '%s'", post.ID.Hex(), post.Title)
+	c.Header("ETag", postETag(post))
 	c.JSON(http.StatusCreated, post)
 }
 
@@ -64,28 +144,76 @@ func CreatePost(c *gin.Context) {
 func GetPosts(c *gin.Context) {
 	log.Printf("[INFO] GetPosts: Received request from %s", c.ClientIP())
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	published := c.Query("published")
+	filter := postListFilter(c)
 
-	if page < 1 {
-		page = 1
+	if authorSlug := c.Query("author"); authorSlug != "" {
+		author, ok := findAuthorBySlug(c, "GetPosts", authorSlug)
+		if !ok {
+			return
+		}
+		filter["author_ids"] = author.ID
 	}
-	if limit < 1 || limit > 100 {
-		limit = 10
+
+	if invalid := postDateFilter(c, filter); invalid != "" {
+		log.Printf("[ERROR] GetPosts: Invalid date filter - %s", invalid)
+		apierrors.RespondWithValidationError(c, invalid)
+		return
 	}
 
-	skip := (page - 1) * limit
+	respondWithPostPage(c, "GetPosts", filter)
+}
 
-	// Build filter
+// postListFilter returns the filter of GetPosts selected by the published, tag and
+// lang query parameters
+func postListFilter(c *gin.Context) bson.M {
 	filter := bson.M{}
-	switch published {
+	switch c.Query("published") {
 	case "true":
 		filter["published"] = true
 	case "false":
 		filter["published"] = false
 	}
 
+	if tag := c.Query("tag"); tag != "" {
+		filter["tags"] = tag
+	}
+
+	if lang := locale.Normalize(c.Query("lang")); lang != "" {
+		filter["lang"] = lang
+	}
+	return filter
+}
+
+// respondWithPostPage responds with the page of posts matching filter selected by the page and limit query parameters,
+// in the order of the sort parameter. Posts are listed without their content, along with their published translations,
+// unless the fields parameter selects other fields. The expand parameter embeds related data.
+func respondWithPostPage(c *gin.Context, handler string, filter bson.M) {
+	view, invalid := parsePostListView(c.Query("fields"), c.Query("expand"))
+	if invalid != "" {
+		log.Printf("[ERROR] %s: Invalid fields or expand parameter - %s", handler, invalid)
+		apierrors.RespondWithValidationError(c, invalid)
+		return
+	}
+
+	sort, invalid := parsePostSort(c.Query("sort"))
+	if invalid != "" {
+		log.Printf("[ERROR] %s: Invalid sort parameter - %s", handler, invalid)
+		apierrors.RespondWithValidationError(c, invalid)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	skip := (page - 1) * limit
+
 	collection := database.Database.Collection("posts")
 
 	// Get total count
@@ -99,11 +227,12 @@ func GetPosts(c *gin.Context) {
 	findOptions := options.Find()
 	findOptions.SetSkip(int64(skip))
 	findOptions.SetLimit(int64(limit))
-	findOptions.SetSort(bson.D{{Key: "created_at", Value: -1}}) // Sort by newest first
+	findOptions.SetSort(sort)
+	findOptions.SetProjection(view.projection())
 
 	cursor, err := collection.Find(context.Background(), filter, findOptions)
 	if err != nil {
-		log.Printf("[ERROR] GetPosts: Failed to find posts - %s", err.Error())
+		log.Printf("[ERROR] %s: Failed to find posts - %s", handler, err.Error())
 		apierrors.RespondFailedToFetchPosts(c)
 		return
 	}
@@ -111,21 +240,52 @@ func GetPosts(c *gin.Context) {
 
 	var posts []models.Post
 	if err = cursor.All(context.Background(), &posts); err != nil {
-		log.Printf("[ERROR] GetPosts: Failed to decode posts - %s", err.Error())
+		log.Printf("[ERROR] %s: Failed to decode posts - %s", handler, err.Error())
 		apierrors.RespondFailedToDecodePosts(c)
 		return
 	}
 
-	log.Printf("[SUCCESS] GetPosts: Retrieved %d posts (page %d, limit %d, total %d)", len(posts), page, limit, total)
+	// Posts without their translations are better than no posts, so failures are only logged
+	if view.selects("translations") {
+		if err := attachTranslations(posts); err != nil {
+			log.Printf("[ERROR] %s: Failed to fetch translations - %s", handler, err.Error())
+		}
+	}
+	if err := expandPosts(posts, view); err != nil {
+		log.Printf("[ERROR] %s: Failed to expand posts - %s", handler, err.Error())
+		apierrors.RespondFailedToFetchPosts(c)
+		return
+	}
+
+	etag := postsETag(posts, total)
+	if key := view.key(); key != "" {
+		etag = hashETag(etag + "|" + key)
+	}
+	if respondNotModified(c, etag, time.Time{}) {
+		log.Printf("[SUCCESS] %s: Posts not modified (page %d, limit %d, total %d)", handler, page, limit, total)
+		return
+	}
+
+	items := make([]interface{}, len(posts))
+	for i, post := range posts {
+		if items[i], err = view.render(post); err != nil {
+			log.Printf("[ERROR] %s: Failed to render post ID '%s' - %s", handler, post.ID.Hex(), err.Error())
+			apierrors.RespondFailedToDecodePosts(c)
+			return
+		}
+	}
+
+	log.Printf("[SUCCESS] %s: Retrieved %d posts (page %d, limit %d, total %d)", handler, len(posts), page, limit, total)
 	c.JSON(http.StatusOK, gin.H{
-		"posts": posts,
+		"posts": items,
 		"page":  page,
 		"limit": limit,
 		"total": total,
 	})
 }
 
-// GetPost retrieves a single blog post by ID or slug
+// GetPost retrieves a single blog post by ID or slug, in the language negotiated
+// from the lang query parameter or, for slugs, the Accept-Language header
 func GetPost(c *gin.Context) {
 	identifier := c.Param("id")
 	log.Printf("[INFO] GetPost: Received request for identifier '%s' from %s", identifier, c.ClientIP())
@@ -133,12 +293,35 @@ func GetPost(c *gin.Context) {
 	var post models.Post
 	collection := database.Database.Collection("posts")
 
-	// Try to parse as ObjectID first, then as slug
+	// Try to parse as ObjectID first, then as slug. An ID names a specific
+	// post, so only an explicit lang parameter switches to a translation.
 	var err error
+	var preferences []string
 	if objectID, parseErr := primitive.ObjectIDFromHex(identifier); parseErr == nil {
+		preferences = languagePreferences(c, false)
 		err = collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&post)
 	} else {
-		err = collection.FindOne(context.Background(), bson.M{"slug": identifier}).Decode(&post)
+		preferences = languagePreferences(c, true)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+
+		// Translations may share a slug, one per language
+		var posts []models.Post
+		var cursor *mongo.Cursor
+		cursor, err = collection.Find(context.Background(), bson.M{"slug": identifier},
+			options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+		if err == nil {
+			err = cursor.All(context.Background(), &posts)
+		}
+		if err == nil && len(posts) == 0 {
+			// The slug may have been renamed; redirect to the current one
+			if respondSlugRedirect(c, identifier, preferences) {
+				return
+			}
+			err = mongo.ErrNoDocuments
+		}
+		if err == nil {
+			post = preferredPost(posts, preferences)
+		}
 	}
 
 	if err != nil {
@@ -152,6 +335,23 @@ func GetPost(c *gin.Context) {
 		return
 	}
 
+	if post, err = negotiateTranslation(post, preferences); err != nil {
+		log.Printf("[ERROR] GetPost: Failed to fetch translations of post ID '%s' - %s", post.ID.Hex(), err.Error())
+		apierrors.RespondFailedToFetchPost(c)
+		return
+	}
+	c.Header("Content-Language", post.Lang)
+
+	// A missing series block is better than no post, so failures are only logged
+	if post.Series, err = postSeries(post.ID); err != nil {
+		log.Printf("[ERROR] GetPost: Failed to fetch series of post ID '%s' - %s", post.ID.Hex(), err.Error())
+	}
+
+	if respondNotModified(c, postETag(post), postLastModified(post)) {
+		log.Printf("[SUCCESS] GetPost: Post '%s' (ID: %s) not modified", post.Title, post.ID.Hex())
+		return
+	}
+
 	log.Printf("[SUCCESS] GetPost: Retrieved post '%s' (ID: %s)", post.Title, post.ID.Hex())
 	c.JSON(http.StatusOK, post)
 }
@@ -167,61 +367,115 @@ func UpdatePost(c *gin.Context) {
 		return
 	}
 
-	var updates models.Post
-	if err := c.ShouldBindJSON(&updates); err != nil {
+	var request postUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
 		log.Printf("[ERROR] UpdatePost: Validation failed for post ID '%s' - %s", id, err.Error())
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierrors.RespondWithBindingError(c, err)
+		return
+	}
+	updates := request.Post
+
+	expectedVersion, ok := expectedPostVersion(c, "UpdatePost", request.Version)
+	if !ok {
+		return
+	}
+
+	if !authorizePostChange(c, "UpdatePost", objectID, middleware.PermEditAnyPost) {
+		return
+	}
+
+	// An omitted language or translation group keeps the current one
+	var lang string
+	if updates.Lang != "" {
+		if lang, ok = postLanguage(c, "UpdatePost", updates.Lang); !ok {
+			return
+		}
+	}
+	if !updates.TranslationGroupID.IsZero() && !validateTranslationGroup(c, "UpdatePost", updates.TranslationGroupID) {
 		return
 	}
 
-	// Set updated timestamp
+	// Set updated timestamp and content metadata
 	updates.UpdatedAt = time.Now()
+	markdown.ApplyMetadata(&updates)
 
 	// Create update document (exclude ID and created_at)
+	setFields := bson.M{
+		"title":                updates.Title,
+		"content":              updates.Content,
+		"summary":              updates.Summary,
+		"tags":                 updates.Tags,
+		"published":            updates.Published,
+		"word_count":           updates.WordCount,
+		"reading_time_minutes": updates.ReadingTimeMinutes,
+		"toc":                  updates.TOC,
+		"updated_at":           updates.UpdatedAt,
+	}
+
+	// Keep the existing attribution unless authors are explicitly provided
+	// by a role allowed to edit any post
+	if updates.AuthorIDs != nil && middleware.Can(c, middleware.PermEditAnyPost) {
+		setFields["author_ids"] = updates.AuthorIDs
+	}
+	if lang != "" {
+		setFields["lang"] = lang
+	}
+	if !updates.TranslationGroupID.IsZero() {
+		setFields["translation_group_id"] = updates.TranslationGroupID
+	}
+
 	updateDoc := bson.M{
-		"$set": bson.M{
-			"title":      updates.Title,
-			"content":    updates.Content,
-			"slug":       updates.Slug,
-			"summary":    updates.Summary,
-			"tags":       updates.Tags,
-			"published":  updates.Published,
-			"updated_at": updates.UpdatedAt,
-		},
+		"$set": setFields,
+		"$inc": bson.M{"version": 1},
 	}
 
-	collection := database.Database.Collection("posts")
-	result, err := collection.UpdateOne(
-		context.Background(),
-		bson.M{"_id": objectID},
-		updateDoc,
-	)
+	// An omitted slug keeps the current one. Previous slugs stay in the
+	// history so that old links keep working.
+	if updates.Slug != "" {
+		setFields["slug"] = updates.Slug
+		updateDoc["$addToSet"] = bson.M{"slugs": updates.Slug}
+	}
+
+	// The version check is part of the filter so a concurrent update can't slip in between
+	filter := bson.M{"_id": objectID}
+	if expectedVersion != nil {
+		filter["version"] = versionFilter(*expectedVersion)
+	}
+
+	// Capture the previous version of the post for the audit log and fetch the
+	// updated one to return, committing the update with its events
+	var previousPost, updatedPost models.Post
+	err = updatePostWithEvents(objectID, filter, updateDoc, &previousPost, &updatedPost)
 
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			respondVersionMismatchOrNotFound(c, "UpdatePost", objectID)
+			return
+		}
+		if isTranslationConflict(err) {
+			log.Printf("[ERROR] UpdatePost: Translation group of post ID '%s' already has a post in its language", id)
+			apierrors.RespondTranslationAlreadyExists(c)
+			return
+		}
 		if mongo.IsDuplicateKeyError(err) {
 			log.Printf("[ERROR] UpdatePost: Duplicate key error for post ID '%s'", id)
 			apierrors.RespondPostAlreadyExists(c)
 			return
 		}
+		if errors.Is(err, errFetchUpdatedPost) {
+			log.Printf("[ERROR] UpdatePost: Failed to fetch updated post ID '%s' - %s", id, err.Error())
+			apierrors.RespondFailedToFetchUpdatedPost(c)
+			return
+		}
 		log.Printf("[ERROR] UpdatePost: Failed to update post ID '%s' - %s", id, err.Error())
 		apierrors.RespondFailedToUpdatePost(c)
 		return
 	}
 
-	if result.MatchedCount == 0 {
-		log.Printf("[ERROR] UpdatePost: Post not found for ID '%s'", id)
-		apierrors.RespondPostNotFound(c)
-		return
-	}
-
-	// Fetch and return updated post
-	var updatedPost models.Post
-	err = collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&updatedPost)
-	if err != nil {
-		log.Printf("[ERROR] UpdatePost: Failed to fetch updated post ID '%s' - %s", id, err.Error())
-		apierrors.RespondFailedToFetchUpdatedPost(c)
-		return
-	}
+	recordPostAudit(c, audit.ActionPostUpdate, objectID, &previousPost, &updatedPost)
+	publishPostEvents(&previousPost, &updatedPost)
+	related.Schedule()
+	c.Header("ETag", postETag(updatedPost))
 
 	log.Printf("[SUCCESS] UpdatePost: Updated post ID '%s', title: '%s'", id, updatedPost.Title)
 	c.JSON(http.StatusOK, updatedPost)
@@ -239,18 +493,40 @@ func DeletePost(c *gin.Context) {
 		return
 	}
 
+	if !authorizePostChange(c, "DeletePost", objectID, middleware.PermDeleteAnyPost) {
+		return
+	}
+
 	collection := database.Database.Collection("posts")
-	result, err := collection.DeleteOne(context.Background(), bson.M{"_id": objectID})
+	var deletedPost models.Post
+	err = database.WithTransaction(context.Background(), func(ctx context.Context) error {
+		if err := collection.FindOneAndDelete(ctx, bson.M{"_id": objectID}).Decode(&deletedPost); err != nil {
+			return err
+		}
+		return enqueuePostEvents(ctx, &deletedPost, nil)
+	})
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Printf("[ERROR] DeletePost: Post not found for ID '%s'", id)
+			apierrors.RespondPostNotFound(c)
+			return
+		}
 		log.Printf("[ERROR] DeletePost: Failed to delete post ID '%s' - %s", id, err.Error())
 		apierrors.RespondFailedToDeletePost(c)
 		return
 	}
 
-	if result.DeletedCount == 0 {
-		log.Printf("[ERROR] DeletePost: Post not found for ID '%s'", id)
-		apierrors.RespondPostNotFound(c)
-		return
+	recordPostAudit(c, audit.ActionPostDelete, objectID, &deletedPost, nil)
+	publishPostEvents(&deletedPost, nil)
+	related.Schedule()
+
+	// Remove the post from its series, leaving the other parts in order
+	_, err = database.Database.Collection("series").UpdateOne(context.Background(),
+		bson.M{"post_ids": objectID},
+		bson.M{"$pull": bson.M{"post_ids": objectID}, "$set": bson.M{"updated_at": time.Now()}},
+	)
+	if err != nil {
+		log.Printf("[ERROR] DeletePost: Failed to remove post ID '%s' from its series - %s", id, err.Error())
 	}
 
 	log.Printf("[SUCCESS] DeletePost: Successfully deleted post ID '%s'", id)
@@ -269,15 +545,22 @@ func LikePost(c *gin.Context) {
 		return
 	}
 
-	// Check if post exists and increment likes in one operation
+	// Check if post exists and increment likes in one operation, committed
+	// together with its webhook event
 	postsCollection := database.Database.Collection("posts")
 	var updatedPost models.Post
-	err = postsCollection.FindOneAndUpdate(
-		context.Background(),
-		bson.M{"_id": objectID},
-		bson.M{"$inc": bson.M{"likes": 1}},
-		options.FindOneAndUpdate().SetReturnDocument(options.After),
-	).Decode(&updatedPost)
+	err = database.WithTransaction(context.Background(), func(ctx context.Context) error {
+		err := postsCollection.FindOneAndUpdate(
+			ctx,
+			bson.M{"_id": objectID},
+			bson.M{"$inc": bson.M{"likes": 1}, "$set": bson.M{"counted_at": time.Now()}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&updatedPost)
+		if err != nil {
+			return err
+		}
+		return webhooks.Enqueue(ctx, webhooks.NewEvent(webhooks.EventPostLiked, updatedPost))
+	})
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
@@ -290,6 +573,8 @@ func LikePost(c *gin.Context) {
 		return
 	}
 
+	watch.Publish(webhooks.NewEvent(webhooks.EventPostLiked, updatedPost))
+
 	log.Printf("[SUCCESS] LikePost: Successfully liked post ID '%s', new count: %d", id, updatedPost.Likes)
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Post liked successfully",
@@ -318,7 +603,7 @@ func DislikePost(c *gin.Context) {
 			"_id":   objectID,
 			"likes": bson.M{"$gt": 0}, // Only update if likes > 0
 		},
-		bson.M{"$inc": bson.M{"likes": -1}},
+		bson.M{"$inc": bson.M{"likes": -1}, "$set": bson.M{"counted_at": time.Now()}},
 		options.FindOneAndUpdate().SetReturnDocument(options.After),
 	).Decode(&updatedPost)
 
@@ -394,6 +679,79 @@ func ViewPost(c *gin.Context) {
 	})
 }
 
+// errFetchUpdatedPost marks failures to read a post back after updating it
+var errFetchUpdatedPost = errors.New("failed to fetch updated post")
+
+// updatePostWithEvents applies an update to the post matching filter, decoding
+// the post before and after it. The update is committed together with the
+// events queued by enqueuePostEvents. A post that doesn't match is mongo.ErrNoDocuments.
+func updatePostWithEvents(postID primitive.ObjectID, filter, update bson.M, previousPost, updatedPost *models.Post) error {
+	collection := database.Database.Collection("posts")
+	return database.WithTransaction(context.Background(), func(ctx context.Context) error {
+		err := collection.FindOneAndUpdate(ctx, filter, update,
+			options.FindOneAndUpdate().SetReturnDocument(options.Before),
+		).Decode(previousPost)
+		if err != nil {
+			return err
+		}
+		if err := collection.FindOne(ctx, bson.M{"_id": postID}).Decode(updatedPost); err != nil {
+			return fmt.Errorf("%w: %v", errFetchUpdatedPost, err)
+		}
+		return enqueuePostEvents(ctx, previousPost, updatedPost)
+	})
+}
+
+// enqueuePostEvents queues the webhook events of a post write and, when it
+// publishes the post, its newsletter issue. Call it with the context of the
+// transaction making the write.
+func enqueuePostEvents(ctx context.Context, before, after *models.Post) error {
+	if err := webhooks.EnqueuePostEvents(ctx, before, after); err != nil {
+		return err
+	}
+	return newsletter.EnqueuePost(ctx, before, after)
+}
+
+// publishPostEvents broadcasts the events of a committed post write to the
+// watchers of this process, such as gRPC WatchPosts streams
+func publishPostEvents(before, after *models.Post) {
+	watch.Publish(webhooks.PostEvents(before, after)...)
+}
+
+// authorizePostChange verifies that the request may modify a post. Roles without
+// anyPermission may only modify posts attributed to the author mapped to their API key.
+func authorizePostChange(c *gin.Context, handler string, postID primitive.ObjectID, anyPermission middleware.Permission) bool {
+	if middleware.Can(c, anyPermission) {
+		return true
+	}
+
+	var post models.Post
+	collection := database.Database.Collection("posts")
+	err := collection.FindOne(context.Background(), bson.M{"_id": postID}).Decode(&post)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Printf("[ERROR] %s: Post not found for ID '%s'", handler, postID.Hex())
+			apierrors.RespondPostNotFound(c)
+			return false
+		}
+		log.Printf("[ERROR] %s: Failed to fetch post ID '%s' - %s", handler, postID.Hex(), err.Error())
+		apierrors.RespondFailedToFetchPost(c)
+		return false
+	}
+
+	if author, ok := authorForRequest(c); ok {
+		for _, authorID := range post.AuthorIDs {
+			if authorID == author.ID {
+				return true
+			}
+		}
+	}
+
+	log.Printf("[SECURITY] %s: API key of %s is not an author of post ID '%s'", handler, c.ClientIP(), postID.Hex())
+	recordOwnershipFailure(c, postID)
+	apierrors.RespondNotPostOwner(c)
+	return false
+}
+
 // incrementPostViews tracks post views and returns the updated count
 func incrementPostViews(postID primitive.ObjectID, ipAddress, userAgent string) int64 {
 	// Create view record
@@ -417,7 +775,7 @@ func incrementPostViews(postID primitive.ObjectID, ipAddress, userAgent string)
 	err = postsCollection.FindOneAndUpdate(
 		context.Background(),
 		bson.M{"_id": postID},
-		bson.M{"$inc": bson.M{"views": 1}},
+		bson.M{"$inc": bson.M{"views": 1}, "$set": bson.M{"counted_at": time.Now()}},
 		options.FindOneAndUpdate().SetReturnDocument(options.After),
 	).Decode(&updatedPost)
 	if err != nil {
@@ -428,16 +786,53 @@ func incrementPostViews(postID primitive.ObjectID, ipAddress, userAgent string)
 	return updatedPost.Views
 }
 
-// generateSlug creates a URL-friendly slug from a title
-func generateSlug(title string) string {
-	slug := strings.ToLower(title)
-	slug = strings.ReplaceAll(slug, " ", "-")
-	// Remove special characters (basic implementation)
-	slug = strings.ReplaceAll(slug, "'", "")
-	slug = strings.ReplaceAll(slug, "\"", "")
-	slug = strings.ReplaceAll(slug, ".", "")
-	slug = strings.ReplaceAll(slug, ",", "")
-	slug = strings.ReplaceAll(slug, "!", "")
-	slug = strings.ReplaceAll(slug, "?", "")
-	return slug
+// maxSlugAttempts bounds retries when a generated slug is taken by a concurrent insert
+const maxSlugAttempts = 3
+
+// uniquePostSlug generates a slug from title that no post in lang uses or has used
+// before, appending -2, -3, ... when the generated slug is taken
+func uniquePostSlug(title, lang string) (string, error) {
+	base := slug.Generate(title)
+	collection := database.Database.Collection("posts")
+
+	// Fetch every slug of the form base or base-N in one query
+	pattern := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(base) + "(-[0-9]+)?$"}
+	cursor, err := collection.Find(
+		context.Background(),
+		bson.M{"lang": lang, "$or": bson.A{bson.M{"slug": pattern}, bson.M{"slugs": pattern}}},
+		options.Find().SetProjection(bson.M{"slug": 1, "slugs": 1}),
+	)
+	if err != nil {
+		return "", err
+	}
+	var posts []models.Post
+	if err := cursor.All(context.Background(), &posts); err != nil {
+		return "", err
+	}
+
+	taken := make(map[string]bool)
+	for _, post := range posts {
+		taken[post.Slug] = true
+		for _, previous := range post.Slugs {
+			taken[previous] = true
+		}
+	}
+
+	for n := 1; ; n++ {
+		candidate := slug.WithSuffix(base, n)
+		if taken[candidate] {
+			continue
+		}
+		// Candidates near the length cap shorten base, so the query above didn't cover them
+		if !strings.HasPrefix(candidate, base) {
+			count, err := collection.CountDocuments(context.Background(), bson.M{"lang": lang, "$or": bson.A{bson.M{"slug": candidate}, bson.M{"slugs": candidate}}})
+			if err != nil {
+				return "", err
+			}
+			if count > 0 {
+				continue
+			}
+		}
+		return candidate, nil
+	}
 }
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"dbl-blog-backend/apierrors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Unit tests for post handler helpers
+
+func TestPostListFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	filter := func(target string) bson.M {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		return postListFilter(c)
+	}
+
+	assert.Equal(t, bson.M{}, filter("/api/v1/posts"))
+	assert.Equal(t, bson.M{"tags": "go"}, filter("/api/v1/posts?tag=go"))
+	assert.Equal(t, bson.M{"published": true, "tags": "go", "lang": "pt-br"}, filter("/api/v1/posts?published=true&tag=go&lang=PT_BR"))
+	assert.Equal(t, bson.M{"published": false}, filter("/api/v1/posts?published=false&tag="))
+}
+
+func TestUpdatePost_FieldErrorsMatchBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PUT("/api/v1/posts/:id", UpdatePost)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/posts/"+primitive.NewObjectID().Hex(), strings.NewReader(`{"version":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response apierrors.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	fields := make([]string, 0, len(response.Error.Errors))
+	for _, fieldError := range response.Error.Errors {
+		fields = append(fields, fieldError.Field)
+	}
+	assert.Equal(t, []string{"title", "content"}, fields, "Fields of the embedded post should be named as in the body")
+}
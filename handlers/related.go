@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/models"
+	"dbl-blog-backend/related"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Limits on the number of related posts returned
+const (
+	defaultRelatedLimit = 5
+	maxRelatedLimit     = related.MaxCandidates
+)
+
+// relatedPost is a published post recommended as related, with its ranking score
+type relatedPost struct {
+	models.Post
+	Score float64 `json:"score"`
+}
+
+// GetRelatedPosts returns the published posts most related to a post, identified
+// by ID or slug. Similarities are precomputed by the related posts worker and
+// weighted for recency here, so newer posts gain over time without a rebuild.
+func GetRelatedPosts(c *gin.Context) {
+	identifier := c.Param("id")
+	log.Printf("[INFO] GetRelatedPosts: Received request for identifier '%s' from %s", identifier, c.ClientIP())
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultRelatedLimit)))
+	if err != nil || limit < 1 || limit > maxRelatedLimit {
+		apierrors.RespondWithValidationError(c, "limit must be between 1 and "+strconv.Itoa(maxRelatedLimit))
+		return
+	}
+
+	// Translations may share a slug; the one in the preferred language is used
+	filter := bson.M{"slug": identifier}
+	if objectID, parseErr := primitive.ObjectIDFromHex(identifier); parseErr == nil {
+		filter = bson.M{"_id": objectID}
+	}
+
+	var matches []models.Post
+	collection := database.Database.Collection("posts")
+	cursor, err := collection.Find(context.Background(), filter, options.Find().SetProjection(bson.M{"_id": 1, "lang": 1}))
+	if err == nil {
+		err = cursor.All(context.Background(), &matches)
+	}
+	if err == nil && len(matches) == 0 {
+		err = mongo.ErrNoDocuments
+	}
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Printf("[ERROR] GetRelatedPosts: Post not found for identifier '%s'", identifier)
+			apierrors.RespondPostNotFound(c)
+			return
+		}
+		log.Printf("[ERROR] GetRelatedPosts: Failed to fetch post for identifier '%s' - %s", identifier, err.Error())
+		apierrors.RespondFailedToFetchPost(c)
+		return
+	}
+	post := preferredPost(matches, languagePreferences(c, true))
+
+	candidates, err := related.Candidates(context.Background(), post.ID)
+	if err != nil {
+		log.Printf("[ERROR] GetRelatedPosts: Failed to fetch candidates of post ID '%s' - %s", post.ID.Hex(), err.Error())
+		apierrors.RespondFailedToFetchRelatedPosts(c)
+		return
+	}
+
+	posts, err := publishedRelatedPosts(candidates)
+	if err != nil {
+		log.Printf("[ERROR] GetRelatedPosts: Failed to fetch related posts of post ID '%s' - %s", post.ID.Hex(), err.Error())
+		apierrors.RespondFailedToFetchRelatedPosts(c)
+		return
+	}
+
+	result := rankRelatedPosts(candidates, posts, time.Now(), limit)
+
+	log.Printf("[SUCCESS] GetRelatedPosts: Retrieved %d related posts for post ID '%s'", len(result), post.ID.Hex())
+	c.JSON(http.StatusOK, gin.H{
+		"posts": result,
+		"total": len(result),
+	})
+}
+
+// RebuildRelatedPosts recomputes the related posts of all published posts right
+// away, instead of waiting for the background worker
+func RebuildRelatedPosts(c *gin.Context) {
+	log.Printf("[INFO] RebuildRelatedPosts: Received request from %s", c.ClientIP())
+
+	indexed, err := related.Rebuild(c.Request.Context())
+	if err != nil {
+		log.Printf("[ERROR] RebuildRelatedPosts: Failed to rebuild related posts - %s", err.Error())
+		apierrors.RespondFailedToRebuildRelatedPosts(c)
+		return
+	}
+
+	log.Printf("[SUCCESS] RebuildRelatedPosts: Indexed %d published posts", indexed)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Related posts rebuilt successfully",
+		"indexed": indexed,
+	})
+}
+
+// publishedRelatedPosts fetches the candidate posts that are still published, without their content
+func publishedRelatedPosts(candidates []related.Candidate) ([]models.Post, error) {
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	ids := make([]primitive.ObjectID, len(candidates))
+	for i, candidate := range candidates {
+		ids[i] = candidate.PostID
+	}
+
+	collection := database.Database.Collection("posts")
+	cursor, err := collection.Find(context.Background(),
+		bson.M{"_id": bson.M{"$in": ids}, "published": true},
+		options.Find().SetProjection(bson.M{"content": 0}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	var posts []models.Post
+	if err := cursor.All(context.Background(), &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// rankRelatedPosts orders the fetched posts by their recency-weighted candidate
+// score. Candidates whose post wasn't fetched, because it was unpublished or
+// deleted since the last rebuild, are left out.
+func rankRelatedPosts(candidates []related.Candidate, posts []models.Post, now time.Time, limit int) []relatedPost {
+	byID := make(map[primitive.ObjectID]models.Post, len(posts))
+	for _, post := range posts {
+		byID[post.ID] = post
+	}
+
+	available := make([]related.Candidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if post, ok := byID[candidate.PostID]; ok {
+			// The stored date may predate a re-dated import
+			candidate.CreatedAt = post.CreatedAt
+			available = append(available, candidate)
+		}
+	}
+
+	ranked := related.Rank(available, now, limit)
+	result := make([]relatedPost, len(ranked))
+	for i, candidate := range ranked {
+		result[i] = relatedPost{Post: byID[candidate.PostID], Score: candidate.Similarity}
+	}
+	return result
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"dbl-blog-backend/models"
+	"dbl-blog-backend/related"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Unit tests for related posts helpers
+
+func TestRankRelatedPosts(t *testing.T) {
+	now := time.Now()
+	posts := []models.Post{
+		{ID: primitive.NewObjectID(), Slug: "older", CreatedAt: now.Add(-related.RecencyHalfLife)},
+		{ID: primitive.NewObjectID(), Slug: "newer", CreatedAt: now},
+	}
+	candidates := []related.Candidate{
+		{PostID: posts[0].ID, Similarity: 0.6, CreatedAt: now},
+		{PostID: primitive.NewObjectID(), Similarity: 0.9, CreatedAt: now},
+		{PostID: posts[1].ID, Similarity: 0.3, CreatedAt: now},
+	}
+
+	result := rankRelatedPosts(candidates, posts, now, 5)
+	if assert.Len(t, result, 2, "Candidates that are no longer published are left out") {
+		assert.Equal(t, "older", result[0].Slug)
+		assert.InDelta(t, 0.45, result[0].Score, 1e-9, "The post's own date is used for recency")
+		assert.Equal(t, "newer", result[1].Slug)
+	}
+
+	assert.Len(t, rankRelatedPosts(candidates, posts, now, 1), 1)
+	assert.Empty(t, rankRelatedPosts(nil, nil, now, 5))
+}
@@ -0,0 +1,428 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/models"
+	"dbl-blog-backend/slug"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// seriesDetail is a series with its published parts, as returned by GetSeries
+type seriesDetail struct {
+	models.Series
+	Parts []models.SeriesPart `json:"parts"`
+}
+
+// CreateSeries creates a new series of posts
+func CreateSeries(c *gin.Context) {
+	log.Printf("[INFO] CreateSeries: Received request from %s", c.ClientIP())
+
+	var series models.Series
+	if err := c.ShouldBindJSON(&series); err != nil {
+		log.Printf("[ERROR] CreateSeries: Validation failed - %s", err.Error())
+		apierrors.RespondWithBindingError(c, err)
+		return
+	}
+	if series.Slug == "" {
+		series.Slug = slug.Generate(series.Title)
+	}
+	if series.PostIDs == nil {
+		series.PostIDs = []primitive.ObjectID{}
+	}
+	if !validateSeriesPosts(c, "CreateSeries", primitive.NilObjectID, series.PostIDs) {
+		return
+	}
+
+	now := time.Now()
+	series.CreatedAt = now
+	series.UpdatedAt = now
+
+	collection := database.Database.Collection("series")
+	result, err := collection.InsertOne(context.Background(), series)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			log.Printf("[ERROR] CreateSeries: Duplicate key error for slug '%s'", series.Slug)
+			apierrors.RespondSeriesAlreadyExists(c)
+			return
+		}
+		log.Printf("[ERROR] CreateSeries: Failed to insert series - %s", err.Error())
+		apierrors.RespondFailedToCreateSeries(c)
+		return
+	}
+
+	series.ID = result.InsertedID.(primitive.ObjectID)
+	log.Printf("[SUCCESS] CreateSeries: Created series with ID %s, title: '%s', %d parts", series.ID.Hex(), series.Title, len(series.PostIDs))
+	c.JSON(http.StatusCreated, series)
+}
+
+// GetSeriesList retrieves all series, without their parts
+func GetSeriesList(c *gin.Context) {
+	log.Printf("[INFO] GetSeriesList: Received request from %s", c.ClientIP())
+
+	collection := database.Database.Collection("series")
+	cursor, err := collection.Find(context.Background(), bson.M{}, options.Find().SetSort(bson.D{{Key: "title", Value: 1}}))
+	if err != nil {
+		log.Printf("[ERROR] GetSeriesList: Failed to find series - %s", err.Error())
+		apierrors.RespondFailedToFetchSeries(c)
+		return
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	seriesList := []models.Series{}
+	if err = cursor.All(context.Background(), &seriesList); err != nil {
+		log.Printf("[ERROR] GetSeriesList: Failed to decode series - %s", err.Error())
+		apierrors.RespondFailedToFetchSeries(c)
+		return
+	}
+
+	// Drafts are not public, so only published parts are listed
+	published, err := publishedPostIDs(seriesList)
+	if err != nil {
+		log.Printf("[ERROR] GetSeriesList: Failed to fetch series parts - %s", err.Error())
+		apierrors.RespondFailedToFetchSeries(c)
+		return
+	}
+	for i := range seriesList {
+		visible := []primitive.ObjectID{}
+		for _, id := range seriesList[i].PostIDs {
+			if published[id] {
+				visible = append(visible, id)
+			}
+		}
+		seriesList[i].PostIDs = visible
+	}
+
+	log.Printf("[SUCCESS] GetSeriesList: Retrieved %d series", len(seriesList))
+	c.JSON(http.StatusOK, gin.H{
+		"series": seriesList,
+		"total":  len(seriesList),
+	})
+}
+
+// GetSeries retrieves a series by slug with its published parts in order
+func GetSeries(c *gin.Context) {
+	seriesSlug := c.Param("slug")
+	log.Printf("[INFO] GetSeries: Received request for slug '%s' from %s", seriesSlug, c.ClientIP())
+
+	var series models.Series
+	collection := database.Database.Collection("series")
+	err := collection.FindOne(context.Background(), bson.M{"slug": seriesSlug}).Decode(&series)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Printf("[ERROR] GetSeries: Series not found for slug '%s'", seriesSlug)
+			apierrors.RespondSeriesNotFound(c)
+			return
+		}
+		log.Printf("[ERROR] GetSeries: Failed to fetch series for slug '%s' - %s", seriesSlug, err.Error())
+		apierrors.RespondFailedToFetchSeries(c)
+		return
+	}
+
+	posts, err := findSeriesPosts(series)
+	if err != nil {
+		log.Printf("[ERROR] GetSeries: Failed to fetch parts of series '%s' - %s", seriesSlug, err.Error())
+		apierrors.RespondFailedToFetchSeries(c)
+		return
+	}
+
+	detail := seriesDetail{Series: series, Parts: seriesParts(series, posts, primitive.NilObjectID)}
+	detail.PostIDs = make([]primitive.ObjectID, 0, len(detail.Parts))
+	for _, part := range detail.Parts {
+		detail.PostIDs = append(detail.PostIDs, part.ID)
+	}
+
+	log.Printf("[SUCCESS] GetSeries: Retrieved series '%s' with %d published parts", series.Title, len(detail.Parts))
+	c.JSON(http.StatusOK, detail)
+}
+
+// UpdateSeries replaces the title, slug, description and parts of a series
+func UpdateSeries(c *gin.Context) {
+	id := c.Param("id")
+	log.Printf("[INFO] UpdateSeries: Received request for series ID '%s' from %s", id, c.ClientIP())
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		apierrors.RespondInvalidSeriesID(c)
+		return
+	}
+
+	var updates models.Series
+	if err := c.ShouldBindJSON(&updates); err != nil {
+		log.Printf("[ERROR] UpdateSeries: Validation failed for series ID '%s' - %s", id, err.Error())
+		apierrors.RespondWithBindingError(c, err)
+		return
+	}
+	if updates.Slug == "" {
+		updates.Slug = slug.Generate(updates.Title)
+	}
+	if updates.PostIDs == nil {
+		updates.PostIDs = []primitive.ObjectID{}
+	}
+	if !validateSeriesPosts(c, "UpdateSeries", objectID, updates.PostIDs) {
+		return
+	}
+
+	collection := database.Database.Collection("series")
+	var updatedSeries models.Series
+	err = collection.FindOneAndUpdate(
+		context.Background(),
+		bson.M{"_id": objectID},
+		bson.M{"$set": bson.M{
+			"title":       updates.Title,
+			"slug":        updates.Slug,
+			"description": updates.Description,
+			"post_ids":    updates.PostIDs,
+			"updated_at":  time.Now(),
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updatedSeries)
+
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Printf("[ERROR] UpdateSeries: Series not found for ID '%s'", id)
+			apierrors.RespondSeriesNotFound(c)
+			return
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			log.Printf("[ERROR] UpdateSeries: Duplicate key error for series ID '%s'", id)
+			apierrors.RespondSeriesAlreadyExists(c)
+			return
+		}
+		log.Printf("[ERROR] UpdateSeries: Failed to update series ID '%s' - %s", id, err.Error())
+		apierrors.RespondFailedToUpdateSeries(c)
+		return
+	}
+
+	log.Printf("[SUCCESS] UpdateSeries: Updated series ID '%s', title: '%s', %d parts", id, updatedSeries.Title, len(updatedSeries.PostIDs))
+	c.JSON(http.StatusOK, updatedSeries)
+}
+
+// DeleteSeries deletes a series. Its posts are kept.
+func DeleteSeries(c *gin.Context) {
+	id := c.Param("id")
+	log.Printf("[INFO] DeleteSeries: Received request to delete series ID '%s' from %s", id, c.ClientIP())
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		log.Printf("[ERROR] DeleteSeries: Invalid series ID format '%s'", id)
+		apierrors.RespondInvalidSeriesID(c)
+		return
+	}
+
+	collection := database.Database.Collection("series")
+	result, err := collection.DeleteOne(context.Background(), bson.M{"_id": objectID})
+	if err != nil {
+		log.Printf("[ERROR] DeleteSeries: Failed to delete series ID '%s' - %s", id, err.Error())
+		apierrors.RespondFailedToDeleteSeries(c)
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		log.Printf("[ERROR] DeleteSeries: Series not found for ID '%s'", id)
+		apierrors.RespondSeriesNotFound(c)
+		return
+	}
+
+	log.Printf("[SUCCESS] DeleteSeries: Successfully deleted series ID '%s'", id)
+	c.JSON(http.StatusOK, gin.H{"message": "Series deleted successfully"})
+}
+
+// validateSeriesPosts checks that the parts of a series are distinct existing posts
+// that belong to no other series, responding with an error if they are not
+func validateSeriesPosts(c *gin.Context, handler string, seriesID primitive.ObjectID, postIDs []primitive.ObjectID) bool {
+	seen := make(map[primitive.ObjectID]bool, len(postIDs))
+	for _, id := range postIDs {
+		if seen[id] {
+			apierrors.RespondWithValidationError(c, "post '"+id.Hex()+"' is listed more than once")
+			return false
+		}
+		seen[id] = true
+	}
+	if len(postIDs) == 0 {
+		return true
+	}
+
+	count, err := database.Database.Collection("posts").CountDocuments(context.Background(), bson.M{"_id": bson.M{"$in": postIDs}})
+	if err != nil {
+		log.Printf("[ERROR] %s: Failed to count series posts - %s", handler, err.Error())
+		apierrors.RespondFailedToFetchPosts(c)
+		return false
+	}
+	if count != int64(len(postIDs)) {
+		log.Printf("[ERROR] %s: %d of %d series posts do not exist", handler, int64(len(postIDs))-count, len(postIDs))
+		apierrors.RespondWithValidationError(c, "post_ids must only reference existing posts")
+		return false
+	}
+
+	var other models.Series
+	err = database.Database.Collection("series").FindOne(context.Background(), bson.M{
+		"_id":      bson.M{"$ne": seriesID},
+		"post_ids": bson.M{"$in": postIDs},
+	}).Decode(&other)
+	if err == nil {
+		log.Printf("[ERROR] %s: Posts already belong to series '%s'", handler, other.Slug)
+		apierrors.RespondPostInAnotherSeries(c, "Some of the posts already belong to the series '"+other.Slug+"'")
+		return false
+	}
+	if err != mongo.ErrNoDocuments {
+		log.Printf("[ERROR] %s: Failed to check series membership - %s", handler, err.Error())
+		apierrors.RespondFailedToFetchSeries(c)
+		return false
+	}
+	return true
+}
+
+// findSeriesPosts fetches the titles, slugs and publication state of the parts of a series
+func findSeriesPosts(series models.Series) ([]models.Post, error) {
+	posts := []models.Post{}
+	if len(series.PostIDs) == 0 {
+		return posts, nil
+	}
+
+	cursor, err := database.Database.Collection("posts").Find(context.Background(),
+		bson.M{"_id": bson.M{"$in": series.PostIDs}},
+		options.Find().SetProjection(bson.M{"title": 1, "slug": 1, "summary": 1, "published": 1, "created_at": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	err = cursor.All(context.Background(), &posts)
+	return posts, err
+}
+
+// publishedPostIDs returns the set of published posts among the parts of several series
+func publishedPostIDs(seriesList []models.Series) (map[primitive.ObjectID]bool, error) {
+	var ids []primitive.ObjectID
+	for _, series := range seriesList {
+		ids = append(ids, series.PostIDs...)
+	}
+	published := make(map[primitive.ObjectID]bool, len(ids))
+	if len(ids) == 0 {
+		return published, nil
+	}
+
+	cursor, err := database.Database.Collection("posts").Find(context.Background(),
+		bson.M{"_id": bson.M{"$in": ids}, "published": true},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	var posts []models.Post
+	if err := cursor.All(context.Background(), &posts); err != nil {
+		return nil, err
+	}
+	for _, post := range posts {
+		published[post.ID] = true
+	}
+	return published, nil
+}
+
+// seriesParts lists the parts of a series in order and numbers them. Unpublished
+// posts are left out, except for include, so that drafts don't leave gaps.
+func seriesParts(series models.Series, posts []models.Post, include primitive.ObjectID) []models.SeriesPart {
+	byID := make(map[primitive.ObjectID]models.Post, len(posts))
+	for _, post := range posts {
+		byID[post.ID] = post
+	}
+
+	parts := []models.SeriesPart{}
+	for _, id := range series.PostIDs {
+		post, ok := byID[id]
+		if !ok || (!post.Published && id != include) {
+			continue
+		}
+		parts = append(parts, models.SeriesPart{
+			Part:      len(parts) + 1,
+			ID:        post.ID,
+			Title:     post.Title,
+			Slug:      post.Slug,
+			Summary:   post.Summary,
+			CreatedAt: post.CreatedAt,
+		})
+	}
+	return parts
+}
+
+// buildPostSeries describes the position of a post within its series, with links
+// to the previous and next parts
+func buildPostSeries(series models.Series, posts []models.Post, postID primitive.ObjectID) *models.PostSeries {
+	parts := seriesParts(series, posts, postID)
+	for i, part := range parts {
+		if part.ID != postID {
+			continue
+		}
+		postSeries := &models.PostSeries{
+			ID:    series.ID,
+			Title: series.Title,
+			Slug:  series.Slug,
+			Part:  part.Part,
+			Total: len(parts),
+		}
+		if i > 0 {
+			previous := parts[i-1]
+			previous.Summary = ""
+			postSeries.Previous = &previous
+		}
+		if i < len(parts)-1 {
+			next := parts[i+1]
+			next.Summary = ""
+			postSeries.Next = &next
+		}
+		return postSeries
+	}
+	return nil
+}
+
+// postSeries returns the series block of a post, or nil if it belongs to no series
+func postSeries(postID primitive.ObjectID) (*models.PostSeries, error) {
+	var series models.Series
+	err := database.Database.Collection("series").FindOne(context.Background(), bson.M{"post_ids": postID}).Decode(&series)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := findSeriesPosts(series)
+	if err != nil {
+		return nil, err
+	}
+	return buildPostSeries(series, posts, postID), nil
+}
+
+// seriesETagPart summarizes a series block for the ETag of a post, so that cached
+// copies are revalidated when the series or the neighbouring parts change
+func seriesETagPart(postSeries *models.PostSeries) string {
+	if postSeries == nil {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "|%s|%s|%s|%d/%d", postSeries.ID.Hex(), postSeries.Title, postSeries.Slug, postSeries.Part, postSeries.Total)
+	for _, link := range []*models.SeriesPart{postSeries.Previous, postSeries.Next} {
+		if link != nil {
+			fmt.Fprintf(&b, "|%s|%s|%s", link.ID.Hex(), link.Title, link.Slug)
+		} else {
+			b.WriteString("|-")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"testing"
+
+	"dbl-blog-backend/models"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Unit tests for series helpers
+
+func seriesFixture() (models.Series, []models.Post) {
+	posts := []models.Post{
+		{ID: primitive.NewObjectID(), Title: "Part One", Slug: "part-one", Published: true},
+		{ID: primitive.NewObjectID(), Title: "Part Two (draft)", Slug: "part-two", Published: false},
+		{ID: primitive.NewObjectID(), Title: "Part Three", Slug: "part-three", Published: true},
+		{ID: primitive.NewObjectID(), Title: "Part Four", Slug: "part-four", Published: true},
+	}
+	series := models.Series{
+		ID:    primitive.NewObjectID(),
+		Title: "Go Tutorial",
+		Slug:  "go-tutorial",
+		// Stored order differs from the order posts are fetched in
+		PostIDs: []primitive.ObjectID{posts[0].ID, posts[1].ID, posts[3].ID, posts[2].ID},
+	}
+	return series, posts
+}
+
+func TestSeriesParts(t *testing.T) {
+	series, posts := seriesFixture()
+
+	parts := seriesParts(series, posts, primitive.NilObjectID)
+	if assert.Len(t, parts, 3, "Drafts are left out") {
+		assert.Equal(t, "part-one", parts[0].Slug)
+		assert.Equal(t, "part-four", parts[1].Slug, "Parts follow the series order")
+		assert.Equal(t, 2, parts[1].Part, "Parts are numbered without gaps")
+		assert.Equal(t, "part-three", parts[2].Slug)
+	}
+
+	parts = seriesParts(series, posts, posts[1].ID)
+	assert.Len(t, parts, 4, "The included draft is listed")
+
+	series.PostIDs = append(series.PostIDs, primitive.NewObjectID())
+	assert.Len(t, seriesParts(series, posts, primitive.NilObjectID), 3, "Missing posts are skipped")
+}
+
+func TestBuildPostSeries(t *testing.T) {
+	series, posts := seriesFixture()
+
+	first := buildPostSeries(series, posts, posts[0].ID)
+	if assert.NotNil(t, first) {
+		assert.Equal(t, 1, first.Part)
+		assert.Equal(t, 3, first.Total)
+		assert.Nil(t, first.Previous)
+		if assert.NotNil(t, first.Next) {
+			assert.Equal(t, "part-four", first.Next.Slug)
+		}
+	}
+
+	middle := buildPostSeries(series, posts, posts[3].ID)
+	if assert.NotNil(t, middle) {
+		assert.Equal(t, 2, middle.Part)
+		assert.Equal(t, "part-one", middle.Previous.Slug)
+		assert.Equal(t, "part-three", middle.Next.Slug)
+	}
+
+	draft := buildPostSeries(series, posts, posts[1].ID)
+	if assert.NotNil(t, draft) {
+		assert.Equal(t, 2, draft.Part, "A draft sees its own position")
+		assert.Equal(t, 4, draft.Total)
+	}
+
+	assert.Nil(t, buildPostSeries(series, posts, primitive.NewObjectID()))
+}
+
+func TestPostETag_IncludesSeries(t *testing.T) {
+	series, posts := seriesFixture()
+	post := posts[0]
+	post.Version = 3
+
+	withoutSeries := postETag(post)
+	post.Series = buildPostSeries(series, posts, post.ID)
+	withSeries := postETag(post)
+	assert.NotEqual(t, withoutSeries, withSeries)
+
+	version, ok := versionFromETag(withSeries)
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), version, "The version stays readable for If-Match")
+
+	post.Series.Next.Title = "Renamed"
+	assert.NotEqual(t, withSeries, postETag(post), "Renaming a neighbouring part changes the ETag")
+}
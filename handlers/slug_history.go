@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/audit"
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/middleware"
+	"dbl-blog-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// respondSlugRedirect answers a request for a previous slug of a post with a 301
+// pointing at the post's current slug. When posts in several languages used the
+// slug, the one best fitting the language preferences is chosen. The body also
+// carries the canonical slug for clients that don't follow redirects. It returns
+// false, without responding, when no post has ever used the slug.
+func respondSlugRedirect(c *gin.Context, slug string, preferences []string) bool {
+	var posts []models.Post
+	collection := database.Database.Collection("posts")
+	cursor, err := collection.Find(context.Background(), bson.M{"slugs": slug},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err == nil {
+		err = cursor.All(context.Background(), &posts)
+	}
+	if err != nil {
+		log.Printf("[ERROR] GetPost: Failed to look up slug history for '%s' - %s", slug, err.Error())
+		apierrors.RespondFailedToFetchPost(c)
+		return true
+	}
+	if len(posts) == 0 {
+		return false
+	}
+	post := preferredPost(posts, preferences)
+
+	location := canonicalPostURL(c, post.Slug)
+	log.Printf("[SUCCESS] GetPost: Redirecting previous slug '%s' to '%s' (ID: %s)", slug, post.Slug, post.ID.Hex())
+	c.Header("Location", location)
+	c.JSON(http.StatusMovedPermanently, gin.H{
+		"id":       post.ID,
+		"slug":     post.Slug,
+		"location": location,
+	})
+	return true
+}
+
+// canonicalPostURL builds the URL of the current request's route for slug, keeping the query string
+func canonicalPostURL(c *gin.Context, slug string) string {
+	location := strings.Replace(c.FullPath(), ":id", url.PathEscape(slug), 1)
+	if c.Request.URL.RawQuery != "" {
+		location += "?" + c.Request.URL.RawQuery
+	}
+	return location
+}
+
+// slugAliases returns the previous slugs of a post, excluding its current slug
+func slugAliases(post models.Post) []string {
+	aliases := []string{}
+	for _, slug := range post.Slugs {
+		if slug != post.Slug {
+			aliases = append(aliases, slug)
+		}
+	}
+	return aliases
+}
+
+// GetPostSlugs lists the previous slugs of a post that redirect to its current slug
+func GetPostSlugs(c *gin.Context) {
+	id := c.Param("id")
+	log.Printf("[INFO] GetPostSlugs: Received request for post ID '%s' from %s", id, c.ClientIP())
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		apierrors.RespondInvalidPostID(c)
+		return
+	}
+
+	if !authorizePostChange(c, "GetPostSlugs", objectID, middleware.PermEditAnyPost) {
+		return
+	}
+
+	var post models.Post
+	collection := database.Database.Collection("posts")
+	err = collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&post)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Printf("[ERROR] GetPostSlugs: Post not found for ID '%s'", id)
+			apierrors.RespondPostNotFound(c)
+			return
+		}
+		log.Printf("[ERROR] GetPostSlugs: Failed to fetch post ID '%s' - %s", id, err.Error())
+		apierrors.RespondFailedToFetchPost(c)
+		return
+	}
+
+	aliases := slugAliases(post)
+	log.Printf("[SUCCESS] GetPostSlugs: Retrieved %d slug aliases for post ID '%s'", len(aliases), id)
+	c.JSON(http.StatusOK, gin.H{
+		"id":      post.ID,
+		"slug":    post.Slug,
+		"aliases": aliases,
+	})
+}
+
+// DeletePostSlugs prunes previous slugs of a post so they stop redirecting and become
+// available to other posts. With a slug parameter only that alias is removed,
+// otherwise all of them are.
+func DeletePostSlugs(c *gin.Context) {
+	id := c.Param("id")
+	alias := c.Param("slug")
+	log.Printf("[INFO] DeletePostSlugs: Received request for post ID '%s', alias '%s' from %s", id, alias, c.ClientIP())
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		apierrors.RespondInvalidPostID(c)
+		return
+	}
+
+	if !authorizePostChange(c, "DeletePostSlugs", objectID, middleware.PermEditAnyPost) {
+		return
+	}
+
+	var post models.Post
+	collection := database.Database.Collection("posts")
+	err = collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&post)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Printf("[ERROR] DeletePostSlugs: Post not found for ID '%s'", id)
+			apierrors.RespondPostNotFound(c)
+			return
+		}
+		log.Printf("[ERROR] DeletePostSlugs: Failed to fetch post ID '%s' - %s", id, err.Error())
+		apierrors.RespondFailedToFetchPost(c)
+		return
+	}
+
+	var update bson.M
+	if alias != "" {
+		if alias == post.Slug {
+			log.Printf("[ERROR] DeletePostSlugs: Refusing to remove current slug '%s' of post ID '%s'", alias, id)
+			apierrors.RespondCannotRemoveCurrentSlug(c)
+			return
+		}
+		found := false
+		for _, slug := range post.Slugs {
+			found = found || slug == alias
+		}
+		if !found {
+			log.Printf("[ERROR] DeletePostSlugs: Alias '%s' not found for post ID '%s'", alias, id)
+			apierrors.RespondSlugAliasNotFound(c)
+			return
+		}
+		update = bson.M{"$pull": bson.M{"slugs": alias}}
+	} else {
+		update = bson.M{"$set": bson.M{"slugs": []string{post.Slug}}}
+	}
+
+	// Matching the current slug keeps a concurrent rename from losing its new slug
+	var updatedPost models.Post
+	err = collection.FindOneAndUpdate(
+		context.Background(),
+		bson.M{"_id": objectID, "slug": post.Slug},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updatedPost)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Printf("[ERROR] DeletePostSlugs: Post ID '%s' was renamed concurrently", id)
+			apierrors.RespondPreconditionFailed(c)
+			return
+		}
+		log.Printf("[ERROR] DeletePostSlugs: Failed to update post ID '%s' - %s", id, err.Error())
+		apierrors.RespondFailedToUpdatePost(c)
+		return
+	}
+
+	recordPostAudit(c, audit.ActionPostUpdate, objectID, &post, &updatedPost)
+
+	aliases := slugAliases(updatedPost)
+	log.Printf("[SUCCESS] DeletePostSlugs: Pruned slug aliases of post ID '%s', %d remaining", id, len(aliases))
+	c.JSON(http.StatusOK, gin.H{
+		"id":      updatedPost.ID,
+		"slug":    updatedPost.Slug,
+		"aliases": aliases,
+	})
+}
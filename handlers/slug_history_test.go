@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"dbl-blog-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// Unit tests for slug history helpers
+
+func TestSlugAliases(t *testing.T) {
+	post := models.Post{Slug: "current", Slugs: []string{"first", "current", "second"}}
+	assert.Equal(t, []string{"first", "second"}, slugAliases(post))
+
+	assert.Equal(t, []string{}, slugAliases(models.Post{Slug: "legacy"}), "Posts without history have no aliases")
+}
+
+func TestCanonicalPostURL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var location string
+	router := gin.New()
+	router.GET("/api/v1/posts/:id", func(c *gin.Context) {
+		location = canonicalPostURL(c, "new slug")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/posts/old-slug?preview=1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "/api/v1/posts/new%20slug?preview=1", location)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"dbl-blog-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// defaultPostSort lists posts newest first
+const defaultPostSort = "-created_at"
+
+// parsePostSort parses the sort parameter of post lists: one of
+// models.PostSortFields for ascending order, or prefixed with "-" for descending
+// order. Ties are broken on _id in the same direction, so that pages are stable.
+// It returns an error message naming the accepted values for unknown fields.
+func parsePostSort(value string) (bson.D, string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		value = defaultPostSort
+	}
+
+	direction := 1
+	field := value
+	if strings.HasPrefix(value, "-") {
+		direction = -1
+		field = value[1:]
+	}
+
+	for _, sortable := range models.PostSortFields {
+		if field == sortable {
+			return bson.D{{Key: field, Value: direction}, {Key: "_id", Value: direction}}, ""
+		}
+	}
+	return nil, "sort must be one of " + strings.Join(models.PostSortFields, ", ") + ", optionally prefixed with '-' for descending order"
+}
+
+// postDateFilter adds the created_after, created_before and updated_since query
+// parameters (RFC 3339 timestamps) to filter. It returns an error message for
+// invalid timestamps.
+func postDateFilter(c *gin.Context, filter bson.M) string {
+	ranges := []struct {
+		param, field, operator string
+	}{
+		{"created_after", "created_at", "$gt"},
+		{"created_before", "created_at", "$lt"},
+		{"updated_since", "updated_at", "$gte"},
+	}
+
+	for _, r := range ranges {
+		value := c.Query(r.param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return "'" + r.param + "' must be an RFC 3339 timestamp"
+		}
+		condition, _ := filter[r.field].(bson.M)
+		if condition == nil {
+			condition = bson.M{}
+			filter[r.field] = condition
+		}
+		condition[r.operator] = parsed
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Unit tests for sorting and date filters of post lists
+
+func TestParsePostSort(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bson.D
+	}{
+		{"", bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{"views", bson.D{{Key: "views", Value: 1}, {Key: "_id", Value: 1}}},
+		{"-likes", bson.D{{Key: "likes", Value: -1}, {Key: "_id", Value: -1}}},
+		{" title", bson.D{{Key: "title", Value: 1}, {Key: "_id", Value: 1}}}, // An unescaped "+" decodes to a space
+		{"-updated_at", bson.D{{Key: "updated_at", Value: -1}, {Key: "_id", Value: -1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			sort, invalid := parsePostSort(tt.value)
+			assert.Empty(t, invalid)
+			assert.Equal(t, tt.expected, sort)
+		})
+	}
+
+	for _, value := range []string{"content", "-", "--views", "_id"} {
+		_, invalid := parsePostSort(value)
+		assert.Contains(t, invalid, "sort must be one of created_at, updated_at, views, likes, title", value)
+	}
+}
+
+func TestPostDateFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(query string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/posts?"+query, nil)
+		return c
+	}
+
+	filter := bson.M{"published": true}
+	invalid := postDateFilter(newContext("created_after=2024-01-01T00:00:00Z&created_before=2024-02-01T00:00:00Z&updated_since=2024-03-01T12:00:00%2B02:00"), filter)
+	assert.Empty(t, invalid)
+	assert.Equal(t, bson.M{
+		"published": true,
+		"created_at": bson.M{
+			"$gt": time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			"$lt": time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"updated_at": bson.M{"$gte": time.Date(2024, 3, 1, 12, 0, 0, 0, time.FixedZone("", 2*60*60))},
+	}, filter)
+
+	filter = bson.M{}
+	assert.Empty(t, postDateFilter(newContext(""), filter))
+	assert.Empty(t, filter, "Filters are only added when given")
+
+	assert.Equal(t, "'created_after' must be an RFC 3339 timestamp", postDateFilter(newContext("created_after=yesterday"), bson.M{}))
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/locale"
+	"dbl-blog-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// postLanguage normalizes the language of a post being written, defaulting to
+// DEFAULT_LANGUAGE. It responds with a validation error and returns false when
+// the language isn't one of SUPPORTED_LANGUAGES.
+func postLanguage(c *gin.Context, handler, lang string) (string, bool) {
+	lang = locale.Normalize(lang)
+	if lang == "" {
+		return locale.Default(), true
+	}
+	if !locale.IsSupported(lang) {
+		log.Printf("[ERROR] %s: Unsupported language '%s'", handler, lang)
+		apierrors.RespondWithValidationError(c, "lang must be one of: "+strings.Join(locale.Supported(), ", "))
+		return "", false
+	}
+	return lang, true
+}
+
+// validateTranslationGroup verifies that a post joining a translation group
+// translates an existing post. It responds with an error and returns false otherwise.
+func validateTranslationGroup(c *gin.Context, handler string, groupID primitive.ObjectID) bool {
+	collection := database.Database.Collection("posts")
+	count, err := collection.CountDocuments(context.Background(), bson.M{"translation_group_id": groupID}, options.Count().SetLimit(1))
+	if err != nil {
+		log.Printf("[ERROR] %s: Failed to look up translation group '%s' - %s", handler, groupID.Hex(), err.Error())
+		apierrors.RespondFailedToFetchPost(c)
+		return false
+	}
+	if count == 0 {
+		log.Printf("[ERROR] %s: Translation group '%s' not found", handler, groupID.Hex())
+		apierrors.RespondTranslationGroupNotFound(c)
+		return false
+	}
+	return true
+}
+
+// isTranslationConflict reports whether a write failed because the translation
+// group already has a post in the same language
+func isTranslationConflict(err error) bool {
+	return mongo.IsDuplicateKeyError(err) && strings.Contains(err.Error(), "translation_group_id")
+}
+
+// languagePreferences returns the languages a request asks for, most preferred
+// first: the lang query parameter, or else the Accept-Language header when
+// acceptHeader is true
+func languagePreferences(c *gin.Context, acceptHeader bool) []string {
+	if lang := locale.Normalize(c.Query("lang")); lang != "" {
+		return []string{lang}
+	}
+	if acceptHeader {
+		return locale.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	}
+	return nil
+}
+
+// preferredPost picks the post whose language best fits the preferences among
+// posts sharing a slug, falling back to the default language and then to the
+// first post
+func preferredPost(posts []models.Post, preferences []string) models.Post {
+	if post, ok := matchPost(posts, preferences); ok {
+		return post
+	}
+	if post, ok := matchPost(posts, []string{locale.Default()}); ok {
+		return post
+	}
+	return posts[0]
+}
+
+// matchPost returns the post whose language best fits the preferences, or
+// false when no preference matches
+func matchPost(posts []models.Post, preferences []string) (models.Post, bool) {
+	languages := make([]string, len(posts))
+	for i, post := range posts {
+		languages[i] = post.Lang
+	}
+	lang, ok := locale.Match(preferences, languages)
+	if !ok {
+		return models.Post{}, false
+	}
+	for _, post := range posts {
+		if post.Lang == lang {
+			return post, true
+		}
+	}
+	return models.Post{}, false
+}
+
+// negotiateTranslation returns the translation of post whose language best fits
+// the preferences, among post and its published translations, with its other
+// published translations listed. Without a better fit, post itself is returned.
+func negotiateTranslation(post models.Post, preferences []string) (models.Post, error) {
+	if post.TranslationGroupID.IsZero() {
+		return post, nil
+	}
+
+	collection := database.Database.Collection("posts")
+	cursor, err := collection.Find(context.Background(),
+		bson.M{"translation_group_id": post.TranslationGroupID, "_id": bson.M{"$ne": post.ID}, "published": true},
+		options.Find().SetSort(bson.D{{Key: "lang", Value: 1}}),
+	)
+	if err != nil {
+		return post, err
+	}
+	var translations []models.Post
+	if err := cursor.All(context.Background(), &translations); err != nil {
+		return post, err
+	}
+
+	candidates := append([]models.Post{post}, translations...)
+	chosen := post
+	if match, ok := matchPost(candidates, preferences); ok {
+		chosen = match
+	}
+
+	chosen.Translations = translationsOf(chosen, candidates)
+	return chosen, nil
+}
+
+// attachTranslations lists the published translations of each post of a page,
+// fetching the translation groups of all posts in one query
+func attachTranslations(posts []models.Post) error {
+	groupIDs := make([]primitive.ObjectID, 0, len(posts))
+	for _, post := range posts {
+		if !post.TranslationGroupID.IsZero() {
+			groupIDs = append(groupIDs, post.TranslationGroupID)
+		}
+	}
+	if len(groupIDs) == 0 {
+		return nil
+	}
+
+	members, err := findTranslationGroups(groupIDs)
+	if err != nil {
+		return err
+	}
+	for i := range posts {
+		posts[i].Translations = translationsOf(posts[i], members)
+	}
+	return nil
+}
+
+// findTranslationGroups fetches the published posts of translation groups, with
+// only the fields listed as translations
+func findTranslationGroups(groupIDs []primitive.ObjectID) ([]models.Post, error) {
+	collection := database.Database.Collection("posts")
+	cursor, err := collection.Find(context.Background(),
+		bson.M{"translation_group_id": bson.M{"$in": groupIDs}, "published": true},
+		options.Find().
+			SetProjection(bson.M{"lang": 1, "slug": 1, "title": 1, "published": 1, "translation_group_id": 1}).
+			SetSort(bson.D{{Key: "lang", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var members []models.Post
+	if err := cursor.All(context.Background(), &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// translationsOf lists the published posts of post's translation group among
+// candidates, excluding post itself and any other post in its language
+func translationsOf(post models.Post, candidates []models.Post) []models.PostTranslation {
+	var translations []models.PostTranslation
+	for _, candidate := range candidates {
+		if !candidate.Published || candidate.TranslationGroupID != post.TranslationGroupID ||
+			candidate.ID == post.ID || candidate.Lang == post.Lang {
+			continue
+		}
+		translations = append(translations, models.PostTranslation{
+			ID:    candidate.ID,
+			Lang:  candidate.Lang,
+			Slug:  candidate.Slug,
+			Title: candidate.Title,
+		})
+	}
+	return translations
+}
+
+// translationsETagPart summarizes the translations of a post for its ETag, so
+// that cached copies are revalidated when a translation is published or renamed
+func translationsETagPart(translations []models.PostTranslation) string {
+	var b strings.Builder
+	for _, translation := range translations {
+		fmt.Fprintf(&b, "|%s|%s|%s|%s", translation.ID.Hex(), translation.Lang, translation.Slug, translation.Title)
+	}
+	return b.String()
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"dbl-blog-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Unit tests for translation helpers
+
+func TestPreferredPost(t *testing.T) {
+	t.Setenv("DEFAULT_LANGUAGE", "en")
+	posts := []models.Post{
+		{ID: primitive.NewObjectID(), Lang: "pt", Slug: "go"},
+		{ID: primitive.NewObjectID(), Lang: "en", Slug: "go"},
+	}
+
+	assert.Equal(t, posts[0].ID, preferredPost(posts, []string{"pt-br", "en"}).ID)
+	assert.Equal(t, posts[1].ID, preferredPost(posts, []string{"fr"}).ID, "Unmatched preferences fall back to the default language")
+	assert.Equal(t, posts[1].ID, preferredPost(posts, nil).ID)
+	assert.Equal(t, posts[0].ID, preferredPost(posts[:1], []string{"en"}).ID, "A single post is always chosen")
+}
+
+func TestTranslationsOf(t *testing.T) {
+	group := primitive.NewObjectID()
+	post := models.Post{ID: primitive.NewObjectID(), TranslationGroupID: group, Lang: "en", Published: true}
+	candidates := []models.Post{
+		post,
+		{ID: primitive.NewObjectID(), TranslationGroupID: group, Lang: "pt", Slug: "ola", Title: "Olá", Published: true},
+		{ID: primitive.NewObjectID(), TranslationGroupID: group, Lang: "fr", Slug: "salut", Published: false},
+		{ID: primitive.NewObjectID(), TranslationGroupID: primitive.NewObjectID(), Lang: "pt", Slug: "outro", Published: true},
+	}
+
+	translations := translationsOf(post, candidates)
+	if assert.Len(t, translations, 1, "Drafts, other groups and the post itself are left out") {
+		assert.Equal(t, models.PostTranslation{ID: candidates[1].ID, Lang: "pt", Slug: "ola", Title: "Olá"}, translations[0])
+	}
+	assert.NotEqual(t, translationsETagPart(nil), translationsETagPart(translations))
+}
+
+func TestLanguagePreferences(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		url          string
+		acceptHeader bool
+		expected     []string
+	}{
+		{"query parameter wins", "/posts/go?lang=PT", true, []string{"pt"}},
+		{"Accept-Language", "/posts/go", true, []string{"pt-br", "en"}},
+		{"Accept-Language ignored", "/posts/go", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, tt.url, nil)
+			c.Request.Header.Set("Accept-Language", "pt-BR, en;q=0.8")
+			assert.Equal(t, tt.expected, languagePreferences(c, tt.acceptHeader))
+		})
+	}
+}
+
+func TestPostLanguage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("DEFAULT_LANGUAGE", "en")
+	t.Setenv("SUPPORTED_LANGUAGES", "en,pt")
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	lang, ok := postLanguage(c, "Test", "")
+	assert.True(t, ok)
+	assert.Equal(t, "en", lang)
+
+	lang, ok = postLanguage(c, "Test", " PT ")
+	assert.True(t, ok)
+	assert.Equal(t, "pt", lang)
+
+	w := httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	_, ok = postLanguage(c, "Test", "fr")
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "en, pt")
+}
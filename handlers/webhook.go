@@ -0,0 +1,408 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/models"
+	"dbl-blog-backend/webhooks"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// webhookRequest is the body of webhook create and update requests
+type webhookRequest struct {
+	URL          string   `json:"url" binding:"required,url,max=2048"`
+	Events       []string `json:"events" binding:"required,min=1,max=5"`
+	Description  string   `json:"description" binding:"max=500"`
+	Active       *bool    `json:"active"`                                    // Defaults to true on create; omitted keeps the current state on update
+	Secret       string   `json:"secret" binding:"omitempty,min=16,max=200"` // Generated when omitted on create
+	RotateSecret bool     `json:"rotate_secret"`                             // Update only: replace the secret with a generated one
+}
+
+// webhookWithSecret is a webhook along with its signing secret, returned only
+// when the secret is created or rotated
+type webhookWithSecret struct {
+	models.Webhook
+	Secret string `json:"secret,omitempty"`
+}
+
+// CreateWebhook registers an endpoint for post lifecycle events. The response
+// includes the signing secret, which is not returned again.
+func CreateWebhook(c *gin.Context) {
+	log.Printf("[INFO] CreateWebhook: Received request from %s", c.ClientIP())
+
+	var request webhookRequest
+	if !bindWebhookRequest(c, "CreateWebhook", &request) {
+		return
+	}
+
+	secret := request.Secret
+	if secret == "" {
+		var err error
+		if secret, err = webhooks.GenerateSecret(); err != nil {
+			log.Printf("[ERROR] CreateWebhook: Failed to generate secret - %s", err.Error())
+			apierrors.RespondFailedToCreateWebhook(c)
+			return
+		}
+	}
+
+	now := time.Now()
+	webhook := models.Webhook{
+		URL:         request.URL,
+		Events:      request.Events,
+		Description: request.Description,
+		Active:      request.Active == nil || *request.Active,
+		Secret:      secret,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	collection := database.Database.Collection(webhooks.CollectionName)
+	result, err := collection.InsertOne(context.Background(), webhook)
+	if err != nil {
+		log.Printf("[ERROR] CreateWebhook: Failed to insert webhook - %s", err.Error())
+		apierrors.RespondFailedToCreateWebhook(c)
+		return
+	}
+
+	webhook.ID = result.InsertedID.(primitive.ObjectID)
+	log.Printf("[SUCCESS] CreateWebhook: Created webhook with ID %s for events %v", webhook.ID.Hex(), webhook.Events)
+	c.JSON(http.StatusCreated, webhookWithSecret{Webhook: webhook, Secret: secret})
+}
+
+// GetWebhooks lists all webhooks
+func GetWebhooks(c *gin.Context) {
+	log.Printf("[INFO] GetWebhooks: Received request from %s", c.ClientIP())
+
+	collection := database.Database.Collection(webhooks.CollectionName)
+	cursor, err := collection.Find(context.Background(), bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		log.Printf("[ERROR] GetWebhooks: Failed to find webhooks - %s", err.Error())
+		apierrors.RespondFailedToFetchWebhooks(c)
+		return
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	list := []models.Webhook{}
+	if err = cursor.All(context.Background(), &list); err != nil {
+		log.Printf("[ERROR] GetWebhooks: Failed to decode webhooks - %s", err.Error())
+		apierrors.RespondFailedToFetchWebhooks(c)
+		return
+	}
+
+	log.Printf("[SUCCESS] GetWebhooks: Retrieved %d webhooks", len(list))
+	c.JSON(http.StatusOK, gin.H{
+		"webhooks": list,
+		"total":    len(list),
+	})
+}
+
+// GetWebhook retrieves a webhook by ID
+func GetWebhook(c *gin.Context) {
+	id := c.Param("id")
+	log.Printf("[INFO] GetWebhook: Received request for webhook ID '%s' from %s", id, c.ClientIP())
+
+	webhook, ok := findWebhook(c, "GetWebhook", id)
+	if !ok {
+		return
+	}
+
+	log.Printf("[SUCCESS] GetWebhook: Retrieved webhook ID '%s'", id)
+	c.JSON(http.StatusOK, webhook)
+}
+
+// UpdateWebhook replaces the URL, events and description of a webhook. Setting
+// active to true re-enables a webhook disabled after repeated failures, and
+// rotate_secret replaces its signing secret, returning the new one.
+func UpdateWebhook(c *gin.Context) {
+	id := c.Param("id")
+	log.Printf("[INFO] UpdateWebhook: Received request for webhook ID '%s' from %s", id, c.ClientIP())
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		apierrors.RespondInvalidWebhookID(c)
+		return
+	}
+
+	var request webhookRequest
+	if !bindWebhookRequest(c, "UpdateWebhook", &request) {
+		return
+	}
+
+	now := time.Now()
+	setFields := bson.M{
+		"url":         request.URL,
+		"events":      request.Events,
+		"description": request.Description,
+		"updated_at":  now,
+	}
+	update := bson.M{"$set": setFields}
+	if request.Active != nil {
+		setFields["active"] = *request.Active
+		if *request.Active {
+			setFields["consecutive_failures"] = 0
+			update["$unset"] = bson.M{"disabled_at": "", "disabled_reason": ""}
+		}
+	}
+
+	var secret string
+	if request.RotateSecret || request.Secret != "" {
+		secret = request.Secret
+		if secret == "" {
+			if secret, err = webhooks.GenerateSecret(); err != nil {
+				log.Printf("[ERROR] UpdateWebhook: Failed to generate secret - %s", err.Error())
+				apierrors.RespondFailedToUpdateWebhook(c)
+				return
+			}
+		}
+		setFields["secret"] = secret
+	}
+
+	collection := database.Database.Collection(webhooks.CollectionName)
+	var updated models.Webhook
+	err = collection.FindOneAndUpdate(
+		context.Background(),
+		bson.M{"_id": objectID},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Printf("[ERROR] UpdateWebhook: Webhook not found for ID '%s'", id)
+			apierrors.RespondWebhookNotFound(c)
+			return
+		}
+		log.Printf("[ERROR] UpdateWebhook: Failed to update webhook ID '%s' - %s", id, err.Error())
+		apierrors.RespondFailedToUpdateWebhook(c)
+		return
+	}
+
+	log.Printf("[SUCCESS] UpdateWebhook: Updated webhook ID '%s' (active: %t, secret rotated: %t)", id, updated.Active, secret != "")
+	c.JSON(http.StatusOK, webhookWithSecret{Webhook: updated, Secret: secret})
+}
+
+// DeleteWebhook deletes a webhook and its delivery log
+func DeleteWebhook(c *gin.Context) {
+	id := c.Param("id")
+	log.Printf("[INFO] DeleteWebhook: Received request to delete webhook ID '%s' from %s", id, c.ClientIP())
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		log.Printf("[ERROR] DeleteWebhook: Invalid webhook ID format '%s'", id)
+		apierrors.RespondInvalidWebhookID(c)
+		return
+	}
+
+	result, err := database.Database.Collection(webhooks.CollectionName).DeleteOne(context.Background(), bson.M{"_id": objectID})
+	if err != nil {
+		log.Printf("[ERROR] DeleteWebhook: Failed to delete webhook ID '%s' - %s", id, err.Error())
+		apierrors.RespondFailedToDeleteWebhook(c)
+		return
+	}
+	if result.DeletedCount == 0 {
+		log.Printf("[ERROR] DeleteWebhook: Webhook not found for ID '%s'", id)
+		apierrors.RespondWebhookNotFound(c)
+		return
+	}
+
+	// Pending deliveries would be cancelled by the dispatcher anyway
+	_, err = database.Database.Collection(webhooks.DeliveriesCollectionName).DeleteMany(context.Background(), bson.M{"webhook_id": objectID})
+	if err != nil {
+		log.Printf("[ERROR] DeleteWebhook: Failed to delete deliveries of webhook ID '%s' - %s", id, err.Error())
+	}
+
+	log.Printf("[SUCCESS] DeleteWebhook: Successfully deleted webhook ID '%s'", id)
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// GetWebhookDeliveries lists the deliveries of a webhook, newest first, with
+// every attempt made. Filter: status.
+func GetWebhookDeliveries(c *gin.Context) {
+	id := c.Param("id")
+	log.Printf("[INFO] GetWebhookDeliveries: Received request for webhook ID '%s' from %s", id, c.ClientIP())
+
+	webhook, ok := findWebhook(c, "GetWebhookDeliveries", id)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filter := bson.M{"webhook_id": webhook.ID}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+
+	collection := database.Database.Collection(webhooks.DeliveriesCollectionName)
+	total, err := collection.CountDocuments(context.Background(), filter)
+	if err != nil {
+		log.Printf("[ERROR] GetWebhookDeliveries: Failed to count deliveries - %s", err.Error())
+		apierrors.RespondFailedToFetchWebhookDeliveries(c)
+		return
+	}
+
+	findOptions := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	cursor, err := collection.Find(context.Background(), filter, findOptions)
+	if err != nil {
+		log.Printf("[ERROR] GetWebhookDeliveries: Failed to find deliveries - %s", err.Error())
+		apierrors.RespondFailedToFetchWebhookDeliveries(c)
+		return
+	}
+	defer func() { _ = cursor.Close(context.Background()) }()
+
+	deliveries := []models.WebhookDelivery{}
+	if err = cursor.All(context.Background(), &deliveries); err != nil {
+		log.Printf("[ERROR] GetWebhookDeliveries: Failed to decode deliveries - %s", err.Error())
+		apierrors.RespondFailedToFetchWebhookDeliveries(c)
+		return
+	}
+
+	log.Printf("[SUCCESS] GetWebhookDeliveries: Retrieved %d deliveries of webhook ID '%s' (page %d, limit %d, total %d)", len(deliveries), id, page, limit, total)
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+		"page":       page,
+		"limit":      limit,
+		"total":      total,
+	})
+}
+
+// RedeliverWebhookDelivery queues a new delivery of the same event with the same
+// payload, whatever the outcome of the original one
+func RedeliverWebhookDelivery(c *gin.Context) {
+	id := c.Param("id")
+	deliveryID := c.Param("delivery_id")
+	log.Printf("[INFO] RedeliverWebhookDelivery: Received request for delivery '%s' of webhook ID '%s' from %s", deliveryID, id, c.ClientIP())
+
+	webhook, ok := findWebhook(c, "RedeliverWebhookDelivery", id)
+	if !ok {
+		return
+	}
+	if !webhook.Active {
+		log.Printf("[ERROR] RedeliverWebhookDelivery: Webhook ID '%s' is disabled", id)
+		apierrors.RespondWebhookDisabled(c)
+		return
+	}
+
+	deliveryObjectID, err := primitive.ObjectIDFromHex(deliveryID)
+	if err != nil {
+		apierrors.RespondWebhookDeliveryNotFound(c)
+		return
+	}
+
+	collection := database.Database.Collection(webhooks.DeliveriesCollectionName)
+	var original models.WebhookDelivery
+	err = collection.FindOne(context.Background(), bson.M{"_id": deliveryObjectID, "webhook_id": webhook.ID}).Decode(&original)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Printf("[ERROR] RedeliverWebhookDelivery: Delivery '%s' not found for webhook ID '%s'", deliveryID, id)
+			apierrors.RespondWebhookDeliveryNotFound(c)
+			return
+		}
+		log.Printf("[ERROR] RedeliverWebhookDelivery: Failed to fetch delivery '%s' - %s", deliveryID, err.Error())
+		apierrors.RespondFailedToFetchWebhookDeliveries(c)
+		return
+	}
+
+	now := time.Now()
+	delivery := models.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		EventID:       original.EventID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        webhooks.StatusPending,
+		Attempts:      []models.WebhookAttempt{},
+		NextAttemptAt: &now,
+		RedeliveryOf:  &original.ID,
+		CreatedAt:     now,
+	}
+	result, err := collection.InsertOne(context.Background(), delivery)
+	if err != nil {
+		log.Printf("[ERROR] RedeliverWebhookDelivery: Failed to queue redelivery of '%s' - %s", deliveryID, err.Error())
+		apierrors.RespondFailedToRedeliverWebhook(c)
+		return
+	}
+
+	delivery.ID = result.InsertedID.(primitive.ObjectID)
+	log.Printf("[SUCCESS] RedeliverWebhookDelivery: Queued delivery '%s' as redelivery of '%s'", delivery.ID.Hex(), deliveryID)
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// bindWebhookRequest binds and validates a webhook request, removing duplicate events
+func bindWebhookRequest(c *gin.Context, handler string, request *webhookRequest) bool {
+	if err := c.ShouldBindJSON(request); err != nil {
+		log.Printf("[ERROR] %s: Validation failed - %s", handler, err.Error())
+		apierrors.RespondWithBindingError(c, err)
+		return false
+	}
+
+	if target, err := url.Parse(request.URL); err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		log.Printf("[ERROR] %s: Invalid webhook URL '%s'", handler, request.URL)
+		apierrors.RespondWithValidationError(c, "url must be an absolute http or https URL")
+		return false
+	}
+
+	seen := make(map[string]bool, len(request.Events))
+	events := make([]string, 0, len(request.Events))
+	for _, event := range request.Events {
+		if !webhooks.ValidEvent(event) {
+			log.Printf("[ERROR] %s: Unknown event '%s'", handler, event)
+			apierrors.RespondWithValidationError(c, "unknown event '"+event+"', expected one of "+strings.Join(webhooks.Events, ", "))
+			return false
+		}
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+	request.Events = events
+	return true
+}
+
+// findWebhook fetches a webhook by its hex ID, responding with an error when it can't
+func findWebhook(c *gin.Context, handler, id string) (models.Webhook, bool) {
+	var webhook models.Webhook
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		log.Printf("[ERROR] %s: Invalid webhook ID format '%s'", handler, id)
+		apierrors.RespondInvalidWebhookID(c)
+		return webhook, false
+	}
+
+	err = database.Database.Collection(webhooks.CollectionName).FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&webhook)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			log.Printf("[ERROR] %s: Webhook not found for ID '%s'", handler, id)
+			apierrors.RespondWebhookNotFound(c)
+			return webhook, false
+		}
+		log.Printf("[ERROR] %s: Failed to fetch webhook ID '%s' - %s", handler, id, err.Error())
+		apierrors.RespondFailedToFetchWebhooks(c)
+		return webhook, false
+	}
+	return webhook, true
+}
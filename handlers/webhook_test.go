@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// Unit tests for webhook request validation
+
+func TestBindWebhookRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	testCases := []struct {
+		name     string
+		body     string
+		valid    bool
+		expected []string
+	}{
+		{"valid", `{"url":"https://example.com/hook","events":["post.created","post.deleted"]}`, true, []string{"post.created", "post.deleted"}},
+		{"duplicate events are removed", `{"url":"http://example.com/hook","events":["post.liked","post.liked"]}`, true, []string{"post.liked"}},
+		{"unknown event", `{"url":"https://example.com/hook","events":["post.viewed"]}`, false, nil},
+		{"no events", `{"url":"https://example.com/hook","events":[]}`, false, nil},
+		{"missing URL", `{"events":["post.created"]}`, false, nil},
+		{"non-HTTP URL", `{"url":"ftp://example.com/hook","events":["post.created"]}`, false, nil},
+		{"short secret", `{"url":"https://example.com/hook","events":["post.created"],"secret":"short"}`, false, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/admin/webhooks", strings.NewReader(tc.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			var request webhookRequest
+			valid := bindWebhookRequest(c, "TestBindWebhookRequest", &request)
+
+			assert.Equal(t, tc.valid, valid)
+			if tc.valid {
+				assert.Equal(t, tc.expected, request.Events)
+			} else {
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
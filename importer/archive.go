@@ -0,0 +1,140 @@
+package importer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Limits guarding against oversized archives and decompression bombs
+const (
+	MaxFileBytes  = 1 << 20  // Largest single file read from an archive
+	MaxTotalBytes = 64 << 20 // Largest total size of all files read from an archive
+	MaxFiles      = 10000    // Most files read from an archive
+)
+
+// ErrUnsupportedArchive is returned for data that is not a zip, tar or tar.gz archive
+var ErrUnsupportedArchive = errors.New("unsupported archive format, expected zip, tar or tar.gz")
+
+// File is a Markdown file read from an archive
+type File struct {
+	Name string
+	Data []byte
+}
+
+// ReadArchive extracts the Markdown files (.md and .markdown) from a zip, tar or
+// tar.gz archive, detecting the format from its content. Other files are skipped.
+func ReadArchive(data []byte) ([]File, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")) || bytes.HasPrefix(data, []byte("PK\x05\x06")):
+		return readZip(data)
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip data: %w", err)
+		}
+		defer func() { _ = gzipReader.Close() }()
+		return readTar(gzipReader)
+	case len(data) > 262 && string(data[257:262]) == "ustar":
+		return readTar(bytes.NewReader(data))
+	default:
+		return nil, ErrUnsupportedArchive
+	}
+}
+
+// readZip extracts Markdown files from a zip archive
+func readZip(data []byte) ([]File, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+
+	var files []File
+	var total int64
+	for _, entry := range zipReader.File {
+		if entry.FileInfo().IsDir() || !isMarkdownFile(entry.Name) {
+			continue
+		}
+		reader, err := entry.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open '%s': %w", entry.Name, err)
+		}
+		content, err := readLimited(reader, entry.Name, &total)
+		_ = reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		if files, err = appendFile(files, entry.Name, content); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// readTar extracts Markdown files from a tar stream
+func readTar(r io.Reader) ([]File, error) {
+	tarReader := tar.NewReader(r)
+
+	var files []File
+	var total int64
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid tar archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || !isMarkdownFile(header.Name) {
+			continue
+		}
+		content, err := readLimited(tarReader, header.Name, &total)
+		if err != nil {
+			return nil, err
+		}
+		if files, err = appendFile(files, header.Name, content); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readLimited reads a file from an archive, enforcing the per-file and total size limits
+func readLimited(r io.Reader, name string, total *int64) ([]byte, error) {
+	content, err := io.ReadAll(io.LimitReader(r, MaxFileBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '%s': %w", name, err)
+	}
+	if len(content) > MaxFileBytes {
+		return nil, fmt.Errorf("'%s' exceeds the %d byte file size limit", name, MaxFileBytes)
+	}
+	*total += int64(len(content))
+	if *total > MaxTotalBytes {
+		return nil, fmt.Errorf("archive exceeds the %d byte size limit", MaxTotalBytes)
+	}
+	return content, nil
+}
+
+// appendFile adds a file to the list, enforcing the file count limit
+func appendFile(files []File, name string, content []byte) ([]File, error) {
+	if len(files) >= MaxFiles {
+		return nil, fmt.Errorf("archive contains more than %d Markdown files", MaxFiles)
+	}
+	return append(files, File{Name: name, Data: content}), nil
+}
+
+// isMarkdownFile reports whether an archive entry is a Markdown file worth importing,
+// skipping hidden files and macOS metadata
+func isMarkdownFile(name string) bool {
+	base := path.Base(name)
+	if strings.HasPrefix(base, ".") || strings.Contains(name, "__MACOSX/") {
+		return false
+	}
+	ext := strings.ToLower(path.Ext(base))
+	return ext == ".md" || ext == ".markdown"
+}
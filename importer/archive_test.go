@@ -0,0 +1,90 @@
+package importer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var archiveTestFiles = map[string]string{
+	"content/posts/first.md":      "---\ntitle: First\n---\nBody",
+	"content/posts/second.MD":     "+++\ntitle = \"Second\"\n+++\nBody",
+	"content/posts/image.png":     "not markdown",
+	"__MACOSX/content/._first.md": "metadata",
+}
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	for name, content := range files {
+		file, err := zipWriter.Create(name)
+		assert.NoError(t, err)
+		_, _ = file.Write([]byte(content))
+	}
+	assert.NoError(t, zipWriter.Close())
+	return buf.Bytes()
+}
+
+func buildTar(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	tarWriter := tar.NewWriter(&buf)
+	for name, content := range files {
+		assert.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, _ = tarWriter.Write([]byte(content))
+	}
+	assert.NoError(t, tarWriter.Close())
+	return buf.Bytes()
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	_, _ = gzipWriter.Write(data)
+	assert.NoError(t, gzipWriter.Close())
+	return buf.Bytes()
+}
+
+func fileNames(files []File) []string {
+	names := []string{}
+	for _, file := range files {
+		names = append(names, file.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestReadArchive_Formats(t *testing.T) {
+	tarData := buildTar(t, archiveTestFiles)
+	archives := map[string][]byte{
+		"zip":    buildZip(t, archiveTestFiles),
+		"tar":    tarData,
+		"tar.gz": gzipBytes(t, tarData),
+	}
+
+	for format, data := range archives {
+		t.Run(format, func(t *testing.T) {
+			files, err := ReadArchive(data)
+
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"content/posts/first.md", "content/posts/second.MD"}, fileNames(files), "Only Markdown files should be read")
+		})
+	}
+}
+
+func TestReadArchive_Unsupported(t *testing.T) {
+	_, err := ReadArchive([]byte("just some text"))
+	assert.ErrorIs(t, err, ErrUnsupportedArchive)
+}
+
+func TestReadArchive_FileSizeLimit(t *testing.T) {
+	data := buildZip(t, map[string]string{"big.md": strings.Repeat("x", MaxFileBytes+1)})
+
+	_, err := ReadArchive(data)
+	assert.ErrorContains(t, err, "file size limit")
+}
@@ -0,0 +1,278 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	whitespaceRun   = regexp.MustCompile(`\s+`)
+	blankLines      = regexp.MustCompile(`\n\s*\n`)
+	paragraphTag    = regexp.MustCompile(`(?i)<p[\s>]`)
+	leadingBlockTag = regexp.MustCompile(`(?i)^<(h[1-6]|ul|ol|pre|blockquote|div|table|figure|hr)[\s>/]`)
+)
+
+// blockElements are rendered as separate Markdown blocks
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true, atom.Header: true,
+	atom.Footer: true, atom.Main: true, atom.Aside: true, atom.Nav: true, atom.Figure: true,
+	atom.Figcaption: true, atom.Blockquote: true, atom.Pre: true, atom.Ul: true, atom.Ol: true,
+	atom.Li: true, atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true,
+	atom.H6: true, atom.Hr: true, atom.Table: true, atom.Thead: true, atom.Tbody: true,
+	atom.Tfoot: true, atom.Tr: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.Center: true, atom.Address: true,
+}
+
+// HTMLToMarkdown converts post HTML, as stored by WordPress, to Markdown. Content
+// from the classic editor separates paragraphs with blank lines instead of <p>
+// tags, so those are turned into paragraphs first, as WordPress does on display.
+func HTMLToMarkdown(input string) string {
+	if !paragraphTag.MatchString(input) {
+		input = autoParagraph(input)
+	}
+
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(input), body)
+	if err != nil {
+		return strings.TrimSpace(input)
+	}
+	for _, node := range nodes {
+		body.AppendChild(node)
+	}
+
+	return strings.Join(renderBlocks(body), "\n\n")
+}
+
+// autoParagraph wraps blank-line separated chunks of text in <p> tags and turns
+// single line breaks into <br>, leaving chunks that start with a block tag alone
+func autoParagraph(input string) string {
+	var b strings.Builder
+	for _, chunk := range blankLines.Split(strings.ReplaceAll(input, "\r\n", "\n"), -1) {
+		chunk = strings.TrimSpace(chunk)
+		if chunk == "" {
+			continue
+		}
+		if leadingBlockTag.MatchString(chunk) {
+			b.WriteString(chunk + "\n")
+			continue
+		}
+		b.WriteString("<p>" + strings.ReplaceAll(chunk, "\n", "<br>\n") + "</p>\n")
+	}
+	return b.String()
+}
+
+// renderBlocks renders the children of n as Markdown blocks. Runs of inline
+// content between block elements become paragraphs.
+func renderBlocks(n *html.Node) []string {
+	var blocks []string
+	var inline strings.Builder
+
+	flush := func() {
+		if text := cleanInline(inline.String()); text != "" {
+			blocks = append(blocks, text)
+		}
+		inline.Reset()
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && blockElements[child.DataAtom] {
+			flush()
+			blocks = append(blocks, renderBlock(child)...)
+		} else {
+			inline.WriteString(renderInline(child))
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+// renderBlock renders a block element as zero or more Markdown blocks
+func renderBlock(n *html.Node) []string {
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := strings.ReplaceAll(cleanInline(renderInlineChildren(n)), "  \n", " ")
+		if text == "" {
+			return nil
+		}
+		level := int(n.Data[1] - '0')
+		return []string{strings.Repeat("#", level) + " " + text}
+
+	case atom.Pre:
+		code := strings.Trim(textContent(n), "\n")
+		if code == "" {
+			return nil
+		}
+		return []string{"```\n" + code + "\n```"}
+
+	case atom.Blockquote:
+		inner := strings.Join(renderBlocks(n), "\n\n")
+		if inner == "" {
+			return nil
+		}
+		lines := strings.Split(inner, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		return []string{strings.Join(lines, "\n")}
+
+	case atom.Ul, atom.Ol:
+		if list := renderList(n); list != "" {
+			return []string{list}
+		}
+		return nil
+
+	case atom.Hr:
+		return []string{"---"}
+
+	default:
+		return renderBlocks(n)
+	}
+}
+
+// renderList renders the items of a list, indenting their continuation lines
+// so nested content stays inside its item
+func renderList(n *html.Node) string {
+	var items []string
+	number := 1
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || child.DataAtom != atom.Li {
+			continue
+		}
+
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = fmt.Sprintf("%d. ", number)
+			number++
+		}
+
+		lines := strings.Split(strings.Join(renderBlocks(child), "\n"), "\n")
+		indent := strings.Repeat(" ", len(marker))
+		for i := range lines {
+			if i == 0 {
+				lines[i] = marker + lines[i]
+			} else if lines[i] != "" {
+				lines[i] = indent + lines[i]
+			}
+		}
+		items = append(items, strings.TrimRight(strings.Join(lines, "\n"), " "))
+	}
+	return strings.Join(items, "\n")
+}
+
+// renderInline renders inline content. Line breaks are returned as "\n" and turned
+// into Markdown hard breaks by cleanInline.
+func renderInline(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return whitespaceRun.ReplaceAllString(n.Data, " ")
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		return "\n"
+	case atom.Strong, atom.B:
+		return wrapInline(renderInlineChildren(n), "**")
+	case atom.Em, atom.I:
+		return wrapInline(renderInlineChildren(n), "*")
+	case atom.Del, atom.S, atom.Strike:
+		return wrapInline(renderInlineChildren(n), "~~")
+	case atom.Code, atom.Kbd, atom.Tt:
+		if code := textContent(n); code != "" {
+			return "`" + code + "`"
+		}
+		return ""
+	case atom.A:
+		text := strings.TrimSpace(renderInlineChildren(n))
+		href := attr(n, "href")
+		if href == "" {
+			return text
+		}
+		if text == "" {
+			text = href
+		}
+		return "[" + text + "](" + href + ")"
+	case atom.Img:
+		if src := attr(n, "src"); src != "" {
+			return "![" + attr(n, "alt") + "](" + src + ")"
+		}
+		return ""
+	case atom.Td, atom.Th:
+		return renderInlineChildren(n) + " "
+	case atom.Script, atom.Style, atom.Noscript, atom.Iframe:
+		return ""
+	default:
+		return renderInlineChildren(n)
+	}
+}
+
+// renderInlineChildren renders the children of n as inline content, including
+// any block elements nested where only inline content is expected
+func renderInlineChildren(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && blockElements[child.DataAtom] {
+			b.WriteString(" " + renderInlineChildren(child) + " ")
+			continue
+		}
+		b.WriteString(renderInline(child))
+	}
+	return b.String()
+}
+
+// wrapInline surrounds text with a Markdown emphasis marker, keeping surrounding
+// spaces outside of it since Markdown ignores markers next to whitespace
+func wrapInline(text, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	prefix, suffix := "", ""
+	if strings.HasPrefix(text, " ") {
+		prefix = " "
+	}
+	if strings.HasSuffix(text, " ") {
+		suffix = " "
+	}
+	return prefix + marker + trimmed + marker + suffix
+}
+
+// cleanInline trims each line of inline content and joins lines with Markdown hard breaks
+func cleanInline(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "  \n")
+}
+
+// textContent returns the concatenated text of n and its descendants
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+// attr returns the value of an attribute of n
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTMLToMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			"paragraphs and emphasis",
+			"<p>Hello <strong>bold</strong> and <em>italic </em>text.</p><p>Second</p>",
+			"Hello **bold** and *italic* text.\n\nSecond",
+		},
+		{
+			"classic editor line breaks",
+			"First paragraph\nwith a break\n\nSecond paragraph",
+			"First paragraph  \nwith a break\n\nSecond paragraph",
+		},
+		{
+			"headings and links",
+			`<h2>Title <a href="https://example.com">link</a></h2><p><img src="/a.png" alt="A"></p>`,
+			"## Title [link](https://example.com)\n\n![A](/a.png)",
+		},
+		{
+			"lists",
+			"<ul><li>One</li><li>Two<ol><li>Nested</li></ol></li></ul>",
+			"- One\n- Two\n  1. Nested",
+		},
+		{
+			"code",
+			"<p>Use <code>go test</code>:</p><pre><code>go test ./...\n</code></pre>",
+			"Use `go test`:\n\n```\ngo test ./...\n```",
+		},
+		{
+			"blockquote and rule",
+			"<blockquote><p>Quoted</p><p>Twice</p></blockquote><hr>",
+			"> Quoted\n>\n> Twice\n\n---",
+		},
+		{
+			"gutenberg comments and scripts",
+			"<!-- wp:paragraph -->\n<p>Kept</p>\n<!-- /wp:paragraph --><script>alert(1)</script>",
+			"Kept",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, HTMLToMarkdown(tt.html))
+		})
+	}
+}
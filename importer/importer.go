@@ -0,0 +1,327 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/locale"
+	"dbl-blog-backend/markdown"
+	"dbl-blog-backend/models"
+	"dbl-blog-backend/slug"
+
+	"github.com/gin-gonic/gin/binding"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Actions reported for each imported file
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionUnchanged = "unchanged"
+	ActionInvalid   = "invalid" // The file could not be parsed or failed validation
+	ActionFailed    = "failed"  // The post was valid but could not be written
+)
+
+// Candidate is a post parsed from an import source, along with any problems found.
+// Errors prevent the import; warnings describe data that was adjusted or dropped.
+type Candidate struct {
+	File     string
+	Post     models.Post
+	Errors   []string
+	Warnings []string
+}
+
+// FileResult is the outcome of importing a single file
+type FileResult struct {
+	File     string   `json:"file"`
+	Slug     string   `json:"slug,omitempty"`
+	Action   string   `json:"action"`
+	PostID   string   `json:"post_id,omitempty"`
+	Errors   []string `json:"errors,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Report summarizes an import. In a dry run, actions describe what would happen.
+type Report struct {
+	DryRun    bool         `json:"dry_run"`
+	Total     int          `json:"total"`
+	Created   int          `json:"created"`
+	Updated   int          `json:"updated"`
+	Unchanged int          `json:"unchanged"`
+	Invalid   int          `json:"invalid"`
+	Failed    int          `json:"failed"`
+	Files     []FileResult `json:"files"`
+}
+
+// Options control how candidates are imported
+type Options struct {
+	DryRun bool
+
+	// AuthorIDs attributes newly created posts that name no authors
+	AuthorIDs []primitive.ObjectID
+
+	// Events is called with the context of the transaction that creates (before
+	// is nil) or updates each post. An error rolls the write back.
+	Events func(ctx context.Context, before, after *models.Post) error
+
+	// OnWrite is called after each post is created (before is nil) or updated
+	OnWrite func(action string, before, after *models.Post)
+}
+
+// jekyllFileName matches Jekyll post file names such as 2024-01-02-my-post.md
+var jekyllFileName = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-(.+)$`)
+
+// ParseMarkdown turns Markdown files with front matter into candidates. The slug
+// falls back to the file name (or the directory of a Hugo page bundle), then to
+// the title; a Jekyll file name also provides a missing date, and a Hugo language
+// suffix such as my-post.pt.md a missing language.
+func ParseMarkdown(files []File) []Candidate {
+	candidates := make([]Candidate, 0, len(files))
+	for _, file := range files {
+		post, err := markdown.Decode(file.Data)
+		if err != nil {
+			candidates = append(candidates, Candidate{File: file.Name, Errors: []string{err.Error()}})
+			continue
+		}
+
+		name := strings.TrimSuffix(path.Base(file.Name), path.Ext(file.Name))
+		if suffix := strings.TrimPrefix(path.Ext(name), "."); suffix != "" && locale.IsSupported(suffix) {
+			name = strings.TrimSuffix(name, "."+suffix)
+			if post.Lang == "" {
+				post.Lang = suffix
+			}
+		}
+		if name == "index" || name == "_index" {
+			name = path.Base(path.Dir(file.Name))
+		}
+		if match := jekyllFileName.FindStringSubmatch(name); match != nil {
+			name = match[2]
+			if post.CreatedAt.IsZero() {
+				post.CreatedAt, _ = time.Parse("2006-01-02", match[1])
+			}
+		}
+		if post.Slug == "" && name != "." && name != "/" {
+			post.Slug = slug.Generate(name)
+		}
+
+		candidates = append(candidates, Candidate{File: file.Name, Post: post})
+	}
+	return Validate(candidates)
+}
+
+// Validate fills in missing slugs and languages, checks candidates against the Post
+// binding rules and supported languages, and rejects slugs used by more than one
+// candidate in the same language
+func Validate(candidates []Candidate) []Candidate {
+	seen := make(map[string]string)
+	for i := range candidates {
+		candidate := &candidates[i]
+		if len(candidate.Errors) > 0 {
+			continue
+		}
+		if candidate.Post.Slug == "" {
+			candidate.Post.Slug = slug.Generate(candidate.Post.Title)
+		}
+		if candidate.Post.Lang = locale.Normalize(candidate.Post.Lang); candidate.Post.Lang == "" {
+			candidate.Post.Lang = locale.Default()
+		}
+		if err := binding.Validator.ValidateStruct(&candidate.Post); err != nil {
+			candidate.Errors = strings.Split(err.Error(), "\n")
+			continue
+		}
+		if !locale.IsSupported(candidate.Post.Lang) {
+			candidate.Errors = []string{fmt.Sprintf("lang '%s' is not supported", candidate.Post.Lang)}
+			continue
+		}
+		key := candidate.Post.Lang + "/" + candidate.Post.Slug
+		if other, ok := seen[key]; ok {
+			candidate.Errors = []string{fmt.Sprintf("slug '%s' is also used by '%s'", candidate.Post.Slug, other)}
+			continue
+		}
+		seen[key] = candidate.File
+	}
+	return candidates
+}
+
+// Import upserts valid candidates by language and slug: posts with a new slug are
+// created, each in a translation group of its own, and existing posts have their
+// content fields updated. Views, likes and creation dates from the source are
+// only applied to new posts.
+func Import(ctx context.Context, candidates []Candidate, opts Options) Report {
+	report := Report{DryRun: opts.DryRun, Total: len(candidates), Files: []FileResult{}}
+	collection := database.Database.Collection("posts")
+
+	for _, candidate := range candidates {
+		result := FileResult{File: candidate.File, Slug: candidate.Post.Slug}
+		if len(candidate.Errors) > 0 {
+			result.Action = ActionInvalid
+			result.Errors = candidate.Errors
+		} else {
+			result = importPost(ctx, collection, candidate, opts)
+		}
+		result.Warnings = candidate.Warnings
+
+		switch result.Action {
+		case ActionCreate:
+			report.Created++
+		case ActionUpdate:
+			report.Updated++
+		case ActionUnchanged:
+			report.Unchanged++
+		case ActionInvalid:
+			report.Invalid++
+		default:
+			report.Failed++
+		}
+		report.Files = append(report.Files, result)
+	}
+
+	return report
+}
+
+// importPost creates or updates the post for a single valid candidate
+func importPost(ctx context.Context, collection *mongo.Collection, candidate Candidate, opts Options) FileResult {
+	post := candidate.Post
+	result := FileResult{File: candidate.File, Slug: post.Slug}
+
+	// The slug may be the current slug of a post or one of the previous slugs
+	// kept in its history, which the (lang, slugs) unique index reserves
+	var existing models.Post
+	filter := bson.M{"lang": post.Lang, "$or": []bson.M{{"slug": post.Slug}, {"slugs": post.Slug}}}
+	err := collection.FindOne(ctx, filter).Decode(&existing)
+	if err != nil && err != mongo.ErrNoDocuments {
+		result.Action = ActionFailed
+		result.Errors = []string{"failed to look up slug: " + err.Error()}
+		return result
+	}
+	if err == nil && existing.Slug != post.Slug {
+		result.Action = ActionFailed
+		result.Errors = []string{fmt.Sprintf("slug is a previous slug of post '%s', now at '%s'", existing.ID.Hex(), existing.Slug)}
+		return result
+	}
+
+	if err == mongo.ErrNoDocuments {
+		result.Action = ActionCreate
+		if opts.DryRun {
+			return result
+		}
+
+		now := time.Now()
+		if post.CreatedAt.IsZero() {
+			post.CreatedAt = now
+		}
+		if post.UpdatedAt.IsZero() {
+			post.UpdatedAt = post.CreatedAt
+		}
+		if len(post.AuthorIDs) == 0 {
+			post.AuthorIDs = opts.AuthorIDs
+		}
+		post.Slugs = []string{post.Slug}
+		post.TranslationGroupID = primitive.NewObjectID()
+		post.Version = 1
+		markdown.ApplyMetadata(&post)
+
+		err := database.WithTransaction(ctx, func(ctx context.Context) error {
+			post.ID = primitive.NilObjectID
+			inserted, err := collection.InsertOne(ctx, post)
+			if err != nil {
+				return err
+			}
+			post.ID = inserted.InsertedID.(primitive.ObjectID)
+			return writeEvents(ctx, opts, nil, &post)
+		})
+		if err != nil {
+			result.Action = ActionFailed
+			result.Errors = []string{"failed to create post: " + err.Error()}
+			return result
+		}
+		result.PostID = post.ID.Hex()
+		if opts.OnWrite != nil {
+			opts.OnWrite(ActionCreate, nil, &post)
+		}
+		return result
+	}
+
+	result.PostID = existing.ID.Hex()
+	setFields := changedFields(existing, post)
+	if len(setFields) == 0 {
+		result.Action = ActionUnchanged
+		return result
+	}
+
+	result.Action = ActionUpdate
+	if opts.DryRun {
+		return result
+	}
+
+	if _, ok := setFields["content"]; ok {
+		markdown.ApplyMetadata(&post)
+		setFields["word_count"] = post.WordCount
+		setFields["reading_time_minutes"] = post.ReadingTimeMinutes
+		setFields["toc"] = post.TOC
+	}
+	setFields["updated_at"] = time.Now()
+	var updated models.Post
+	err = database.WithTransaction(ctx, func(ctx context.Context) error {
+		err := collection.FindOneAndUpdate(
+			ctx,
+			bson.M{"_id": existing.ID},
+			bson.M{"$set": setFields, "$inc": bson.M{"version": 1}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&updated)
+		if err != nil {
+			return err
+		}
+		return writeEvents(ctx, opts, &existing, &updated)
+	})
+	if err != nil {
+		result.Action = ActionFailed
+		result.Errors = []string{"failed to update post: " + err.Error()}
+		return result
+	}
+	if opts.OnWrite != nil {
+		opts.OnWrite(ActionUpdate, &existing, &updated)
+	}
+	return result
+}
+
+// writeEvents calls the Events option, if set, for a post write
+func writeEvents(ctx context.Context, opts Options, before, after *models.Post) error {
+	if opts.Events == nil {
+		return nil
+	}
+	return opts.Events(ctx, before, after)
+}
+
+// changedFields returns the content fields of post that differ from existing
+func changedFields(existing, post models.Post) bson.M {
+	fields := bson.M{}
+	if post.Title != existing.Title {
+		fields["title"] = post.Title
+	}
+	if post.Content != existing.Content {
+		fields["content"] = post.Content
+	}
+	if post.Summary != existing.Summary {
+		fields["summary"] = post.Summary
+	}
+	if (len(post.Tags) > 0 || len(existing.Tags) > 0) && !reflect.DeepEqual(post.Tags, existing.Tags) {
+		fields["tags"] = post.Tags
+	}
+	if post.Published != existing.Published {
+		fields["published"] = post.Published
+	}
+	if !post.CreatedAt.IsZero() && !post.CreatedAt.Equal(existing.CreatedAt) {
+		fields["created_at"] = post.CreatedAt
+	}
+	return fields
+}
@@ -0,0 +1,80 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"dbl-blog-backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	files := []File{
+		{Name: "_posts/2024-03-04-jekyll-post.md", Data: []byte("---\ntitle: Jekyll Post\n---\nBody")},
+		{Name: "content/posts/bundle/index.md", Data: []byte("+++\ntitle = \"Bundle\"\n+++\nBody")},
+		{Name: "content/posts/explicit.md", Data: []byte("---\ntitle: Explicit\nslug: custom-slug\n---\nBody")},
+		{Name: "content/posts/no-title.md", Data: []byte("---\nslug: no-title\n---\nBody")},
+		{Name: "content/posts/bad-tags.md", Data: []byte("---\ntitle: Bad Tags\ntags: [\"not alphanumeric!\"]\n---\nBody")},
+		{Name: "content/posts/duplicate.md", Data: []byte("---\ntitle: Duplicate\nslug: custom-slug\n---\nBody")},
+		{Name: "content/posts/plain.md", Data: []byte("No front matter")},
+	}
+
+	candidates := ParseMarkdown(files)
+	assert.Len(t, candidates, len(files))
+
+	jekyll := candidates[0]
+	assert.Empty(t, jekyll.Errors)
+	assert.Equal(t, "jekyll-post", jekyll.Post.Slug)
+	assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), jekyll.Post.CreatedAt)
+
+	assert.Equal(t, "bundle", candidates[1].Post.Slug, "Page bundles are named after their directory")
+	assert.Equal(t, "custom-slug", candidates[2].Post.Slug, "Front matter slugs take precedence")
+
+	assert.NotEmpty(t, candidates[3].Errors, "Title is required")
+	assert.NotEmpty(t, candidates[4].Errors, "Tags must be alphanumeric")
+	if assert.Len(t, candidates[5].Errors, 1) {
+		assert.True(t, strings.Contains(candidates[5].Errors[0], "content/posts/explicit.md"), "Duplicate slugs name the other file")
+	}
+	assert.Equal(t, []string{"missing front matter"}, candidates[6].Errors)
+}
+
+func TestParseMarkdown_Languages(t *testing.T) {
+	t.Setenv("DEFAULT_LANGUAGE", "")
+	t.Setenv("SUPPORTED_LANGUAGES", "en,pt")
+
+	files := []File{
+		{Name: "content/posts/hello.md", Data: []byte("---\ntitle: Hello\n---\nBody")},
+		{Name: "content/posts/hello.pt.md", Data: []byte("---\ntitle: Olá\n---\nCorpo")},
+		{Name: "content/posts/bundle/index.pt.md", Data: []byte("---\ntitle: Pacote\n---\nCorpo")},
+		{Name: "content/posts/french.md", Data: []byte("---\ntitle: Bonjour\nlang: fr\n---\nCorps")},
+		{Name: "content/posts/v1.2.md", Data: []byte("---\ntitle: Version\n---\nBody")},
+	}
+
+	candidates := ParseMarkdown(files)
+	assert.Equal(t, "en", candidates[0].Post.Lang, "Posts default to the default language")
+	assert.Equal(t, "pt", candidates[1].Post.Lang)
+	assert.Equal(t, "hello", candidates[1].Post.Slug, "Translations may share a slug")
+	assert.Empty(t, candidates[1].Errors)
+	assert.Equal(t, "bundle", candidates[2].Post.Slug)
+	assert.Equal(t, "pt", candidates[2].Post.Lang)
+	assert.Equal(t, []string{"lang 'fr' is not supported"}, candidates[3].Errors)
+	assert.Equal(t, "v1-2", candidates[4].Post.Slug, "Other suffixes are part of the name")
+}
+
+func TestChangedFields(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	existing := models.Post{Title: "Title", Content: "Body", Tags: []string{}, Published: true, CreatedAt: created}
+
+	same := models.Post{Title: "Title", Content: "Body", Published: true}
+	assert.Empty(t, changedFields(existing, same), "Missing tags and dates match empty ones")
+
+	changed := models.Post{Title: "New Title", Content: "Body", Tags: []string{"go"}, CreatedAt: created.Add(time.Hour)}
+	fields := changedFields(existing, changed)
+	assert.Equal(t, "New Title", fields["title"])
+	assert.Equal(t, []string{"go"}, fields["tags"])
+	assert.Equal(t, false, fields["published"])
+	assert.Equal(t, created.Add(time.Hour), fields["created_at"])
+	assert.NotContains(t, fields, "content")
+}
@@ -0,0 +1,237 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"dbl-blog-backend/slug"
+
+	"golang.org/x/net/html"
+)
+
+// Limits of models.Post that WordPress data is fitted into
+const (
+	maxTags       = 10
+	maxTagLength  = 50
+	maxSummaryLen = 500
+)
+
+// wxrDateLayout is the layout of WordPress post and comment dates
+const wxrDateLayout = "2006-01-02 15:04:05"
+
+// wxrFeed is the subset of a WordPress eXtended RSS (WXR) export used for import.
+// Elements are matched by local name so that all WXR versions are accepted.
+type wxrFeed struct {
+	Items []wxrItem `xml:"channel>item"`
+}
+
+type wxrItem struct {
+	Title       string        `xml:"title"`
+	PubDate     string        `xml:"pubDate"`
+	Encoded     []wxrEncoded  `xml:"encoded"` // content:encoded and excerpt:encoded
+	PostID      string        `xml:"post_id"`
+	PostDate    string        `xml:"post_date"`
+	PostDateGMT string        `xml:"post_date_gmt"`
+	ModifiedGMT string        `xml:"post_modified_gmt"`
+	PostName    string        `xml:"post_name"`
+	Status      string        `xml:"status"`
+	PostType    string        `xml:"post_type"`
+	Categories  []wxrCategory `xml:"category"`
+	Comments    []wxrComment  `xml:"comment"`
+}
+
+type wxrEncoded struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type wxrCategory struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+type wxrComment struct {
+	Approved string `xml:"comment_approved"`
+	Type     string `xml:"comment_type"`
+}
+
+// WordPressReport summarizes a WordPress import on top of the per-post report
+type WordPressReport struct {
+	Report
+	Skipped          int `json:"skipped"`           // Items that are not importable posts
+	ApprovedComments int `json:"approved_comments"` // Approved comments found, not imported as there is no comments store
+}
+
+// ParseWordPress parses a WXR export into candidates. Only items of type post are
+// imported; pages, attachments, trashed posts and auto-drafts are skipped and
+// counted. Content is converted from HTML to Markdown, categories and tags are
+// fitted into post tags, and approved comments are counted for the report.
+func ParseWordPress(r io.Reader) ([]Candidate, WordPressReport, error) {
+	var feed wxrFeed
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	if err := decoder.Decode(&feed); err != nil {
+		return nil, WordPressReport{}, fmt.Errorf("invalid WXR file: %w", err)
+	}
+
+	var report WordPressReport
+	candidates := []Candidate{}
+	for _, item := range feed.Items {
+		if item.PostType != "post" || item.Status == "trash" || item.Status == "auto-draft" {
+			report.Skipped++
+			continue
+		}
+
+		report.ApprovedComments += countApproved(item.Comments)
+		candidates = append(candidates, wordpressCandidate(item))
+	}
+
+	return Validate(candidates), report, nil
+}
+
+// wordpressCandidate maps a WXR item onto a post
+func wordpressCandidate(item wxrItem) Candidate {
+	candidate := Candidate{File: "post " + item.PostID}
+	post := &candidate.Post
+
+	post.Title = strings.TrimSpace(item.Title)
+	post.Published = item.Status == "publish"
+
+	if name, err := url.PathUnescape(item.PostName); err == nil {
+		post.Slug = name
+	} else {
+		post.Slug = item.PostName
+	}
+
+	for _, encoded := range item.Encoded {
+		switch {
+		case strings.Contains(encoded.XMLName.Space, "/content/"):
+			post.Content = HTMLToMarkdown(encoded.Value)
+		case strings.Contains(encoded.XMLName.Space, "/excerpt/"):
+			post.Summary = htmlToText(encoded.Value)
+		}
+	}
+	if utf8.RuneCountInString(post.Summary) > maxSummaryLen {
+		post.Summary = string([]rune(post.Summary)[:maxSummaryLen-1]) + "…"
+		candidate.Warnings = append(candidate.Warnings, "excerpt shortened to fit the summary limit")
+	}
+
+	post.CreatedAt = wordpressDate(item.PostDateGMT, item.PostDate, item.PubDate)
+	post.UpdatedAt = wordpressDate(item.ModifiedGMT)
+
+	var warnings []string
+	post.Tags, warnings = wordpressTags(item.Categories)
+	candidate.Warnings = append(candidate.Warnings, warnings...)
+
+	if approved := countApproved(item.Comments); approved > 0 {
+		candidate.Warnings = append(candidate.Warnings, fmt.Sprintf("%d approved comments not imported: there is no comments store", approved))
+	}
+
+	return candidate
+}
+
+// wordpressDate parses the first usable date. WordPress GMT dates are UTC, post_date
+// is in the blog's time zone (taken as UTC), and pubDate is RFC 1123. Drafts carry
+// zero dates, which are skipped.
+func wordpressDate(values ...string) time.Time {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || strings.HasPrefix(value, "0000-00-00") {
+			continue
+		}
+		if parsed, err := time.Parse(wxrDateLayout, value); err == nil {
+			return parsed
+		}
+		if parsed, err := time.Parse(time.RFC1123Z, value); err == nil {
+			return parsed
+		}
+	}
+	return time.Time{}
+}
+
+// wordpressTags turns categories and tags into alphanumeric post tags of at most 50
+// characters, dropping duplicates, WordPress's default category and anything past
+// the 10 tag limit. Categories come first.
+func wordpressTags(categories []wxrCategory) ([]string, []string) {
+	var tags, warnings []string
+	seen := make(map[string]bool)
+
+	for _, domain := range []string{"category", "post_tag"} {
+		for _, category := range categories {
+			if category.Domain != domain || category.Nicename == "uncategorized" {
+				continue
+			}
+
+			tag := alphanumericTag(category.Name)
+			if tag == "" {
+				tag = alphanumericTag(category.Nicename)
+			}
+			if tag == "" {
+				warnings = append(warnings, fmt.Sprintf("%s '%s' has no alphanumeric characters and was dropped", domain, category.Name))
+				continue
+			}
+			if seen[tag] {
+				continue
+			}
+			if len(tags) == maxTags {
+				warnings = append(warnings, fmt.Sprintf("%s '%s' dropped: posts have at most %d tags", domain, category.Name, maxTags))
+				continue
+			}
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags, warnings
+}
+
+// alphanumericTag reduces a category or tag name to lowercase ASCII letters and digits
+func alphanumericTag(name string) string {
+	if strings.IndexFunc(name, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, r := range slug.Generate(name) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	tag := b.String()
+	if len(tag) > maxTagLength {
+		tag = tag[:maxTagLength]
+	}
+	return tag
+}
+
+// countApproved counts approved comments, excluding pingbacks and trackbacks
+func countApproved(comments []wxrComment) int {
+	count := 0
+	for _, comment := range comments {
+		if comment.Approved == "1" && comment.Type != "pingback" && comment.Type != "trackback" {
+			count++
+		}
+	}
+	return count
+}
+
+// htmlToText strips tags from an HTML fragment and collapses whitespace
+func htmlToText(input string) string {
+	nodes, err := html.ParseFragment(strings.NewReader(input), nil)
+	if err != nil {
+		return strings.TrimSpace(input)
+	}
+	var b strings.Builder
+	for _, node := range nodes {
+		b.WriteString(textContent(node) + " ")
+	}
+	return strings.TrimSpace(whitespaceRun.ReplaceAllString(b.String(), " "))
+}
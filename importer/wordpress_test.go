@@ -0,0 +1,121 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testWXR = `<?xml version="1.0" encoding="UTF-8" ?>
+<rss version="2.0"
+	xmlns:excerpt="http://wordpress.org/export/1.2/excerpt/"
+	xmlns:content="http://purl.org/rss/1.0/modules/content/"
+	xmlns:dc="http://purl.org/dc/elements/1.1/"
+	xmlns:wp="http://wordpress.org/export/1.2/">
+<channel>
+	<title>Old Blog</title>
+	<item>
+		<title>Caf&#233; Notes</title>
+		<pubDate>Tue, 02 Jan 2024 10:00:00 +0000</pubDate>
+		<content:encoded><![CDATA[First paragraph
+
+<h2>Section</h2>
+Second <strong>paragraph</strong>]]></content:encoded>
+		<excerpt:encoded><![CDATA[<p>A short <em>excerpt</em></p>]]></excerpt:encoded>
+		<wp:post_id>12</wp:post_id>
+		<wp:post_date_gmt>2024-01-02 10:00:00</wp:post_date_gmt>
+		<wp:post_modified_gmt>2024-01-05 08:30:00</wp:post_modified_gmt>
+		<wp:post_name><![CDATA[caf%c3%a9-notes]]></wp:post_name>
+		<wp:status><![CDATA[publish]]></wp:status>
+		<wp:post_type><![CDATA[post]]></wp:post_type>
+		<category domain="category" nicename="uncategorized"><![CDATA[Uncategorized]]></category>
+		<category domain="category" nicename="coffee-culture"><![CDATA[Coffee Culture]]></category>
+		<category domain="post_tag" nicename="go"><![CDATA[Go]]></category>
+		<category domain="post_tag" nicename="c"><![CDATA[C++]]></category>
+		<category domain="post_tag" nicename="symbols"><![CDATA[Symbols]]></category>
+		<category domain="post_tag" nicename=""><![CDATA[!!!]]></category>
+		<wp:comment>
+			<wp:comment_approved><![CDATA[1]]></wp:comment_approved>
+			<wp:comment_type><![CDATA[comment]]></wp:comment_type>
+		</wp:comment>
+		<wp:comment>
+			<wp:comment_approved><![CDATA[0]]></wp:comment_approved>
+		</wp:comment>
+		<wp:comment>
+			<wp:comment_approved><![CDATA[1]]></wp:comment_approved>
+			<wp:comment_type><![CDATA[pingback]]></wp:comment_type>
+		</wp:comment>
+	</item>
+	<item>
+		<title>Unfinished Draft</title>
+		<content:encoded><![CDATA[<p>Work in progress</p>]]></content:encoded>
+		<wp:post_id>13</wp:post_id>
+		<wp:post_date_gmt>0000-00-00 00:00:00</wp:post_date_gmt>
+		<wp:post_date>2024-02-01 09:00:00</wp:post_date>
+		<wp:post_name></wp:post_name>
+		<wp:status>draft</wp:status>
+		<wp:post_type>post</wp:post_type>
+	</item>
+	<item>
+		<title>About</title>
+		<wp:post_id>2</wp:post_id>
+		<wp:status>publish</wp:status>
+		<wp:post_type>page</wp:post_type>
+	</item>
+	<item>
+		<title>logo.png</title>
+		<wp:post_id>3</wp:post_id>
+		<wp:status>inherit</wp:status>
+		<wp:post_type>attachment</wp:post_type>
+	</item>
+</channel>
+</rss>`
+
+func TestParseWordPress(t *testing.T) {
+	candidates, report, err := ParseWordPress(strings.NewReader(testWXR))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Skipped, "Pages and attachments should be skipped")
+	assert.Equal(t, 1, report.ApprovedComments, "Only approved comments should be counted")
+	if !assert.Len(t, candidates, 2) {
+		return
+	}
+
+	published := candidates[0]
+	assert.Empty(t, published.Errors)
+	assert.Equal(t, "post 12", published.File)
+	assert.Equal(t, "Café Notes", published.Post.Title)
+	assert.Equal(t, "café-notes", published.Post.Slug)
+	assert.True(t, published.Post.Published)
+	assert.Equal(t, "First paragraph\n\n## Section\n\nSecond **paragraph**", published.Post.Content)
+	assert.Equal(t, "A short excerpt", published.Post.Summary)
+	assert.Equal(t, []string{"coffeeculture", "go", "c", "symbols"}, published.Post.Tags)
+	assert.Equal(t, time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC), published.Post.CreatedAt)
+	assert.Equal(t, time.Date(2024, 1, 5, 8, 30, 0, 0, time.UTC), published.Post.UpdatedAt)
+	assert.Len(t, published.Warnings, 2, "Dropped tags and comments should be reported")
+
+	draft := candidates[1]
+	assert.Empty(t, draft.Errors)
+	assert.False(t, draft.Post.Published)
+	assert.Equal(t, "unfinished-draft", draft.Post.Slug, "Drafts without a slug get one from the title")
+	assert.Equal(t, time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC), draft.Post.CreatedAt)
+}
+
+func TestParseWordPress_Invalid(t *testing.T) {
+	_, _, err := ParseWordPress(strings.NewReader("<rss><channel><item>"))
+	assert.Error(t, err)
+}
+
+func TestWordPressTags_Limit(t *testing.T) {
+	var categories []wxrCategory
+	for _, name := range []string{"a1", "b2", "c3", "d4", "e5", "f6", "g7", "h8", "i9", "j10", "k11", "A1"} {
+		categories = append(categories, wxrCategory{Domain: "post_tag", Name: name})
+	}
+
+	tags, warnings := wordpressTags(categories)
+
+	assert.Len(t, tags, maxTags)
+	assert.Equal(t, []string{"post_tag 'k11' dropped: posts have at most 10 tags"}, warnings, "Duplicates are merged silently")
+}
@@ -0,0 +1,112 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Unit tests for JSON Merge Patch (RFC 7386) and JSON Patch (RFC 6902)
+
+func mustDecode(t *testing.T, data string) interface{} {
+	t.Helper()
+	value, err := Decode([]byte(data))
+	assert.NoError(t, err)
+	return value
+}
+
+func assertJSONEqual(t *testing.T, expected string, actual interface{}) {
+	t.Helper()
+	data, err := json.Marshal(actual)
+	assert.NoError(t, err)
+	assert.JSONEq(t, expected, string(data))
+}
+
+func TestMergePatch(t *testing.T) {
+	testCases := []struct {
+		name     string
+		target   string
+		patch    string
+		expected string
+	}{
+		{"replace member", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add member", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"remove member", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"replace array", `{"a":["b"]}`, `{"a":["c","d"]}`, `{"a":["c","d"]}`},
+		{"nested merge", `{"a":{"b":"c","d":"e"}}`, `{"a":{"d":null,"f":"g"}}`, `{"a":{"b":"c","f":"g"}}`},
+		{"non-object patch replaces", `{"a":"b"}`, `["c"]`, `["c"]`},
+		{"integers preserved", `{"views":12345678901}`, `{"title":"x"}`, `{"views":12345678901,"title":"x"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := MergePatch(mustDecode(t, tc.target), mustDecode(t, tc.patch))
+			assertJSONEqual(t, tc.expected, result)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	testCases := []struct {
+		name     string
+		doc      string
+		patch    string
+		expected string
+	}{
+		{"add member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"foo":"bar","baz":"qux"}`},
+		{"add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"append array element", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":"baz"}]`, `{"foo":["bar","baz"]}`},
+		{"remove member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"remove array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"replace member", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"move member", `{"foo":{"bar":"baz"},"qux":{}}`, `[{"op":"move","from":"/foo/bar","path":"/qux/thud"}]`, `{"foo":{},"qux":{"thud":"baz"}}`},
+		{"copy member", `{"foo":"bar"}`, `[{"op":"copy","from":"/foo","path":"/baz"}]`, `{"foo":"bar","baz":"bar"}`},
+		{"passing test", `{"foo":["a",2]}`, `[{"op":"test","path":"/foo","value":["a",2.0]}]`, `{"foo":["a",2]}`},
+		{"replace with null", `{"summary":"bar","foo":1}`, `[{"op":"replace","path":"/summary","value":null}]`, `{"summary":null,"foo":1}`},
+		{"add null", `{"foo":1}`, `[{"op":"add","path":"/bar","value":null}]`, `{"foo":1,"bar":null}`},
+		{"test null", `{"foo":null}`, `[{"op":"test","path":"/foo","value":null}]`, `{"foo":null}`},
+		{"escaped pointer", `{"a/b":1,"m~n":2}`, `[{"op":"replace","path":"/a~1b","value":3},{"op":"remove","path":"/m~0n"}]`, `{"a/b":3}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var operations []Operation
+			assert.NoError(t, json.Unmarshal([]byte(tc.patch), &operations))
+
+			result, err := Apply(mustDecode(t, tc.doc), operations)
+			assert.NoError(t, err)
+			assertJSONEqual(t, tc.expected, result)
+		})
+	}
+}
+
+func TestApply_Errors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		doc   string
+		patch string
+	}{
+		{"failing test", `{"foo":"bar"}`, `[{"op":"test","path":"/foo","value":"baz"}]`},
+		{"replace missing member", `{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"qux"}]`},
+		{"remove missing member", `{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`},
+		{"array index out of bounds", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/5","value":"qux"}]`},
+		{"leading zero index", `{"foo":["bar","baz"]}`, `[{"op":"remove","path":"/foo/01"}]`},
+		{"missing value", `{"foo":"bar"}`, `[{"op":"add","path":"/baz"}]`},
+		{"unknown operation", `{"foo":"bar"}`, `[{"op":"frobnicate","path":"/foo"}]`},
+		{"invalid pointer", `{"foo":"bar"}`, `[{"op":"remove","path":"foo"}]`},
+		{"move into child", `{"foo":{"bar":1}}`, `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var operations []Operation
+			assert.NoError(t, json.Unmarshal([]byte(tc.patch), &operations))
+
+			doc := mustDecode(t, tc.doc)
+			_, err := Apply(doc, operations)
+			assert.Error(t, err)
+			assertJSONEqual(t, tc.doc, doc) // Input must never be modified
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package locale
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default returns DEFAULT_LANGUAGE, the language of posts created without one
+// and of posts written before translations existed
+func Default() string {
+	if lang := Normalize(os.Getenv("DEFAULT_LANGUAGE")); lang != "" {
+		return lang
+	}
+	return "en"
+}
+
+// Supported returns SUPPORTED_LANGUAGES, the comma-separated languages posts
+// can be written in. The default language is always supported.
+func Supported() []string {
+	value := os.Getenv("SUPPORTED_LANGUAGES")
+	if strings.TrimSpace(value) == "" {
+		value = "en,pt"
+	}
+
+	defaultLang := Default()
+	languages := []string{defaultLang}
+	for _, lang := range strings.Split(value, ",") {
+		if lang = Normalize(lang); lang != "" && lang != defaultLang && !contains(languages, lang) {
+			languages = append(languages, lang)
+		}
+	}
+	return languages
+}
+
+// IsSupported reports whether posts can be written in lang
+func IsSupported(lang string) bool {
+	return contains(Supported(), Normalize(lang))
+}
+
+// Normalize lowercases a language tag and uses hyphens as separators, so that
+// "pt_BR" and "pt-br" are the same language
+func Normalize(lang string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(lang)), "_", "-")
+}
+
+// ParseAcceptLanguage returns the languages of an Accept-Language header, most
+// preferred first. Languages with q=0 and the "*" wildcard are left out.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		lang    string
+		quality float64
+	}
+
+	var ranges []weighted
+	for _, part := range strings.Split(header, ",") {
+		lang, params, _ := strings.Cut(part, ";")
+		lang = Normalize(lang)
+		if lang == "" || lang == "*" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "q") {
+				parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+				if err != nil || parsed < 0 || parsed > 1 {
+					parsed = 0
+				}
+				quality = parsed
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, weighted{lang, quality})
+		}
+	}
+
+	// Equal weights keep the order of the header
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].quality > ranges[j].quality })
+
+	languages := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		languages = append(languages, r.lang)
+	}
+	return languages
+}
+
+// Match returns the available language that best fits the preferences, which
+// are ordered most preferred first. A preference matches a language exactly or
+// by its primary subtag, so "pt-br" matches "pt" and "pt" matches "pt-br" when
+// nothing matches exactly. It returns false when no preference matches.
+func Match(preferences, available []string) (string, bool) {
+	for _, preference := range preferences {
+		preference = Normalize(preference)
+		if contains(available, preference) {
+			return preference, true
+		}
+		for _, lang := range available {
+			if primary(lang) == primary(preference) {
+				return lang, true
+			}
+		}
+	}
+	return "", false
+}
+
+// primary returns the primary subtag of a language tag, e.g. "pt" for "pt-br"
+func primary(lang string) string {
+	base, _, _ := strings.Cut(Normalize(lang), "-")
+	return base
+}
+
+func contains(languages []string, lang string) bool {
+	for _, candidate := range languages {
+		if candidate == lang {
+			return true
+		}
+	}
+	return false
+}
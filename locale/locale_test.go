@@ -0,0 +1,68 @@
+package locale
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupported(t *testing.T) {
+	t.Setenv("DEFAULT_LANGUAGE", "")
+	t.Setenv("SUPPORTED_LANGUAGES", "")
+	assert.Equal(t, "en", Default())
+	assert.Equal(t, []string{"en", "pt"}, Supported())
+
+	t.Setenv("DEFAULT_LANGUAGE", "pt_BR")
+	t.Setenv("SUPPORTED_LANGUAGES", "en, EN ,fr")
+	assert.Equal(t, "pt-br", Default())
+	assert.Equal(t, []string{"pt-br", "en", "fr"}, Supported(), "The default language is always supported")
+	assert.True(t, IsSupported("FR"))
+	assert.False(t, IsSupported("pt"))
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected []string
+	}{
+		{"", []string{}},
+		{"pt-BR", []string{"pt-br"}},
+		{"en;q=0.5, pt-BR, pt;q=0.9", []string{"pt-br", "pt", "en"}},
+		{"fr, de", []string{"fr", "de"}},
+		{"*, en;q=0, pt;q=0.1", []string{"pt"}},
+		{"en;q=abc, pt", []string{"pt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParseAcceptLanguage(tt.header))
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		preferences []string
+		available   []string
+		expected    string
+		found       bool
+	}{
+		{"exact", []string{"pt", "en"}, []string{"en", "pt"}, "pt", true},
+		{"first preference wins", []string{"en", "pt"}, []string{"pt", "en"}, "en", true},
+		{"region falls back to language", []string{"pt-br"}, []string{"en", "pt"}, "pt", true},
+		{"language matches a region", []string{"pt"}, []string{"en", "pt-br"}, "pt-br", true},
+		{"exact region beats fallback", []string{"pt-pt"}, []string{"pt-br", "pt-pt"}, "pt-pt", true},
+		{"later preference", []string{"fr", "en"}, []string{"en", "pt"}, "en", true},
+		{"no match", []string{"fr"}, []string{"en", "pt"}, "", false},
+		{"no preferences", nil, []string{"en"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lang, found := Match(tt.preferences, tt.available)
+			assert.Equal(t, tt.expected, lang)
+			assert.Equal(t, tt.found, found)
+		})
+	}
+}
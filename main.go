@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
+	"dbl-blog-backend/cli"
 	"dbl-blog-backend/database"
+	"dbl-blog-backend/grpcapi"
+	"dbl-blog-backend/newsletter"
+	"dbl-blog-backend/related"
 	"dbl-blog-backend/routes"
+	"dbl-blog-backend/webhooks"
 
 	"github.com/joho/godotenv"
 )
@@ -16,6 +22,11 @@ func main() {
 		log.Println("No .env file found, using system environment variables")
 	}
 
+	// Run a command-line command instead of the server when one is given
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run(os.Args[1:]))
+	}
+
 	log.Printf("Setting up database connection")
 
 	// Connect to database
@@ -24,9 +35,21 @@ func main() {
 	// Create indexes for better performance
 	database.CreateIndexes()
 
+	// Keep related post recommendations up to date in the background
+	related.Start(context.Background(), related.RebuildInterval())
+
+	// Send queued webhook deliveries in the background
+	webhooks.Start(context.Background())
+
+	// Send newly published posts to newsletter subscribers in the background
+	newsletter.Start(context.Background())
+
 	// Setup routes
 	router := routes.SetupRoutes()
 
+	// Serve the gRPC API on GRPC_PORT in the background
+	grpcapi.Start(router)
+
 	// Get port from environment or use default
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -0,0 +1,150 @@
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"dbl-blog-backend/locale"
+	"dbl-blog-backend/models"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatter is the metadata block at the top of a Markdown post, using the
+// field names understood by static site generators such as Hugo and Jekyll
+type FrontMatter struct {
+	Title     string    `yaml:"title" toml:"title"`
+	Slug      string    `yaml:"slug" toml:"slug"`
+	Lang      string    `yaml:"lang,omitempty" toml:"lang,omitempty"`
+	Summary   string    `yaml:"summary,omitempty" toml:"summary,omitempty"`
+	Tags      []string  `yaml:"tags,omitempty" toml:"tags,omitempty"`
+	Published *bool     `yaml:"published,omitempty" toml:"published,omitempty"`
+	Draft     *bool     `yaml:"draft,omitempty" toml:"draft,omitempty"` // Hugo's inverse of published, only read
+	Date      time.Time `yaml:"date" toml:"date"`
+	Lastmod   time.Time `yaml:"lastmod" toml:"lastmod"`
+	Views     int64     `yaml:"views" toml:"views"`
+	Likes     int64     `yaml:"likes" toml:"likes"`
+}
+
+// FileName returns the archive path of a post's Markdown file. Path separators in
+// the slug are replaced so every file lands directly in the posts directory. Posts
+// in other languages than the default one get a Hugo-style language suffix, since
+// translations may share a slug.
+func FileName(post models.Post) string {
+	name := strings.NewReplacer("/", "-", "\\", "-").Replace(post.Slug)
+	if strings.Trim(name, ".") == "" {
+		name = post.ID.Hex()
+	}
+	if post.Lang != "" && post.Lang != locale.Default() {
+		name += "." + post.Lang
+	}
+	return "posts/" + name + ".md"
+}
+
+// Encode writes a post as a Markdown document with YAML front matter
+func Encode(w io.Writer, post models.Post) error {
+	frontMatter := FrontMatter{
+		Title:     post.Title,
+		Slug:      post.Slug,
+		Lang:      post.Lang,
+		Summary:   post.Summary,
+		Tags:      post.Tags,
+		Published: &post.Published,
+		Date:      post.CreatedAt.UTC(),
+		Lastmod:   post.UpdatedAt.UTC(),
+		Views:     post.Views,
+		Likes:     post.Likes,
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(frontMatter); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	buf.WriteString("---\n\n")
+	buf.WriteString(post.Content)
+	if len(post.Content) > 0 && post.Content[len(post.Content)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Front matter delimiters: YAML as used by Jekyll and Hugo, TOML as used by Hugo
+const (
+	yamlDelimiter = "---"
+	tomlDelimiter = "+++"
+)
+
+// Decode parses a Markdown document with YAML or TOML front matter into a post.
+// Posts are published unless the front matter says otherwise, matching Hugo and
+// Jekyll. Missing dates are left zero; views and likes are only used for new posts.
+func Decode(data []byte) (models.Post, error) {
+	var post models.Post
+
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	firstLine, rest, _ := strings.Cut(text, "\n")
+	delimiter := strings.TrimSpace(firstLine)
+	if delimiter != yamlDelimiter && delimiter != tomlDelimiter {
+		return post, errors.New("missing front matter")
+	}
+
+	var header, content string
+	if strings.HasPrefix(rest, delimiter+"\n") || rest == delimiter {
+		header, content = "", strings.TrimPrefix(strings.TrimPrefix(rest, delimiter), "\n")
+	} else {
+		end := strings.Index(rest, "\n"+delimiter+"\n")
+		if end < 0 {
+			if !strings.HasSuffix(rest, "\n"+delimiter) {
+				return post, errors.New("unterminated front matter")
+			}
+			end = len(rest) - len(delimiter) - 1
+		}
+		header = rest[:end+1]
+		content = strings.TrimPrefix(rest[end+1+len(delimiter):], "\n")
+	}
+
+	var frontMatter FrontMatter
+	var err error
+	if delimiter == yamlDelimiter {
+		err = yaml.Unmarshal([]byte(header), &frontMatter)
+	} else {
+		err = toml.Unmarshal([]byte(header), &frontMatter)
+	}
+	if err != nil {
+		return post, fmt.Errorf("invalid front matter: %w", err)
+	}
+
+	post.Title = frontMatter.Title
+	post.Slug = frontMatter.Slug
+	post.Lang = frontMatter.Lang
+	post.Summary = frontMatter.Summary
+	post.Tags = frontMatter.Tags
+	post.Content = strings.Trim(content, "\n")
+	post.Published = true
+	if frontMatter.Draft != nil {
+		post.Published = !*frontMatter.Draft
+	}
+	if frontMatter.Published != nil {
+		post.Published = *frontMatter.Published
+	}
+	post.CreatedAt = frontMatter.Date
+	post.UpdatedAt = frontMatter.Lastmod
+	post.Views = frontMatter.Views
+	post.Likes = frontMatter.Likes
+
+	return post, nil
+}
@@ -0,0 +1,122 @@
+package markdown
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"dbl-blog-backend/models"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestEncode(t *testing.T) {
+	post := models.Post{
+		Title:     "Café & Go: Part 1",
+		Content:   "# Heading\n\nBody text",
+		Slug:      "cafe-go-part-1",
+		Tags:      []string{"go", "coffee"},
+		Published: true,
+		Views:     42,
+		Likes:     7,
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+	err := Encode(&buf, post)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `---
+title: 'Café & Go: Part 1'
+slug: cafe-go-part-1
+tags:
+  - go
+  - coffee
+published: true
+date: 2024-01-02T03:04:05Z
+lastmod: 2024-02-03T04:05:06Z
+views: 42
+likes: 7
+---
+
+# Heading
+
+Body text
+`, buf.String())
+}
+
+func TestFileName(t *testing.T) {
+	assert.Equal(t, "posts/my-post.md", FileName(models.Post{Slug: "my-post"}))
+	assert.Equal(t, "posts/..-etc-passwd.md", FileName(models.Post{Slug: "../etc/passwd"}))
+
+	post := models.Post{ID: primitive.NewObjectID(), Slug: ".."}
+	assert.Equal(t, "posts/"+post.ID.Hex()+".md", FileName(post))
+
+	t.Setenv("DEFAULT_LANGUAGE", "en")
+	assert.Equal(t, "posts/my-post.md", FileName(models.Post{Slug: "my-post", Lang: "en"}))
+	assert.Equal(t, "posts/my-post.pt.md", FileName(models.Post{Slug: "my-post", Lang: "pt"}), "Translations sharing a slug get distinct files")
+}
+
+func TestDecode_YAML(t *testing.T) {
+	post, err := Decode([]byte("---\ntitle: Hello\nslug: hello\ntags: [go, web]\ndraft: true\ndate: 2024-01-02\n---\n\nBody\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", post.Title)
+	assert.Equal(t, "hello", post.Slug)
+	assert.Equal(t, []string{"go", "web"}, post.Tags)
+	assert.False(t, post.Published, "Hugo drafts should not be published")
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), post.CreatedAt.UTC())
+	assert.True(t, post.UpdatedAt.IsZero())
+	assert.Equal(t, "Body", post.Content)
+}
+
+func TestDecode_TOML(t *testing.T) {
+	post, err := Decode([]byte("+++\ntitle = \"Hello TOML\"\ntags = [\"hugo\"]\ndate = 2023-05-06T07:08:09Z\n+++\nBody"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello TOML", post.Title)
+	assert.Equal(t, []string{"hugo"}, post.Tags)
+	assert.True(t, post.Published, "Posts are published unless marked otherwise")
+	assert.Equal(t, time.Date(2023, 5, 6, 7, 8, 9, 0, time.UTC), post.CreatedAt.UTC())
+	assert.Equal(t, "Body", post.Content)
+}
+
+func TestDecode_RoundTrip(t *testing.T) {
+	original := models.Post{
+		Title:     "Round Trip",
+		Content:   "Some *markdown*",
+		Slug:      "round-trip",
+		Tags:      []string{"go"},
+		Views:     3,
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Encode(&buf, original))
+
+	decoded, err := Decode(buf.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, original, decoded)
+}
+
+func TestDecode_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"no front matter", "# Just markdown\n"},
+		{"unterminated", "---\ntitle: Hello\n\nBody\n"},
+		{"invalid yaml", "---\ntitle: [unclosed\n---\nBody\n"},
+		{"invalid toml", "+++\ntitle = \n+++\nBody\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode([]byte(tt.data))
+			assert.Error(t, err)
+		})
+	}
+}
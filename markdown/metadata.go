@@ -0,0 +1,161 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"dbl-blog-backend/models"
+)
+
+// WordsPerMinute is the reading speed used for reading time estimates
+const WordsPerMinute = 200
+
+// fallbackAnchor is used for headings without any letters or digits
+const fallbackAnchor = "section"
+
+var (
+	atxHeading      = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextUnderline = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	codeFence       = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	notParagraph    = regexp.MustCompile(`^ {0,3}(?:[-+*]|\d{1,9}[.)])(?:[ \t]|$)|^ {0,3}>|^ {0,3}\|`) // List items, quotes and tables
+
+	image          = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	inlineLink     = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	referenceLink  = regexp.MustCompile(`\[([^\]]*)\]\[[^\]]*\]`)
+	htmlTag        = regexp.MustCompile(`<[^>]+>`)
+	escapedChar    = regexp.MustCompile("\\\\([!-/:-@\\[-`{-~])")
+	emphasis       = regexp.MustCompile("\\*+|~~|`+")
+	leadingUnders  = regexp.MustCompile(`(^|[\s(])_+`)
+	trailingUnders = regexp.MustCompile(`_+($|[\s).,:;!?])`)
+)
+
+// ApplyMetadata sets the word count, reading time and table of contents of a post
+// from its content
+func ApplyMetadata(post *models.Post) {
+	post.WordCount = WordCount(post.Content)
+	post.ReadingTimeMinutes = ReadingTime(post.WordCount)
+	post.TOC = TableOfContents(post.Content)
+}
+
+// WordCount counts the words of Markdown content, including code. Link targets,
+// image sources and HTML tags are not counted.
+func WordCount(content string) int {
+	count := 0
+	for _, field := range strings.Fields(plainText(content)) {
+		if strings.IndexFunc(field, isWordRune) >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// ReadingTime estimates the reading time in whole minutes, at least one for any words
+func ReadingTime(words int) int {
+	return (words + WordsPerMinute - 1) / WordsPerMinute
+}
+
+// TableOfContents lists the ATX (# Heading) and setext (underlined) headings of
+// Markdown content in order, ignoring code blocks. Anchor IDs follow GitHub's
+// scheme, so "Hello, World!" becomes "hello-world", and repeated headings get
+// a numeric suffix.
+func TableOfContents(content string) []models.TOCEntry {
+	toc := []models.TOCEntry{}
+	anchors := make(map[string]int)
+	add := func(level int, raw string) {
+		text := plainText(raw)
+		if text == "" {
+			return
+		}
+		toc = append(toc, models.TOCEntry{Level: level, Text: text, ID: uniqueAnchor(anchors, text)})
+	}
+
+	var fence string
+	var paragraph string
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if fence != "" {
+			if marker := codeFence.FindStringSubmatch(line); marker != nil &&
+				marker[1][0] == fence[0] && len(marker[1]) >= len(fence) && strings.TrimSpace(line[len(marker[0]):]) == "" {
+				fence = ""
+			}
+			continue
+		}
+		if marker := codeFence.FindStringSubmatch(line); marker != nil {
+			fence = marker[1]
+			paragraph = ""
+			continue
+		}
+
+		if match := atxHeading.FindStringSubmatch(line); match != nil {
+			add(len(match[1]), match[2])
+			paragraph = ""
+			continue
+		}
+		if match := setextUnderline.FindStringSubmatch(line); match != nil && paragraph != "" {
+			level := 2
+			if match[1][0] == '=' {
+				level = 1
+			}
+			add(level, paragraph)
+			paragraph = ""
+			continue
+		}
+
+		switch {
+		case strings.TrimSpace(line) == "", notParagraph.MatchString(line):
+			paragraph = ""
+		case paragraph == "" && strings.HasPrefix(line, "    "):
+			// Indented code can't become a heading
+		default:
+			// Only the last line of a multi-line paragraph becomes the heading
+			paragraph = line
+		}
+	}
+	return toc
+}
+
+// plainText strips inline Markdown and HTML from text, keeping link and image
+// text, and collapses whitespace
+func plainText(text string) string {
+	text = image.ReplaceAllString(text, "$1")
+	text = inlineLink.ReplaceAllString(text, "$1")
+	text = referenceLink.ReplaceAllString(text, "$1")
+	text = htmlTag.ReplaceAllString(text, "")
+	text = emphasis.ReplaceAllString(text, "")
+	text = leadingUnders.ReplaceAllString(text, "$1")
+	text = trailingUnders.ReplaceAllString(text, "$1")
+	text = escapedChar.ReplaceAllString(text, "$1")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// uniqueAnchor returns the anchor ID of a heading, suffixed with a counter when
+// an earlier heading has the same one
+func uniqueAnchor(seen map[string]int, text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == ' ':
+			b.WriteByte('-')
+		case r == '-' || r == '_' || isWordRune(r) || unicode.IsMark(r):
+			b.WriteRune(r)
+		}
+	}
+	anchor := b.String()
+	if strings.Trim(anchor, "-_") == "" {
+		anchor = fallbackAnchor
+	}
+
+	base := anchor
+	for _, taken := seen[anchor]; taken; _, taken = seen[anchor] {
+		seen[base]++
+		anchor = base + "-" + strconv.Itoa(seen[base])
+	}
+	seen[anchor] = 0
+	return anchor
+}
+
+// isWordRune reports whether r is a letter or digit
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
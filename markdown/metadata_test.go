@@ -0,0 +1,110 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"dbl-blog-backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWordCount(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected int
+	}{
+		{"plain words", "Hello brave new world", 4},
+		{"markup is not counted", "# Title\n\n**Bold** and _italic_ - done", 5},
+		{"link targets are not counted", "Read [the docs](https://go.dev/doc/) now", 4},
+		{"image alt text is counted", "![A diagram](diagram.png)", 2},
+		{"HTML tags are not counted", "<p class=\"intro\">Hi there</p>", 2},
+		{"code is counted", "```go\nfmt.Println(x)\n```", 2},
+		{"hyphenated words count once", "A well-known fact", 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, WordCount(tc.content))
+		})
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	assert.Equal(t, 0, ReadingTime(0))
+	assert.Equal(t, 1, ReadingTime(1))
+	assert.Equal(t, 1, ReadingTime(WordsPerMinute))
+	assert.Equal(t, 2, ReadingTime(WordsPerMinute+1))
+}
+
+func TestTableOfContents(t *testing.T) {
+	content := strings.Join([]string{
+		"# Getting Started",
+		"",
+		"Intro paragraph.",
+		"",
+		"## Install *Go* ##",
+		"",
+		"```bash",
+		"# not a heading",
+		"```",
+		"",
+		"Setext Heading",
+		"--------------",
+		"",
+		"Top Level",
+		"===",
+		"",
+		"### [Links](https://example.com) & `code`",
+		"",
+		"## Install Go",
+		"",
+		"- list item",
+		"---",
+		"",
+		"#hashtag is not a heading",
+		"",
+		"    # indented code",
+		"",
+		"###### C#",
+		"## ",
+	}, "\n")
+
+	expected := []models.TOCEntry{
+		{Level: 1, Text: "Getting Started", ID: "getting-started"},
+		{Level: 2, Text: "Install Go", ID: "install-go"},
+		{Level: 2, Text: "Setext Heading", ID: "setext-heading"},
+		{Level: 1, Text: "Top Level", ID: "top-level"},
+		{Level: 3, Text: "Links & code", ID: "links--code"},
+		{Level: 2, Text: "Install Go", ID: "install-go-1"},
+		{Level: 6, Text: "C#", ID: "c"},
+	}
+	assert.Equal(t, expected, TableOfContents(content))
+}
+
+func TestTableOfContents_Empty(t *testing.T) {
+	toc := TableOfContents("Just text")
+	assert.NotNil(t, toc, "Posts without headings get an empty list")
+	assert.Empty(t, toc)
+}
+
+func TestUniqueAnchor(t *testing.T) {
+	seen := map[string]int{}
+	assert.Equal(t, "hello-world", uniqueAnchor(seen, "Hello, World!"))
+	assert.Equal(t, "hello-world-1", uniqueAnchor(seen, "Hello World"))
+	assert.Equal(t, "hello-world-1-1", uniqueAnchor(seen, "Hello World 1"))
+	assert.Equal(t, "hello-world-2", uniqueAnchor(seen, "Hello world"))
+	assert.Equal(t, "café-déjà-vu", uniqueAnchor(seen, "Café déjà vu"))
+	assert.Equal(t, "section", uniqueAnchor(seen, "🚀"))
+	assert.Equal(t, "section-1", uniqueAnchor(seen, "!!!"))
+}
+
+func TestApplyMetadata(t *testing.T) {
+	post := models.Post{Content: "## Intro\n\n" + strings.Repeat("word ", 450)}
+	ApplyMetadata(&post)
+
+	assert.Equal(t, 451, post.WordCount)
+	assert.Equal(t, 3, post.ReadingTimeMinutes)
+	assert.Equal(t, []models.TOCEntry{{Level: 2, Text: "Intro", ID: "intro"}}, post.TOC)
+}
@@ -0,0 +1,182 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // Registers the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Registers the WebP decoder
+)
+
+// MaxImagePixels limits the dimensions of uploaded images, so that a small but
+// highly compressed file can't exhaust memory when decoded
+const MaxImagePixels = 40_000_000
+
+// jpegQuality is used when encoding resized JPEG variants
+const jpegQuality = 85
+
+// allowedTypes maps the content types accepted for upload to their file extension.
+// HTML and SVG are deliberately excluded since browsers run scripts embedded in them.
+var allowedTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+	"video/mp4":       ".mp4",
+}
+
+// variantWidths are the widths of the resized variants generated for images.
+// Images are never upscaled, so smaller images get fewer variants.
+var variantWidths = []struct {
+	name  string
+	width int
+}{
+	{"small", 320},
+	{"medium", 800},
+	{"large", 1600},
+}
+
+// ErrImageTooLarge is returned for images with more than MaxImagePixels pixels
+var ErrImageTooLarge = fmt.Errorf("image exceeds %d pixels", MaxImagePixels)
+
+// Variant is a rendition of an uploaded image
+type Variant struct {
+	Name        string
+	ContentType string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+// Sniff detects the content type of an upload from its first bytes, ignoring any
+// type claimed by the client, and returns its file extension. It reports false
+// for types that can't be uploaded.
+func Sniff(data []byte) (contentType, extension string, ok bool) {
+	contentType = http.DetectContentType(data)
+	extension, ok = allowedTypes[contentType]
+	return contentType, extension, ok
+}
+
+// IsImage reports whether variants are generated for a content type
+func IsImage(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	}
+	return false
+}
+
+// Extension returns the file extension used for a content type
+func Extension(contentType string) string {
+	return allowedTypes[contentType]
+}
+
+// Variants decodes an image and renders its variants: a resized copy for every
+// variant width below the image's width, in the original format (GIFs become PNGs,
+// using their first frame), and a lossless WebP copy of every resized copy (named
+// "<name>-webp") and of the image itself (named "webp") unless it is wider than the
+// largest variant. WebP images only get resized copies. The dimensions of the
+// original image are returned with the variants.
+func Variants(data []byte, contentType string) (width, height int, variants []Variant, err error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("image could not be decoded: %w", err)
+	}
+	if int64(config.Width)*int64(config.Height) > MaxImagePixels {
+		return 0, 0, nil, ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("image could not be decoded: %w", err)
+	}
+	bounds := img.Bounds()
+	width, height = bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return 0, 0, nil, errors.New("image has no pixels")
+	}
+
+	resizedType := contentType
+	if contentType == "image/gif" {
+		resizedType = "image/png"
+	}
+
+	if contentType != "image/webp" && width <= variantWidths[len(variantWidths)-1].width {
+		variant, err := encodeVariant("webp", "image/webp", img)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		variants = append(variants, variant)
+	}
+
+	for _, size := range variantWidths {
+		if size.width >= width {
+			break
+		}
+		resized := resize(img, size.width)
+
+		variant, err := encodeVariant(size.name, resizedType, resized)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		variants = append(variants, variant)
+
+		if resizedType != "image/webp" {
+			variant, err = encodeVariant(size.name+"-webp", "image/webp", resized)
+			if err != nil {
+				return 0, 0, nil, err
+			}
+			variants = append(variants, variant)
+		}
+	}
+
+	return width, height, variants, nil
+}
+
+// resize scales an image to a width, keeping its aspect ratio
+func resize(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// encodeVariant encodes an image as a variant of the given content type
+func encodeVariant(name, contentType string, img image.Image) (Variant, error) {
+	var buf bytes.Buffer
+	var err error
+	switch contentType {
+	case "image/jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	case "image/png":
+		err = png.Encode(&buf, img)
+	case "image/webp":
+		err = nativewebp.Encode(&buf, img, nil)
+	default:
+		err = fmt.Errorf("cannot encode %s", contentType)
+	}
+	if err != nil {
+		return Variant{}, fmt.Errorf("failed to encode %s variant: %w", name, err)
+	}
+
+	bounds := img.Bounds()
+	return Variant{
+		Name:        name,
+		ContentType: contentType,
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+		Data:        buf.Bytes(),
+	}, nil
+}
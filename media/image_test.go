@@ -0,0 +1,117 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/webp"
+)
+
+// Unit tests for upload sniffing and image variants
+
+func testImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestSniff(t *testing.T) {
+	contentType, extension, ok := Sniff(encodePNG(t, testImage(2, 2)))
+	assert.True(t, ok)
+	assert.Equal(t, "image/png", contentType)
+	assert.Equal(t, ".png", extension)
+
+	_, extension, ok = Sniff([]byte("%PDF-1.7\n"))
+	assert.True(t, ok)
+	assert.Equal(t, ".pdf", extension)
+
+	for _, data := range []string{
+		"<html><script>alert(1)</script></html>",
+		`<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`,
+		"plain text",
+	} {
+		_, _, ok = Sniff([]byte(data))
+		assert.False(t, ok, "Rejected: %s", data)
+	}
+}
+
+func variantNames(variants []Variant) []string {
+	names := []string{}
+	for _, variant := range variants {
+		names = append(names, variant.Name)
+	}
+	return names
+}
+
+func TestVariants_PNG(t *testing.T) {
+	width, height, variants, err := Variants(encodePNG(t, testImage(1000, 500)), "image/png")
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, width)
+	assert.Equal(t, 500, height)
+	assert.Equal(t, []string{"webp", "small", "small-webp", "medium", "medium-webp"}, variantNames(variants),
+		"Images are not upscaled to the large width")
+
+	small := variants[1]
+	assert.Equal(t, "image/png", small.ContentType)
+	assert.Equal(t, 320, small.Width)
+	assert.Equal(t, 160, small.Height)
+	decoded, err := png.Decode(bytes.NewReader(small.Data))
+	assert.NoError(t, err)
+	assert.Equal(t, 320, decoded.Bounds().Dx())
+
+	smallWebP := variants[2]
+	assert.Equal(t, "image/webp", smallWebP.ContentType)
+	decoded, err = webp.Decode(bytes.NewReader(smallWebP.Data))
+	assert.NoError(t, err)
+	assert.Equal(t, 320, decoded.Bounds().Dx())
+}
+
+func TestVariants_Formats(t *testing.T) {
+	var jpegData bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&jpegData, testImage(400, 300), nil))
+	_, _, variants, err := Variants(jpegData.Bytes(), "image/jpeg")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"webp", "small", "small-webp"}, variantNames(variants))
+	assert.Equal(t, "image/jpeg", variants[1].ContentType)
+
+	var gifData bytes.Buffer
+	assert.NoError(t, gif.Encode(&gifData, testImage(400, 300), nil))
+	_, _, variants, err = Variants(gifData.Bytes(), "image/gif")
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", variants[1].ContentType, "GIFs are resized to PNG")
+
+	_, _, variants, err = Variants(encodePNG(t, testImage(100, 100)), "image/png")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"webp"}, variantNames(variants), "Small images only get a WebP copy")
+}
+
+func TestVariants_Rejects(t *testing.T) {
+	_, _, _, err := Variants([]byte("\x89PNG\r\n\x1a\ncorrupt"), "image/png")
+	assert.Error(t, err)
+
+	// A header claiming 10000x10000 pixels is rejected before decoding the pixels
+	header := encodePNG(t, testImage(1, 1))
+	header[16], header[17], header[18], header[19] = 0, 0, 0x27, 0x10
+	header[20], header[21], header[22], header[23] = 0, 0, 0x27, 0x10
+	binary.BigEndian.PutUint32(header[29:33], crc32.ChecksumIEEE(header[12:29]))
+	_, _, _, err = Variants(header, "image/png")
+	assert.Equal(t, ErrImageTooLarge, err)
+}
@@ -0,0 +1,98 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultMaxUploadBytes is the upload size limit when MEDIA_MAX_UPLOAD_BYTES is not set
+const DefaultMaxUploadBytes = 10 << 20
+
+// ErrNotFound is returned by storage backends for objects that do not exist
+var ErrNotFound = errors.New("media object not found")
+
+// Storage persists media objects under slash-separated keys such as "<id>/original.jpg".
+// Objects are immutable: a key is written once and only ever read or deleted afterwards.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+var (
+	defaultOnce    sync.Once
+	defaultStorage Storage
+	defaultErr     error
+)
+
+// Default returns the storage backend selected by MEDIA_STORAGE: "local" (the
+// default) stores files under MEDIA_LOCAL_DIR, and "s3" stores them in an
+// S3-compatible bucket configured by the S3_* variables
+func Default() (Storage, error) {
+	defaultOnce.Do(func() {
+		defaultStorage, defaultErr = storageFromEnv()
+	})
+	return defaultStorage, defaultErr
+}
+
+// storageFromEnv creates the storage backend configured in the environment
+func storageFromEnv() (Storage, error) {
+	switch backend := os.Getenv("MEDIA_STORAGE"); backend {
+	case "", "local":
+		dir := os.Getenv("MEDIA_LOCAL_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		return NewLocalStorage(dir), nil
+	case "s3":
+		return NewS3Storage(S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		})
+	default:
+		return nil, fmt.Errorf("unknown MEDIA_STORAGE '%s': use local or s3", backend)
+	}
+}
+
+// PublicURL returns the URL an object is publicly readable at when MEDIA_PUBLIC_URL
+// is set (e.g. a CDN or a public bucket), or "" when media are served by the API
+func PublicURL(key string) string {
+	base := strings.TrimRight(os.Getenv("MEDIA_PUBLIC_URL"), "/")
+	if base == "" {
+		return ""
+	}
+	return base + "/" + key
+}
+
+// MaxUploadBytes returns the upload size limit from MEDIA_MAX_UPLOAD_BYTES
+func MaxUploadBytes() int64 {
+	if value := os.Getenv("MEDIA_MAX_UPLOAD_BYTES"); value != "" {
+		if limit, err := strconv.ParseInt(value, 10, 64); err == nil && limit > 0 {
+			return limit
+		}
+	}
+	return DefaultMaxUploadBytes
+}
+
+// validKey reports whether key is a relative slash-separated path without empty,
+// "." or ".." segments, so it can't escape the storage root
+func validKey(key string) bool {
+	if key == "" || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package media
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Unit tests for storage configuration and the local filesystem backend
+
+func TestValidKey(t *testing.T) {
+	testCases := []struct {
+		key      string
+		expected bool
+	}{
+		{"0123/original.jpg", true},
+		{"file.png", true},
+		{"", false},
+		{"/etc/passwd", false},
+		{"../outside.jpg", false},
+		{"a/../../b", false},
+		{"a//b", false},
+		{"a/./b", false},
+		{"a\\b", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.key, func(t *testing.T) {
+			assert.Equal(t, tc.expected, validKey(tc.key))
+		})
+	}
+}
+
+func TestLocalStorage_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewLocalStorage(dir)
+	ctx := context.Background()
+
+	assert.NoError(t, storage.Put(ctx, "abc/original.txt", []byte("hello"), "text/plain"))
+
+	reader, err := storage.Open(ctx, "abc/original.txt")
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(reader)
+	_ = reader.Close()
+	assert.Equal(t, "hello", string(data))
+
+	entries, _ := os.ReadDir(filepath.Join(dir, "abc"))
+	assert.Len(t, entries, 1, "Temporary files are renamed into place")
+
+	assert.NoError(t, storage.Delete(ctx, "abc/original.txt"))
+	_, err = storage.Open(ctx, "abc/original.txt")
+	assert.Equal(t, ErrNotFound, err)
+	_, err = os.Stat(filepath.Join(dir, "abc"))
+	assert.True(t, os.IsNotExist(err), "Empty object directories are removed")
+
+	assert.NoError(t, storage.Delete(ctx, "abc/original.txt"), "Deleting a missing object is not an error")
+}
+
+func TestLocalStorage_RejectsEscapingKeys(t *testing.T) {
+	storage := NewLocalStorage(t.TempDir())
+
+	assert.Error(t, storage.Put(context.Background(), "../escape.txt", []byte("x"), "text/plain"))
+	_, err := storage.Open(context.Background(), "../../etc/passwd")
+	assert.Error(t, err)
+}
+
+func TestStorageFromEnv(t *testing.T) {
+	t.Setenv("MEDIA_STORAGE", "")
+	t.Setenv("MEDIA_LOCAL_DIR", "/tmp/media")
+	storage, err := storageFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/media", storage.(*LocalStorage).dir)
+
+	t.Setenv("MEDIA_STORAGE", "s3")
+	t.Setenv("S3_ENDPOINT", "")
+	_, err = storageFromEnv()
+	assert.Error(t, err, "S3 settings are required")
+
+	t.Setenv("MEDIA_STORAGE", "ftp")
+	_, err = storageFromEnv()
+	assert.Error(t, err)
+}
+
+func TestPublicURLAndMaxUploadBytes(t *testing.T) {
+	t.Setenv("MEDIA_PUBLIC_URL", "")
+	assert.Equal(t, "", PublicURL("abc/original.jpg"))
+
+	t.Setenv("MEDIA_PUBLIC_URL", "https://cdn.example.com/media/")
+	assert.Equal(t, "https://cdn.example.com/media/abc/original.jpg", PublicURL("abc/original.jpg"))
+
+	t.Setenv("MEDIA_MAX_UPLOAD_BYTES", "")
+	assert.Equal(t, int64(DefaultMaxUploadBytes), MaxUploadBytes())
+	t.Setenv("MEDIA_MAX_UPLOAD_BYTES", "1024")
+	assert.Equal(t, int64(1024), MaxUploadBytes())
+	t.Setenv("MEDIA_MAX_UPLOAD_BYTES", "-5")
+	assert.Equal(t, int64(DefaultMaxUploadBytes), MaxUploadBytes())
+}
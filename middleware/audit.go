@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"dbl-blog-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewAuditEntry returns an audit entry for action populated with the actor and
+// request details of the current request
+func NewAuditEntry(c *gin.Context, action string) models.AuditEntry {
+	return models.AuditEntry{
+		Action:     action,
+		ActorKeyID: c.GetString(ContextAPIKeyID),
+		ActorRole:  c.GetString(ContextRole),
+		ClientIP:   c.ClientIP(),
+		RequestID:  c.GetString(ContextRequestID),
+		Method:     c.Request.Method,
+		Path:       c.Request.URL.Path,
+	}
+}
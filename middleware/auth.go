@@ -1,29 +1,43 @@
 package middleware
 
 import (
+	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/hex"
 	"log"
 	"net/http"
 	"os"
 	"strings"
 
 	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/audit"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AdminAuthMiddleware validates admin API key with enhanced security features
-func AdminAuthMiddleware() gin.HandlerFunc {
+// Gin context keys set by RequirePermission for authenticated requests
+const (
+	ContextAPIKeyID = "api_key_id" // ID of the authenticated API key
+	ContextRole     = "role"       // Role granted to the authenticated API key
+)
+
+// APIKeyID derives a stable, non-secret identifier for an API key so that
+// credentials can be referenced (e.g. from author profiles) without storing the key itself
+func APIKeyID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// RequirePermission authenticates the API key and requires its role to be granted
+// at least one of the given permissions
+func RequirePermission(permissions ...Permission) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		clientIP := c.ClientIP()
 
-		log.Printf("[INFO] AdminAuth: Checking authorization for %s %s from %s", c.Request.Method, c.Request.URL.Path, clientIP)
+		log.Printf("[INFO] Auth: Checking authorization for %s %s from %s", c.Request.Method, c.Request.URL.Path, clientIP)
 
-		// Get API keys from environment (comma-separated for multiple keys)
-		adminAPIKeys := os.Getenv("ADMIN_API_KEYS")
-
-		if adminAPIKeys == "" {
-			log.Printf("[ERROR] AdminAuth: No admin API keys configured")
+		if !apiKeysConfigured() {
+			log.Printf("[ERROR] Auth: No API keys configured")
 			apierrors.RespondWithCustomError(c, http.StatusInternalServerError, "SERVER_MISCONFIGURATION", "Server configuration error", "Admin API keys not configured")
 			c.Abort()
 			return
@@ -32,31 +46,79 @@ func AdminAuthMiddleware() gin.HandlerFunc {
 		// Get X-API-Key header
 		providedKey := c.GetHeader("X-API-Key")
 		if providedKey == "" {
-			log.Printf("[SECURITY] AdminAuth: Missing X-API-Key header from %s", clientIP)
+			log.Printf("[SECURITY] Auth: Missing X-API-Key header from %s", clientIP)
+			recordAuthFailure(c, "missing API key")
 			apierrors.RespondMissingAuthorization(c)
 			c.Abort()
 			return
 		}
-		// Validate against all configured API keys using constant-time comparison
-		validKeys := strings.Split(adminAPIKeys, ",")
-		isValid := false
 
-		for _, validKey := range validKeys {
-			validKey = strings.TrimSpace(validKey)
-			if validKey != "" && subtle.ConstantTimeCompare([]byte(providedKey), []byte(validKey)) == 1 {
-				isValid = true
-				break
-			}
-		}
+		// Only the non-secret key ID is ever logged or audited, never the key itself
+		keyID := APIKeyID(providedKey)
+		c.Set(ContextAPIKeyID, keyID)
 
-		if !isValid {
-			log.Printf("[SECURITY] AdminAuth: Invalid API key attempt from %s (key: %s...)", clientIP, providedKey[:min(8, len(providedKey))])
+		role, ok := roleForKey(providedKey)
+		if !ok {
+			log.Printf("[SECURITY] Auth: Invalid API key attempt from %s (key ID: %s)", clientIP, keyID)
+			recordAuthFailure(c, "invalid API key")
 			apierrors.RespondInvalidAPIKey(c)
 			c.Abort()
 			return
 		}
 
-		log.Printf("[SUCCESS] AdminAuth: Valid API key for %s %s from %s", c.Request.Method, c.Request.URL.Path, clientIP)
-		c.Next()
+		c.Set(ContextRole, string(role))
+
+		for _, permission := range permissions {
+			if HasPermission(role, permission) {
+				log.Printf("[SUCCESS] Auth: Valid %s API key for %s %s from %s", role, c.Request.Method, c.Request.URL.Path, clientIP)
+				c.Next()
+				return
+			}
+		}
+
+		log.Printf("[SECURITY] Auth: Role '%s' denied %s %s from %s", role, c.Request.Method, c.Request.URL.Path, clientIP)
+		recordAuthFailure(c, "insufficient permissions")
+		apierrors.RespondForbidden(c)
+		c.Abort()
 	})
 }
+
+// recordAuthFailure appends an authentication or authorization failure to the audit log
+func recordAuthFailure(c *gin.Context, reason string) {
+	entry := NewAuditEntry(c, audit.ActionAuthFailure)
+	entry.Reason = reason
+	audit.Record(entry)
+}
+
+// Authenticate resolves the role of an API key, for servers other than gin's
+// such as the gRPC server. It fails when the key is unknown or no key is configured.
+func Authenticate(providedKey string) (Role, bool) {
+	if providedKey == "" || !apiKeysConfigured() {
+		return "", false
+	}
+	return roleForKey(providedKey)
+}
+
+// apiKeysConfigured reports whether any role has API keys configured
+func apiKeysConfigured() bool {
+	for _, entry := range roleKeyEnvVars {
+		if strings.TrimSpace(os.Getenv(entry.envVar)) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// roleForKey resolves the role of an API key using constant-time comparison
+// against the keys configured for every role
+func roleForKey(providedKey string) (Role, bool) {
+	for _, entry := range roleKeyEnvVars {
+		for _, validKey := range strings.Split(os.Getenv(entry.envVar), ",") {
+			validKey = strings.TrimSpace(validKey)
+			if validKey != "" && subtle.ConstantTimeCompare([]byte(providedKey), []byte(validKey)) == 1 {
+				return entry.role, true
+			}
+		}
+	}
+	return "", false
+}
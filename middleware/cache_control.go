@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Default Cache-Control policies used when no environment override is configured
+const (
+	defaultPublicCacheControl = "public, max-age=60, must-revalidate"
+	defaultAdminCacheControl  = "no-store"
+)
+
+// PublicCacheControl sets the Cache-Control policy for a public route. The policy is
+// read from CACHE_CONTROL_<route> (e.g. CACHE_CONTROL_POSTS_LIST), falling back to
+// PUBLIC_CACHE_CONTROL and then to a short shared cache lifetime.
+func PublicCacheControl(route string) gin.HandlerFunc {
+	return cacheControl("CACHE_CONTROL_"+route, "PUBLIC_CACHE_CONTROL", defaultPublicCacheControl)
+}
+
+// AdminCacheControl sets the Cache-Control policy for admin responses, read from
+// ADMIN_CACHE_CONTROL and defaulting to no-store so that no cache keeps them
+func AdminCacheControl() gin.HandlerFunc {
+	return cacheControl("ADMIN_CACHE_CONTROL", "ADMIN_CACHE_CONTROL", defaultAdminCacheControl)
+}
+
+// cacheControl sets the first configured of routeEnvVar, groupEnvVar and fallback
+// as the response's Cache-Control header
+func cacheControl(routeEnvVar, groupEnvVar, fallback string) gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		policy := os.Getenv(routeEnvVar)
+		if policy == "" {
+			policy = os.Getenv(groupEnvVar)
+		}
+		if policy == "" {
+			policy = fallback
+		}
+
+		c.Header("Cache-Control", policy)
+		c.Next()
+	})
+}
@@ -10,11 +10,31 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// sanitizerExemptParams are the query parameters of routes that aren't checked
+// for suspicious patterns. GraphQL documents declare variables such as $search,
+// and their values reach the resolvers as typed arguments, never as filters.
+var sanitizerExemptParams = map[string][]string{
+	"/api/v1/graphql": {"query", "variables", "extensions"},
+}
+
+// isSanitizerExempt reports whether a query parameter of a route isn't checked
+func isSanitizerExempt(route, key string) bool {
+	for _, exempt := range sanitizerExemptParams[route] {
+		if key == exempt {
+			return true
+		}
+	}
+	return false
+}
+
 // InputSanitizationMiddleware prevents potentially dangerous input
 func InputSanitizationMiddleware() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		// Check URL parameters for suspicious patterns
 		for key, values := range c.Request.URL.Query() {
+			if isSanitizerExempt(c.FullPath(), key) {
+				continue
+			}
 			for _, value := range values {
 				if containsSuspiciousPatterns(value) {
 					log.Printf("[SECURITY] Suspicious query parameter detected: %s=%s from %s", key, value, c.ClientIP())
@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/openapi"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPIValidationMiddleware validates requests against openapi/openapi.json,
+// rejecting those that don't match it with VALIDATION_FAILED. With
+// validateResponses, JSON responses are checked too and replaced by a 500
+// VALIDATION_FAILED error when they don't match, which is meant for development
+// as responses are buffered. Routes missing from the document aren't checked,
+// and only JSON bodies are: uploads and archives are left to their handlers.
+func OpenAPIValidationMiddleware(validateResponses bool) gin.HandlerFunc {
+	doc, err := openapi.Load()
+	if err != nil {
+		log.Fatal("Failed to load the OpenAPI document:", err)
+	}
+
+	return gin.HandlerFunc(func(c *gin.Context) {
+		pathItem, operation := openapi.Operation(doc, c.Request.Method, c.FullPath())
+		if operation == nil {
+			c.Next()
+			return
+		}
+
+		// API keys are checked by RequirePermission. Security requirements are
+		// dropped as validating them would read whole request bodies.
+		unsecured := *operation
+		unsecured.Security = &openapi3.SecurityRequirements{}
+
+		options := &openapi3filter.Options{
+			MultiError:          true,
+			SkipSettingDefaults: true,
+			ExcludeRequestBody:  !isJSONMediaType(c.GetHeader("Content-Type")),
+			ExcludeResponseBody: !validateResponses,
+		}
+		options.WithCustomSchemaErrorFunc(schemaErrorMessage)
+
+		pathParams := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			pathParams[param.Key] = param.Value
+		}
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route: &routers.Route{
+				Spec:      doc,
+				Path:      openapi.Path(c.FullPath()),
+				PathItem:  pathItem,
+				Method:    c.Request.Method,
+				Operation: &unsecured,
+			},
+			Options: options,
+		}
+
+		if err := openapi3filter.ValidateRequest(context.Background(), input); err != nil {
+			log.Printf("[ERROR] OpenAPI: Request %s %s doesn't match the spec from %s - %s", c.Request.Method, c.FullPath(), c.ClientIP(), err.Error())
+			apierrors.RespondWithValidationError(c, err.Error())
+			c.Abort()
+			return
+		}
+
+		if !validateResponses {
+			c.Next()
+			return
+		}
+
+		writer := &specResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if !writer.buffering {
+			return
+		}
+
+		response := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 c.Writer.Status(),
+			Header:                 c.Writer.Header(),
+			Options:                options,
+		}
+		response.SetBodyBytes(writer.body.Bytes())
+		if err := openapi3filter.ValidateResponse(context.Background(), response); err != nil {
+			log.Printf("[ERROR] OpenAPI: Response %d to %s %s doesn't match the spec - %s", c.Writer.Status(), c.Request.Method, c.FullPath(), err.Error())
+			apierrors.RespondWithCustomError(c, http.StatusInternalServerError, apierrors.CodeValidationFailed, "Response validation failed", err.Error())
+			return
+		}
+		_, _ = c.Writer.Write(writer.body.Bytes())
+	})
+}
+
+// schemaErrorMessage describes a schema mismatch by the JSON pointer of the
+// value and the reason, leaving out the schema and value dumped by default
+func schemaErrorMessage(err *openapi3.SchemaError) string {
+	if pointer := err.JSONPointer(); len(pointer) > 0 {
+		return "/" + strings.Join(pointer, "/") + ": " + err.Reason
+	}
+	return err.Reason
+}
+
+// isJSONMediaType reports whether a Content-Type is JSON, such as
+// application/json or application/merge-patch+json
+func isJSONMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || (strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
+
+// specResponseWriter holds back JSON responses for validation. Whether the
+// response is JSON is decided by its Content-Type on the first write; other
+// responses, such as media and export streams, are written through.
+type specResponseWriter struct {
+	gin.ResponseWriter
+	decided   bool
+	buffering bool
+	body      bytes.Buffer
+}
+
+// decide chooses whether to buffer the response, once its headers are set
+func (w *specResponseWriter) decide() {
+	if !w.decided {
+		w.decided = true
+		w.buffering = isJSONMediaType(w.Header().Get("Content-Type"))
+	}
+}
+
+func (w *specResponseWriter) Write(data []byte) (int, error) {
+	w.decide()
+	if w.buffering {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *specResponseWriter) WriteString(s string) (int, error) {
+	w.decide()
+	if w.buffering {
+		return w.body.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *specResponseWriter) WriteHeaderNow() {
+	w.decide()
+	if !w.buffering {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *specResponseWriter) Flush() {
+	w.decide()
+	if !w.buffering {
+		w.ResponseWriter.Flush()
+	}
+}
+
+func (w *specResponseWriter) Size() int {
+	if w.buffering {
+		return w.body.Len()
+	}
+	return w.ResponseWriter.Size()
+}
+
+func (w *specResponseWriter) Written() bool {
+	return w.decided || w.ResponseWriter.Written()
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"dbl-blog-backend/apierrors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Unit tests for validation against the OpenAPI document
+
+func openAPITestRouter(validateResponses bool, healthStatus interface{}) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(OpenAPIValidationMiddleware(validateResponses))
+
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": healthStatus, "message": "Blog API is running"})
+	})
+	router.GET("/api/v1/posts/:id/related", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"posts": []interface{}{}, "total": 0})
+	})
+	router.POST("/api/v1/newsletter/subscribe", func(c *gin.Context) {
+		c.JSON(http.StatusAccepted, gin.H{"message": "Check your inbox"})
+	})
+	router.POST("/api/v1/media", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/api/v1/media/:id", func(c *gin.Context) {
+		c.Data(http.StatusOK, "image/png", []byte("not JSON"))
+	})
+	router.GET("/undocumented", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"anything": true})
+	})
+	return router
+}
+
+func serve(router *gin.Engine, method, target, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func decodeAPIError(t *testing.T, w *httptest.ResponseRecorder) apierrors.APIError {
+	var response apierrors.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), w.Body.String())
+	return response.Error
+}
+
+func TestOpenAPIValidation_Requests(t *testing.T) {
+	router := openAPITestRouter(false, "ok")
+
+	w := serve(router, http.MethodGet, "/api/v1/posts/hello/related?limit=5", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = serve(router, http.MethodGet, "/api/v1/posts/hello/related?limit=50", "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	apiError := decodeAPIError(t, w)
+	assert.Equal(t, apierrors.CodeValidationFailed, apiError.Code)
+	assert.Contains(t, apiError.Details, `parameter "limit" in query`)
+
+	w = serve(router, http.MethodPost, "/api/v1/newsletter/subscribe", "application/json", `{"email":"reader@example.com","tags":["go"]}`)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	w = serve(router, http.MethodPost, "/api/v1/newsletter/subscribe", "application/json", `{"tags":"go"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	apiError = decodeAPIError(t, w)
+	assert.Equal(t, apierrors.CodeValidationFailed, apiError.Code)
+	assert.Contains(t, apiError.Details, "email")
+	assert.NotContains(t, apiError.Details, "Schema:", "Schema errors are reported without dumping the schema")
+}
+
+func TestOpenAPIValidation_SkipsNonJSONBodiesAndUndocumentedRoutes(t *testing.T) {
+	router := openAPITestRouter(true, "ok")
+
+	w := serve(router, http.MethodPost, "/api/v1/media", "multipart/form-data; boundary=x", "--x--")
+	assert.Equal(t, http.StatusNoContent, w.Code, "Uploads are left to their handler")
+
+	w = serve(router, http.MethodGet, "/undocumented", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"anything":true}`, w.Body.String())
+
+	w = serve(router, http.MethodGet, "/api/v1/media/507f1f77bcf86cd799439011", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "not JSON", w.Body.String(), "Non-JSON responses are written through")
+}
+
+func TestOpenAPIValidation_Responses(t *testing.T) {
+	w := serve(openAPITestRouter(true, "ok"), http.MethodGet, "/health", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok","message":"Blog API is running"}`, w.Body.String())
+
+	w = serve(openAPITestRouter(true, 1), http.MethodGet, "/health", "", "")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	apiError := decodeAPIError(t, w)
+	assert.Equal(t, apierrors.CodeValidationFailed, apiError.Code)
+	assert.Equal(t, "Response validation failed", apiError.Message)
+	assert.Contains(t, apiError.Details, "/status")
+
+	w = serve(openAPITestRouter(false, 1), http.MethodGet, "/health", "", "")
+	assert.Equal(t, http.StatusOK, w.Code, "Responses are only validated in development mode")
+}
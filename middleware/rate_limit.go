@@ -23,6 +23,8 @@ type RateLimiter struct {
 var (
 	adminRateLimiter  = &RateLimiter{requests: make(map[string][]time.Time)}
 	publicRateLimiter = &RateLimiter{requests: make(map[string][]time.Time)}
+
+	newsletterRateLimiter = &RateLimiter{requests: make(map[string][]time.Time)}
 )
 
 // getEnvInt gets an environment variable as integer with fallback
@@ -40,11 +42,40 @@ func AdminRateLimitMiddleware() gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		clientIP := c.ClientIP()
 
-		// Get rate limit from environment (default: 30 requests per minute)
-		maxRequests := getEnvInt("ADMIN_RATE_LIMIT_PER_MINUTE", 30)
+		if !AllowAdminRequest(clientIP) {
+			log.Printf("[SECURITY] AdminRateLimit: Rate limit exceeded for IP %s (%d requests/minute)", clientIP, adminRateLimit())
+			apierrors.RespondWithCustomError(c, http.StatusTooManyRequests, "RATE_LIMIT_EXCEEDED", "Too many requests", "Please wait before trying again")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	})
+}
+
+// AllowAdminRequest counts a request from clientIP against the admin rate limit,
+// reporting whether it is allowed. Servers other than gin's, such as the gRPC
+// server, share the limit through it.
+func AllowAdminRequest(clientIP string) bool {
+	return checkRateLimit(adminRateLimiter, clientIP, adminRateLimit(), time.Minute)
+}
+
+// adminRateLimit returns the admin requests allowed per minute and IP (default: 30)
+func adminRateLimit() int {
+	return getEnvInt("ADMIN_RATE_LIMIT_PER_MINUTE", 30)
+}
+
+// NewsletterRateLimitMiddleware limits subscribe requests, which send email,
+// whether or not public rate limiting is enabled
+func NewsletterRateLimitMiddleware() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		clientIP := c.ClientIP()
+
+		// Get rate limit from environment (default: 5 requests per minute)
+		maxRequests := getEnvInt("NEWSLETTER_RATE_LIMIT_PER_MINUTE", 5)
 
-		if !checkRateLimit(adminRateLimiter, clientIP, maxRequests, time.Minute) {
-			log.Printf("[SECURITY] AdminRateLimit: Rate limit exceeded for IP %s (%d requests/minute)", clientIP, maxRequests)
+		if !checkRateLimit(newsletterRateLimiter, clientIP, maxRequests, time.Minute) {
+			log.Printf("[INFO] NewsletterRateLimit: Rate limit exceeded for IP %s (%d requests/minute)", clientIP, maxRequests)
 			apierrors.RespondWithCustomError(c, http.StatusTooManyRequests, "RATE_LIMIT_EXCEEDED", "Too many requests", "Please wait before trying again")
 			c.Abort()
 			return
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Role identifies the level of access granted to an API key
+type Role string
+
+// Supported roles, each configured through its own comma-separated API key variable
+const (
+	RoleAdmin   Role = "admin"   // ADMIN_API_KEYS
+	RoleEditor  Role = "editor"  // EDITOR_API_KEYS
+	RoleAuthor  Role = "author"  // AUTHOR_API_KEYS
+	RoleAnalyst Role = "analyst" // ANALYST_API_KEYS
+)
+
+// Permission identifies a single protected operation
+type Permission string
+
+// Permissions enforced by RequirePermission and checked by handlers via Can
+const (
+	PermCreatePosts       Permission = "posts:create"
+	PermEditAnyPost       Permission = "posts:edit:any"
+	PermEditOwnPosts      Permission = "posts:edit:own"
+	PermDeleteAnyPost     Permission = "posts:delete:any"
+	PermDeleteOwnPosts    Permission = "posts:delete:own"
+	PermManageAuthors     Permission = "authors:manage"
+	PermReadAnalytics     Permission = "analytics:read"
+	PermReadAuditLog      Permission = "audit:read"
+	PermExportPosts       Permission = "posts:export"
+	PermImportPosts       Permission = "posts:import"
+	PermUploadMedia       Permission = "media:upload"
+	PermDeleteMedia       Permission = "media:delete"
+	PermManageSeries      Permission = "series:manage"
+	PermRebuildRelated    Permission = "related:rebuild"
+	PermManageWebhooks    Permission = "webhooks:manage"
+	PermManageSubscribers Permission = "subscribers:manage"
+)
+
+// roleKeyEnvVars maps each role to the environment variable holding its API keys.
+// Order matters: a key listed under several roles resolves to the first match.
+var roleKeyEnvVars = []struct {
+	role   Role
+	envVar string
+}{
+	{RoleAdmin, "ADMIN_API_KEYS"},
+	{RoleEditor, "EDITOR_API_KEYS"},
+	{RoleAuthor, "AUTHOR_API_KEYS"},
+	{RoleAnalyst, "ANALYST_API_KEYS"},
+}
+
+// rolePermissions is the permission matrix for all roles
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermCreatePosts,
+		PermEditAnyPost,
+		PermEditOwnPosts,
+		PermDeleteAnyPost,
+		PermDeleteOwnPosts,
+		PermManageAuthors,
+		PermReadAnalytics,
+		PermReadAuditLog,
+		PermExportPosts,
+		PermImportPosts,
+		PermUploadMedia,
+		PermDeleteMedia,
+		PermManageSeries,
+		PermRebuildRelated,
+		PermManageWebhooks,
+		PermManageSubscribers,
+	},
+	RoleEditor: {
+		PermCreatePosts,
+		PermEditAnyPost,
+		PermEditOwnPosts,
+		PermDeleteAnyPost,
+		PermDeleteOwnPosts,
+		PermReadAnalytics,
+		PermUploadMedia,
+		PermDeleteMedia,
+		PermManageSeries,
+		PermRebuildRelated,
+	},
+	RoleAuthor: {
+		PermCreatePosts,
+		PermEditOwnPosts,
+		PermDeleteOwnPosts,
+		PermUploadMedia,
+	},
+	RoleAnalyst: {
+		PermReadAnalytics,
+	},
+}
+
+// HasPermission reports whether role is granted permission
+func HasPermission(role Role, permission Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Can reports whether the authenticated request is granted permission
+func Can(c *gin.Context, permission Permission) bool {
+	return HasPermission(Role(c.GetString(ContextRole)), permission)
+}
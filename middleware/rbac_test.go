@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// Unit tests for role-based access control
+
+func TestHasPermission_Matrix(t *testing.T) {
+	testCases := []struct {
+		name       string
+		role       Role
+		permission Permission
+		expected   bool
+	}{
+		{"admin manages authors", RoleAdmin, PermManageAuthors, true},
+		{"admin edits any post", RoleAdmin, PermEditAnyPost, true},
+		{"editor edits any post", RoleEditor, PermEditAnyPost, true},
+		{"editor cannot manage authors", RoleEditor, PermManageAuthors, false},
+		{"admin exports posts", RoleAdmin, PermExportPosts, true},
+		{"editor cannot export posts", RoleEditor, PermExportPosts, false},
+		{"admin imports posts", RoleAdmin, PermImportPosts, true},
+		{"editor cannot import posts", RoleEditor, PermImportPosts, false},
+		{"editor deletes media", RoleEditor, PermDeleteMedia, true},
+		{"editor manages series", RoleEditor, PermManageSeries, true},
+		{"author cannot manage series", RoleAuthor, PermManageSeries, false},
+		{"editor rebuilds related posts", RoleEditor, PermRebuildRelated, true},
+		{"author cannot rebuild related posts", RoleAuthor, PermRebuildRelated, false},
+		{"admin manages webhooks", RoleAdmin, PermManageWebhooks, true},
+		{"editor cannot manage webhooks", RoleEditor, PermManageWebhooks, false},
+		{"admin manages subscribers", RoleAdmin, PermManageSubscribers, true},
+		{"editor cannot manage subscribers", RoleEditor, PermManageSubscribers, false},
+		{"author uploads media", RoleAuthor, PermUploadMedia, true},
+		{"author cannot delete media", RoleAuthor, PermDeleteMedia, false},
+		{"analyst cannot upload media", RoleAnalyst, PermUploadMedia, false},
+		{"author creates posts", RoleAuthor, PermCreatePosts, true},
+		{"author edits own posts", RoleAuthor, PermEditOwnPosts, true},
+		{"author cannot edit any post", RoleAuthor, PermEditAnyPost, false},
+		{"author cannot read analytics", RoleAuthor, PermReadAnalytics, false},
+		{"analyst reads analytics", RoleAnalyst, PermReadAnalytics, true},
+		{"analyst cannot create posts", RoleAnalyst, PermCreatePosts, false},
+		{"unknown role has no permissions", Role("guest"), PermReadAnalytics, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, HasPermission(tc.role, tc.permission))
+		})
+	}
+}
+
+func TestRoleForKey(t *testing.T) {
+	t.Setenv("ADMIN_API_KEYS", "admin-key-1, admin-key-2")
+	t.Setenv("EDITOR_API_KEYS", "editor-key")
+	t.Setenv("AUTHOR_API_KEYS", "author-key")
+	t.Setenv("ANALYST_API_KEYS", "analyst-key")
+
+	testCases := []struct {
+		name     string
+		key      string
+		expected Role
+		valid    bool
+	}{
+		{"first admin key", "admin-key-1", RoleAdmin, true},
+		{"trimmed admin key", "admin-key-2", RoleAdmin, true},
+		{"editor key", "editor-key", RoleEditor, true},
+		{"author key", "author-key", RoleAuthor, true},
+		{"analyst key", "analyst-key", RoleAnalyst, true},
+		{"unknown key", "not-a-key", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			role, ok := roleForKey(tc.key)
+			assert.Equal(t, tc.valid, ok)
+			assert.Equal(t, tc.expected, role)
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	t.Setenv("ADMIN_API_KEYS", "")
+	t.Setenv("EDITOR_API_KEYS", "editor-key")
+	t.Setenv("AUTHOR_API_KEYS", "")
+	t.Setenv("ANALYST_API_KEYS", "")
+
+	role, ok := Authenticate("editor-key")
+	assert.True(t, ok)
+	assert.Equal(t, RoleEditor, role)
+
+	_, ok = Authenticate("")
+	assert.False(t, ok)
+	_, ok = Authenticate("wrong-key")
+	assert.False(t, ok)
+
+	t.Setenv("EDITOR_API_KEYS", "")
+	_, ok = Authenticate("editor-key")
+	assert.False(t, ok, "no key is valid when none is configured")
+}
+
+func TestRequirePermission_StatusCodes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("ADMIN_API_KEYS", "admin-key")
+	t.Setenv("EDITOR_API_KEYS", "")
+	t.Setenv("AUTHOR_API_KEYS", "")
+	t.Setenv("ANALYST_API_KEYS", "analyst-key")
+
+	router := gin.New()
+	router.POST("/posts", RequirePermission(PermCreatePosts), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	testCases := []struct {
+		name     string
+		key      string
+		expected int
+	}{
+		{"missing key", "", http.StatusUnauthorized},
+		{"invalid key", "wrong-key", http.StatusUnauthorized},
+		{"role without permission", "analyst-key", http.StatusForbidden},
+		{"role with permission", "admin-key", http.StatusCreated},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/posts", nil)
+			if tc.key != "" {
+				req.Header.Set("X-API-Key", tc.key)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tc.expected, w.Code)
+		})
+	}
+}
+
+func TestAPIKeyID(t *testing.T) {
+	id := APIKeyID("test-api-key")
+	assert.Len(t, id, 16)
+	assert.Equal(t, id, APIKeyID("test-api-key"), "Key IDs should be stable")
+	assert.NotEqual(t, id, APIKeyID("other-api-key"))
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContextRequestID is the gin context key holding the request ID
+const ContextRequestID = "request_id"
+
+// RequestIDHeader is the header used to propagate request IDs
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID limits client-supplied request IDs to a safe character set and length
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestIDMiddleware assigns every request an ID, reusing a well-formed
+// X-Request-ID header from the client and echoing it in the response
+func RequestIDMiddleware() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = generateRequestID()
+		}
+
+		c.Set(ContextRequestID, requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	})
+}
+
+// generateRequestID returns a random 128-bit hex request ID
+func generateRequestID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditEntry represents an append-only record of an admin action or authentication failure
+type AuditEntry struct {
+	ID            primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
+	Action        string                 `json:"action" bson:"action"`
+	ActorKeyID    string                 `json:"actor_key_id,omitempty" bson:"actor_key_id,omitempty"`
+	ActorRole     string                 `json:"actor_role,omitempty" bson:"actor_role,omitempty"`
+	ClientIP      string                 `json:"client_ip" bson:"client_ip"`
+	RequestID     string                 `json:"request_id" bson:"request_id"`
+	Method        string                 `json:"method" bson:"method"`
+	Path          string                 `json:"path" bson:"path"`
+	TargetPostID  *primitive.ObjectID    `json:"target_post_id,omitempty" bson:"target_post_id,omitempty"`
+	TargetMediaID *primitive.ObjectID    `json:"target_media_id,omitempty" bson:"target_media_id,omitempty"`
+	Changes       map[string]FieldChange `json:"changes,omitempty" bson:"changes,omitempty"`
+	Reason        string                 `json:"reason,omitempty" bson:"reason,omitempty"`
+	CreatedAt     time.Time              `json:"created_at" bson:"created_at"`
+}
+
+// FieldChange holds the value of a single field before and after an admin action
+type FieldChange struct {
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Author represents a blog contributor profile
+type Author struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name        string             `json:"name" bson:"name" binding:"required,min=1,max=100"`
+	Slug        string             `json:"slug" bson:"slug" binding:"required,min=1,max=100"`
+	Bio         string             `json:"bio" bson:"bio,omitempty" binding:"max=1000"`
+	AvatarURL   string             `json:"avatar_url" bson:"avatar_url,omitempty" binding:"omitempty,url,max=500"`
+	SocialLinks []SocialLink       `json:"social_links" bson:"social_links,omitempty" binding:"max=10,dive"`
+	APIKeyIDs   []string           `json:"api_key_ids,omitempty" bson:"api_key_ids,omitempty" binding:"max=10,dive,min=1,max=64"` // Admin credentials attributed to this author
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// SocialLink represents a link to one of an author's external profiles
+type SocialLink struct {
+	Platform string `json:"platform" bson:"platform" binding:"required,min=1,max=50"`
+	URL      string `json:"url" bson:"url" binding:"required,url,max=500"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Media represents an uploaded file. The file and its variants are kept in media
+// storage under their keys; URLs are derived from the ID when responding.
+type Media struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	FileName    string             `json:"file_name" bson:"file_name"`
+	ContentType string             `json:"content_type" bson:"content_type"`
+	Size        int64              `json:"size" bson:"size"`
+	Width       int                `json:"width,omitempty" bson:"width,omitempty"`
+	Height      int                `json:"height,omitempty" bson:"height,omitempty"`
+	Alt         string             `json:"alt,omitempty" bson:"alt,omitempty"`
+	Key         string             `json:"-" bson:"key"`
+	URL         string             `json:"url" bson:"-"`
+	Variants    []MediaVariant     `json:"variants,omitempty" bson:"variants,omitempty"`
+	UploadedBy  string             `json:"uploaded_by,omitempty" bson:"uploaded_by,omitempty"` // ID of the API key that uploaded the file
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// MediaVariant is a resized or re-encoded rendition of an uploaded image
+type MediaVariant struct {
+	Name        string `json:"name" bson:"name"`
+	ContentType string `json:"content_type" bson:"content_type"`
+	Size        int64  `json:"size" bson:"size"`
+	Width       int    `json:"width" bson:"width"`
+	Height      int    `json:"height" bson:"height"`
+	Key         string `json:"-" bson:"key"`
+	URL         string `json:"url" bson:"-"`
+}
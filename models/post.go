@@ -8,17 +8,52 @@ import (
 
 // Post represents a blog post in the database
 type Post struct {
-	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Title     string             `json:"title" bson:"title" binding:"required,min=1,max=200"`
-	Content   string             `json:"content" bson:"content" binding:"required,min=1,max=50000"`
-	Slug      string             `json:"slug" bson:"slug" binding:"required,min=1,max=100"`
-	Summary   string             `json:"summary" bson:"summary,omitempty" binding:"max=500"`
-	Tags      []string           `json:"tags" bson:"tags,omitempty" binding:"max=10,dive,min=1,max=50,alphanum"` // Max 10 tags, each alphanumeric
-	Published bool               `json:"published" bson:"published"`
-	Views     int64              `json:"views" bson:"views"`
-	Likes     int64              `json:"likes" bson:"likes"`
-	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+	ID        primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	Title     string               `json:"title" bson:"title" binding:"required,min=1,max=200"`
+	Content   string               `json:"content,omitempty" bson:"content" binding:"required,min=1,max=50000"`
+	Slug      string               `json:"slug" bson:"slug" binding:"max=100"` // Generated from the title when empty
+	Slugs     []string             `json:"-" bson:"slugs,omitempty"`           // Current slug and all previous ones, which redirect to it
+	Summary   string               `json:"summary" bson:"summary,omitempty" binding:"max=500"`
+	Tags      []string             `json:"tags" bson:"tags,omitempty" binding:"max=10,dive,min=1,max=50,alphanum"` // Max 10 tags, each alphanumeric
+	AuthorIDs []primitive.ObjectID `json:"author_ids" bson:"author_ids,omitempty" binding:"max=10"`
+	Published bool                 `json:"published" bson:"published"`
+	Views     int64                `json:"views" bson:"views"`
+	Likes     int64                `json:"likes" bson:"likes"`
+	Version   int64                `json:"version" bson:"version"` // Incremented on every update for optimistic concurrency control
+	CreatedAt time.Time            `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at" bson:"updated_at"`
+	CountedAt time.Time            `json:"-" bson:"counted_at,omitempty"` // Last change of views or likes, which leave updated_at alone
+	Series    *PostSeries          `json:"series,omitempty" bson:"-"`     // Set by GetPost, and by lists with expand=series, for posts that belong to a series
+	Authors   []Author             `json:"authors,omitempty" bson:"-"`    // Profiles of author_ids, set by lists with expand=authors
+
+	// Translations of a post share a translation group, with at most one post per language
+	Lang               string             `json:"lang" bson:"lang" binding:"max=35"`                          // Defaults to DEFAULT_LANGUAGE
+	TranslationGroupID primitive.ObjectID `json:"translation_group_id" bson:"translation_group_id,omitempty"` // Set to an existing post's group to translate it
+	Translations       []PostTranslation  `json:"translations,omitempty" bson:"-"`                            // Published translations in other languages, set on reads
+
+	// Derived from the content on every write; values sent by clients are ignored
+	WordCount          int        `json:"word_count" bson:"word_count"`
+	ReadingTimeMinutes int        `json:"reading_time_minutes" bson:"reading_time_minutes"`
+	TOC                []TOCEntry `json:"toc" bson:"toc"`
+}
+
+// PostSortFields lists the fields post lists can be sorted by. Each is backed by
+// an index on the field and _id, which breaks ties.
+var PostSortFields = []string{"created_at", "updated_at", "views", "likes", "title"}
+
+// TOCEntry is a heading of a post's content, listed in the table of contents
+type TOCEntry struct {
+	Level int    `json:"level" bson:"level"` // 1 to 6, as in <h1> to <h6>
+	Text  string `json:"text" bson:"text"`
+	ID    string `json:"id" bson:"id"` // Anchor ID, unique within the post
+}
+
+// PostTranslation is a published translation of a post, listed with the post
+type PostTranslation struct {
+	ID    primitive.ObjectID `json:"id" bson:"_id"`
+	Lang  string             `json:"lang" bson:"lang"`
+	Slug  string             `json:"slug" bson:"slug"`
+	Title string             `json:"title" bson:"title"`
 }
 
 // PostView represents a view record for analytics
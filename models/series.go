@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Series represents an ordered group of posts, such as a multi-part tutorial.
+// A post belongs to at most one series.
+type Series struct {
+	ID          primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	Title       string               `json:"title" bson:"title" binding:"required,min=1,max=200"`
+	Slug        string               `json:"slug" bson:"slug" binding:"max=100"` // Generated from the title when empty
+	Description string               `json:"description" bson:"description,omitempty" binding:"max=2000"`
+	PostIDs     []primitive.ObjectID `json:"post_ids" bson:"post_ids" binding:"max=100"` // Parts in reading order
+	CreatedAt   time.Time            `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at" bson:"updated_at"`
+}
+
+// PostSeries describes the series a post belongs to and its neighbouring parts.
+// Only published parts, and the post itself, are counted.
+type PostSeries struct {
+	ID       primitive.ObjectID `json:"id"`
+	Title    string             `json:"title"`
+	Slug     string             `json:"slug"`
+	Part     int                `json:"part"`
+	Total    int                `json:"total"`
+	Previous *SeriesPart        `json:"previous"`
+	Next     *SeriesPart        `json:"next"`
+}
+
+// SeriesPart is a post listed as part of a series
+type SeriesPart struct {
+	Part      int                `json:"part"`
+	ID        primitive.ObjectID `json:"id"`
+	Title     string             `json:"title"`
+	Slug      string             `json:"slug"`
+	Summary   string             `json:"summary,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Subscriber is a newsletter subscription. Subscriptions are pending until the
+// address is confirmed through the link sent to it.
+type Subscriber struct {
+	ID                    primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Email                 string             `json:"email" bson:"email"`
+	Status                string             `json:"status" bson:"status"`
+	Tags                  []string           `json:"tags" bson:"tags"`                      // Posts with any of these tags are sent; empty means all posts
+	ConfirmTokenHash      string             `json:"-" bson:"confirm_token_hash,omitempty"` // SHA-256 of the token in the confirmation link
+	ConfirmTokenExpiresAt *time.Time         `json:"-" bson:"confirm_token_expires_at,omitempty"`
+	ConfirmSentAt         *time.Time         `json:"-" bson:"confirm_sent_at,omitempty"`
+	UnsubscribeToken      string             `json:"-" bson:"unsubscribe_token"` // Authorizes unsubscribing and changing preferences
+	Bounces               int                `json:"bounces" bson:"bounces"`
+	LastBounceAt          *time.Time         `json:"last_bounce_at,omitempty" bson:"last_bounce_at,omitempty"`
+	ComplainedAt          *time.Time         `json:"complained_at,omitempty" bson:"complained_at,omitempty"`
+	StatusReason          string             `json:"status_reason,omitempty" bson:"status_reason,omitempty"`
+	ConfirmedAt           *time.Time         `json:"confirmed_at,omitempty" bson:"confirmed_at,omitempty"`
+	UnsubscribedAt        *time.Time         `json:"unsubscribed_at,omitempty" bson:"unsubscribed_at,omitempty"`
+	CreatedAt             time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt             time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// NewsletterIssue is a published post being sent to subscribers. Sending resumes
+// after the last subscriber it reached, so each subscriber receives a post once.
+type NewsletterIssue struct {
+	ID               primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	PostID           primitive.ObjectID  `json:"post_id" bson:"post_id"`
+	Status           string              `json:"status" bson:"status"`
+	Sent             int                 `json:"sent" bson:"sent"`
+	Failed           int                 `json:"failed" bson:"failed"`
+	LastSubscriberID *primitive.ObjectID `json:"-" bson:"last_subscriber_id,omitempty"`
+	Retries          int                 `json:"-" bson:"retries"` // Failed attempts to send to the subscriber after LastSubscriberID
+	LockedUntil      *time.Time          `json:"-" bson:"locked_until,omitempty"`
+	Error            string              `json:"error,omitempty" bson:"error,omitempty"`
+	CreatedAt        time.Time           `json:"created_at" bson:"created_at"`
+	CompletedAt      *time.Time          `json:"completed_at,omitempty" bson:"completed_at,omitempty"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Webhook is an endpoint notified of post lifecycle events
+type Webhook struct {
+	ID                  primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	URL                 string             `json:"url" bson:"url"`
+	Events              []string           `json:"events" bson:"events"`
+	Description         string             `json:"description" bson:"description,omitempty"`
+	Active              bool               `json:"active" bson:"active"`
+	Secret              string             `json:"-" bson:"secret"`                                    // Signs deliveries; only returned when created or rotated
+	ConsecutiveFailures int                `json:"consecutive_failures" bson:"consecutive_failures"`   // Failed attempts since the last success
+	DisabledAt          *time.Time         `json:"disabled_at,omitempty" bson:"disabled_at,omitempty"` // Set when disabled after repeated failures
+	DisabledReason      string             `json:"disabled_reason,omitempty" bson:"disabled_reason,omitempty"`
+	CreatedAt           time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt           time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// WebhookDelivery is an event queued for, or delivered to, a webhook. Pending
+// deliveries form the outbox read by the dispatcher; the rest are the delivery log.
+type WebhookDelivery struct {
+	ID            primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	WebhookID     primitive.ObjectID  `json:"webhook_id" bson:"webhook_id"`
+	EventID       string              `json:"event_id" bson:"event_id"`
+	Event         string              `json:"event" bson:"event"`
+	Payload       string              `json:"payload" bson:"payload"` // Exact JSON request body
+	Status        string              `json:"status" bson:"status"`
+	Attempts      []WebhookAttempt    `json:"attempts" bson:"attempts"`
+	NextAttemptAt *time.Time          `json:"next_attempt_at,omitempty" bson:"next_attempt_at,omitempty"`
+	LockedUntil   *time.Time          `json:"-" bson:"locked_until,omitempty"` // Lease held by the dispatcher sending it
+	RedeliveryOf  *primitive.ObjectID `json:"redelivery_of,omitempty" bson:"redelivery_of,omitempty"`
+	CreatedAt     time.Time           `json:"created_at" bson:"created_at"`
+	CompletedAt   *time.Time          `json:"completed_at,omitempty" bson:"completed_at,omitempty"`
+}
+
+// WebhookAttempt is the outcome of one attempt to send a delivery
+type WebhookAttempt struct {
+	At           time.Time `json:"at" bson:"at"`
+	StatusCode   int       `json:"status_code,omitempty" bson:"status_code,omitempty"`
+	Error        string    `json:"error,omitempty" bson:"error,omitempty"`
+	DurationMS   int64     `json:"duration_ms" bson:"duration_ms"`
+	ResponseBody string    `json:"response_body,omitempty" bson:"response_body,omitempty"` // Truncated
+}
@@ -0,0 +1,274 @@
+package newsletter
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Issue statuses
+const (
+	IssuePending   = "pending"
+	IssueSent      = "sent"
+	IssueCancelled = "cancelled" // The post was deleted or unpublished before it was sent
+)
+
+// Sending policy
+const (
+	// MaxSendRetries is the number of attempts to send a post to a subscriber
+	// before moving on to the next one
+	MaxSendRetries = 3
+
+	// retryDelay is the wait before retrying a failed send
+	retryDelay = time.Minute
+
+	// batchSize is the number of subscribers read at a time
+	batchSize = 100
+
+	// lease is how long a claimed issue is reserved for the server sending it;
+	// it is renewed after every subscriber
+	lease = 2 * time.Minute
+
+	// pollInterval is how often pending issues are checked for
+	pollInterval = 5 * time.Second
+)
+
+// EnqueuePost queues a post for subscribers when a write publishes it: it is
+// created published or a draft becomes published. Call it with the context of
+// the transaction making the write. A post is sent at most once, even if it is
+// unpublished and published again.
+func EnqueuePost(ctx context.Context, before, after *models.Post) error {
+	if after == nil || !after.Published || (before != nil && before.Published) {
+		return nil
+	}
+
+	_, err := database.Database.Collection(IssuesCollectionName).UpdateOne(ctx,
+		bson.M{"post_id": after.ID},
+		bson.M{"$setOnInsert": models.NewsletterIssue{
+			PostID:    after.ID,
+			Status:    IssuePending,
+			CreatedAt: time.Now(),
+		}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// Start sends pending issues in the background until ctx is done. Leases on
+// claimed issues allow several server instances to share the work.
+func Start(ctx context.Context) {
+	mailer, err := Default()
+	if err != nil {
+		log.Printf("[ERROR] newsletter.Start: Invalid mailer configuration, newsletters won't be sent - %s", err.Error())
+		return
+	}
+
+	go func() {
+		for {
+			issue, err := claim(ctx)
+			if err != nil {
+				log.Printf("[ERROR] newsletter.Start: Failed to claim issue - %s", err.Error())
+			}
+			if issue != nil {
+				process(ctx, mailer, *issue)
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(pollInterval):
+			}
+		}
+	}()
+}
+
+// claim leases the oldest pending issue that isn't being sent, or returns nil
+// when there is none
+func claim(ctx context.Context) (*models.NewsletterIssue, error) {
+	now := time.Now()
+
+	var issue models.NewsletterIssue
+	err := database.Database.Collection(IssuesCollectionName).FindOneAndUpdate(ctx,
+		bson.M{
+			"status": IssuePending,
+			"$or": bson.A{
+				bson.M{"locked_until": bson.M{"$exists": false}},
+				bson.M{"locked_until": bson.M{"$lte": now}},
+			},
+		},
+		bson.M{"$set": bson.M{"locked_until": now.Add(lease)}},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "created_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&issue)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// process sends a claimed issue to the confirmed subscribers interested in the
+// post, in _id order, recording progress after each one
+func process(ctx context.Context, mailer Mailer, issue models.NewsletterIssue) {
+	var post models.Post
+	err := database.Database.Collection("posts").FindOne(ctx, bson.M{"_id": issue.PostID}).Decode(&post)
+	if err != nil && err != mongo.ErrNoDocuments {
+		log.Printf("[ERROR] newsletter.process: Failed to fetch post ID '%s' - %s", issue.PostID.Hex(), err.Error())
+		return // Retried once the lease expires
+	}
+	if err == mongo.ErrNoDocuments || !post.Published {
+		complete(ctx, issue, IssueCancelled)
+		return
+	}
+
+	for {
+		subscribers, err := recipients(ctx, post, issue.LastSubscriberID)
+		if err != nil {
+			log.Printf("[ERROR] newsletter.process: Failed to fetch subscribers for post ID '%s' - %s", post.ID.Hex(), err.Error())
+			return
+		}
+		if len(subscribers) == 0 {
+			complete(ctx, issue, IssueSent)
+			log.Printf("[SUCCESS] newsletter.process: Sent post ID '%s' to %d subscribers (%d failed)", post.ID.Hex(), issue.Sent, issue.Failed)
+			return
+		}
+
+		for _, subscriber := range subscribers {
+			if ctx.Err() != nil {
+				return
+			}
+			sent, ok := sendTo(ctx, mailer, &issue, post, subscriber)
+			if !ok {
+				return
+			}
+			if !recordProgress(ctx, &issue, subscriber.ID, sent) {
+				return
+			}
+		}
+	}
+}
+
+// recipients returns the next batch of confirmed subscribers after the given
+// one whose tag preferences match the post
+func recipients(ctx context.Context, post models.Post, after *primitive.ObjectID) ([]models.Subscriber, error) {
+	tags := post.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	filter := bson.M{
+		"status": SubscriberConfirmed,
+		"$or": bson.A{
+			bson.M{"tags": bson.M{"$size": 0}},
+			bson.M{"tags": bson.M{"$in": tags}},
+		},
+	}
+	if after != nil {
+		filter["_id"] = bson.M{"$gt": *after}
+	}
+
+	cursor, err := database.Database.Collection(SubscribersCollectionName).Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(batchSize))
+	if err != nil {
+		return nil, err
+	}
+	var subscribers []models.Subscriber
+	if err := cursor.All(ctx, &subscribers); err != nil {
+		return nil, err
+	}
+	return subscribers, nil
+}
+
+// sendTo sends the post to a subscriber. Rejected addresses are marked as
+// bounced. Other failures are retried later, up to MaxSendRetries times, by
+// returning false with the issue left locked until the retry is due.
+func sendTo(ctx context.Context, mailer Mailer, issue *models.NewsletterIssue, post models.Post, subscriber models.Subscriber) (sent bool, ok bool) {
+	msg, err := PostMessage(post, subscriber)
+	if err == nil {
+		err = mailer.Send(ctx, msg)
+	}
+	if err == nil {
+		return true, true
+	}
+
+	if IsRejected(err) {
+		log.Printf("[INFO] newsletter.process: Subscriber ID '%s' bounced - %s", subscriber.ID.Hex(), err.Error())
+		if err := RecordBounce(ctx, subscriber.ID, err.Error()); err != nil {
+			log.Printf("[ERROR] newsletter.process: Failed to record bounce of subscriber ID '%s' - %s", subscriber.ID.Hex(), err.Error())
+		}
+		return false, true
+	}
+
+	log.Printf("[ERROR] newsletter.process: Failed to send post ID '%s' to subscriber ID '%s' (attempt %d) - %s",
+		post.ID.Hex(), subscriber.ID.Hex(), issue.Retries+1, err.Error())
+	if issue.Retries+1 >= MaxSendRetries {
+		return false, true
+	}
+
+	_, updateErr := database.Database.Collection(IssuesCollectionName).UpdateOne(ctx,
+		bson.M{"_id": issue.ID},
+		bson.M{
+			"$inc": bson.M{"retries": 1},
+			"$set": bson.M{"locked_until": time.Now().Add(retryDelay), "error": err.Error()},
+		},
+	)
+	if updateErr != nil {
+		log.Printf("[ERROR] newsletter.process: Failed to schedule retry of issue '%s' - %s", issue.ID.Hex(), updateErr.Error())
+	}
+	return false, false
+}
+
+// recordProgress moves an issue past a subscriber and renews its lease
+func recordProgress(ctx context.Context, issue *models.NewsletterIssue, subscriberID primitive.ObjectID, sent bool) bool {
+	counter := "failed"
+	if sent {
+		counter = "sent"
+	}
+
+	_, err := database.Database.Collection(IssuesCollectionName).UpdateOne(ctx,
+		bson.M{"_id": issue.ID},
+		bson.M{
+			"$set":   bson.M{"last_subscriber_id": subscriberID, "retries": 0, "locked_until": time.Now().Add(lease)},
+			"$inc":   bson.M{counter: 1},
+			"$unset": bson.M{"error": ""},
+		},
+	)
+	if err != nil {
+		log.Printf("[ERROR] newsletter.process: Failed to record progress of issue '%s' - %s", issue.ID.Hex(), err.Error())
+		return false
+	}
+
+	issue.LastSubscriberID = &subscriberID
+	issue.Retries = 0
+	if sent {
+		issue.Sent++
+	} else {
+		issue.Failed++
+	}
+	return true
+}
+
+// complete finishes an issue with a final status
+func complete(ctx context.Context, issue models.NewsletterIssue, status string) {
+	_, err := database.Database.Collection(IssuesCollectionName).UpdateOne(ctx,
+		bson.M{"_id": issue.ID},
+		bson.M{
+			"$set":   bson.M{"status": status, "completed_at": time.Now()},
+			"$unset": bson.M{"locked_until": ""},
+		},
+	)
+	if err != nil {
+		log.Printf("[ERROR] newsletter.process: Failed to complete issue '%s' - %s", issue.ID.Hex(), err.Error())
+	}
+}
@@ -0,0 +1,128 @@
+package newsletter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Message is an email to a single recipient
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	Headers map[string]string // Extra headers, such as List-Unsubscribe
+}
+
+// Mailer sends email. Implementations return a *RejectedError when the
+// recipient's mail server permanently refuses a message.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// RejectedError is a permanent refusal of a recipient, such as an unknown
+// mailbox. Subscribers whose address is rejected are marked as bounced.
+type RejectedError struct {
+	Err error
+}
+
+func (e *RejectedError) Error() string { return "recipient rejected: " + e.Err.Error() }
+
+func (e *RejectedError) Unwrap() error { return e.Err }
+
+// IsRejected reports whether err is a permanent refusal of the recipient
+func IsRejected(err error) bool {
+	var rejected *RejectedError
+	return errors.As(err, &rejected)
+}
+
+var (
+	defaultOnce   sync.Once
+	defaultMailer Mailer
+	defaultErr    error
+)
+
+// Default returns the mailer selected by MAILER: "log" (the default) logs
+// messages, "file" writes them as .eml files under MAILER_FILE_DIR, and "smtp"
+// sends them through the server configured by the SMTP_* variables
+func Default() (Mailer, error) {
+	defaultOnce.Do(func() {
+		defaultMailer, defaultErr = mailerFromEnv()
+	})
+	return defaultMailer, defaultErr
+}
+
+// mailerFromEnv creates the mailer configured in the environment
+func mailerFromEnv() (Mailer, error) {
+	switch backend := os.Getenv("MAILER"); backend {
+	case "", "log":
+		return LogMailer{}, nil
+	case "file":
+		dir := os.Getenv("MAILER_FILE_DIR")
+		if dir == "" {
+			dir = "mail"
+		}
+		return FileMailer{Dir: dir, From: fromAddress()}, nil
+	case "smtp":
+		return NewSMTPMailer(SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     fromAddress(),
+		})
+	default:
+		return nil, fmt.Errorf("unknown MAILER '%s': use log, file or smtp", backend)
+	}
+}
+
+// fromAddress returns the sender of newsletter emails from NEWSLETTER_FROM
+func fromAddress() string {
+	if from := os.Getenv("NEWSLETTER_FROM"); from != "" {
+		return from
+	}
+	return "newsletter@localhost"
+}
+
+// LogMailer logs messages instead of sending them, for development
+type LogMailer struct{}
+
+// Send logs the recipient, subject and text of msg
+func (LogMailer) Send(_ context.Context, msg Message) error {
+	log.Printf("[INFO] newsletter.LogMailer: Email to %s - %s\n%s", msg.To, msg.Subject, msg.Text)
+	return nil
+}
+
+// FileMailer writes each message to a .eml file, for development and tests
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+// Send writes msg to a new file in the mailer's directory
+func (m FileMailer) Send(_ context.Context, msg Message) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	now := time.Now()
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), hex.EncodeToString(suffix))
+
+	data, err := Encode(m.From, msg, now)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(m.Dir, name), data, 0o644)
+}
@@ -0,0 +1,113 @@
+package newsletter
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"net/url"
+	"os"
+	"strings"
+	texttemplate "text/template"
+
+	"dbl-blog-backend/models"
+)
+
+// baseURL returns NEWSLETTER_BASE_URL, the public URL of the API that links in
+// emails point to
+func baseURL() string {
+	if base := strings.TrimRight(os.Getenv("NEWSLETTER_BASE_URL"), "/"); base != "" {
+		return base
+	}
+	return "http://localhost:8080"
+}
+
+// ConfirmURL returns the link that confirms a subscription
+func ConfirmURL(token string) string {
+	return baseURL() + "/api/v1/newsletter/confirm?token=" + url.QueryEscape(token)
+}
+
+// UnsubscribeURL returns the one-click unsubscribe link of a subscriber
+func UnsubscribeURL(token string) string {
+	return baseURL() + "/api/v1/newsletter/unsubscribe?token=" + url.QueryEscape(token)
+}
+
+// PreferencesURL returns the link to a subscriber's tag preferences
+func PreferencesURL(token string) string {
+	return baseURL() + "/api/v1/newsletter/preferences?token=" + url.QueryEscape(token)
+}
+
+// PostURL returns the link to a post: NEWSLETTER_POST_URL with "{slug}" replaced
+// by the post's slug (e.g. https://blog.example.com/posts/{slug}), or the post's
+// API URL when it is not set
+func PostURL(post models.Post) string {
+	if pattern := os.Getenv("NEWSLETTER_POST_URL"); pattern != "" {
+		return strings.ReplaceAll(pattern, "{slug}", url.PathEscape(post.Slug))
+	}
+	return baseURL() + "/api/v1/posts/" + url.PathEscape(post.Slug)
+}
+
+var confirmationText = texttemplate.Must(texttemplate.New("confirmation").Parse(`Please confirm your subscription by opening this link:
+
+{{.ConfirmURL}}
+
+If you didn't subscribe, ignore this email and you won't hear from us again.
+`))
+
+var confirmationHTML = htmltemplate.Must(htmltemplate.New("confirmation").Parse(`<p>Please confirm your subscription:</p>
+<p><a href="{{.ConfirmURL}}">Confirm my subscription</a></p>
+<p>If you didn't subscribe, ignore this email and you won't hear from us again.</p>
+`))
+
+// ConfirmationMessage returns the double opt-in email asking to confirm a subscription
+func ConfirmationMessage(email, confirmToken string) (Message, error) {
+	data := struct{ ConfirmURL string }{ConfirmURL(confirmToken)}
+	return render(email, "Confirm your subscription", data, confirmationText, confirmationHTML, nil)
+}
+
+var postText = texttemplate.Must(texttemplate.New("post").Parse(`{{.Post.Title}}
+{{if .Post.Summary}}
+{{.Post.Summary}}
+{{end}}
+Read it here: {{.PostURL}}
+
+--
+Change the topics you receive: {{.PreferencesURL}}
+Unsubscribe: {{.UnsubscribeURL}}
+`))
+
+var postHTML = htmltemplate.Must(htmltemplate.New("post").Parse(`<h1>{{.Post.Title}}</h1>
+{{if .Post.Summary}}<p>{{.Post.Summary}}</p>
+{{end}}<p><a href="{{.PostURL}}">Read it here</a></p>
+<hr>
+<p><small><a href="{{.PreferencesURL}}">Change the topics you receive</a> &middot; <a href="{{.UnsubscribeURL}}">Unsubscribe</a></small></p>
+`))
+
+// PostMessage returns the email announcing a post to a subscriber, with
+// one-click unsubscribe headers (RFC 8058)
+func PostMessage(post models.Post, subscriber models.Subscriber) (Message, error) {
+	data := struct {
+		Post                                    models.Post
+		PostURL, PreferencesURL, UnsubscribeURL string
+	}{
+		Post:           post,
+		PostURL:        PostURL(post),
+		PreferencesURL: PreferencesURL(subscriber.UnsubscribeToken),
+		UnsubscribeURL: UnsubscribeURL(subscriber.UnsubscribeToken),
+	}
+	headers := map[string]string{
+		"List-Unsubscribe":      "<" + data.UnsubscribeURL + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+	return render(subscriber.Email, post.Title, data, postText, postHTML, headers)
+}
+
+// render executes the text and HTML templates of a message
+func render(to, subject string, data interface{}, text *texttemplate.Template, html *htmltemplate.Template, headers map[string]string) (Message, error) {
+	var textBody, htmlBody bytes.Buffer
+	if err := text.Execute(&textBody, data); err != nil {
+		return Message{}, err
+	}
+	if err := html.Execute(&htmlBody, data); err != nil {
+		return Message{}, err
+	}
+	return Message{To: to, Subject: subject, Text: textBody.String(), HTML: htmlBody.String(), Headers: headers}, nil
+}
@@ -0,0 +1,288 @@
+package newsletter
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"dbl-blog-backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Unit tests for newsletter messages and mailers
+
+func TestNormalizeTags(t *testing.T) {
+	assert.Equal(t, []string{"go", "Go", "web"}, NormalizeTags([]string{" go", "Go", "", "web", "go "}))
+	assert.NotNil(t, NormalizeTags(nil))
+	assert.Empty(t, NormalizeTags(nil))
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	assert.Equal(t, "reader@example.com", NormalizeEmail("  Reader@Example.COM "))
+}
+
+func TestTokens(t *testing.T) {
+	first, err := NewToken()
+	assert.NoError(t, err)
+	second, err := NewToken()
+	assert.NoError(t, err)
+
+	assert.Len(t, first, 64)
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, HashToken(first), HashToken(first))
+	assert.NotEqual(t, first, HashToken(first), "Tokens are not stored as is")
+}
+
+func TestEnqueuePost_IgnoresWritesThatDontPublish(t *testing.T) {
+	draft := models.Post{Title: "Draft"}
+	published := models.Post{Title: "Published", Published: true}
+
+	// None of these reach the database
+	for _, write := range [][2]*models.Post{
+		{nil, &draft},
+		{&draft, &draft},
+		{&published, &published},
+		{&published, &draft},
+		{&published, nil},
+	} {
+		assert.NoError(t, EnqueuePost(t.Context(), write[0], write[1]))
+	}
+}
+
+func TestPostURL(t *testing.T) {
+	post := models.Post{Slug: "hello-world"}
+
+	t.Setenv("NEWSLETTER_BASE_URL", "https://api.example.com/")
+	assert.Equal(t, "https://api.example.com/api/v1/posts/hello-world", PostURL(post))
+
+	t.Setenv("NEWSLETTER_POST_URL", "https://blog.example.com/posts/{slug}")
+	assert.Equal(t, "https://blog.example.com/posts/hello-world", PostURL(post))
+}
+
+func TestPostMessage(t *testing.T) {
+	t.Setenv("NEWSLETTER_BASE_URL", "https://api.example.com")
+	post := models.Post{Title: "Generics <in> Go", Summary: "Type parameters & you", Slug: "generics"}
+	subscriber := models.Subscriber{Email: "reader@example.com", UnsubscribeToken: "tok123"}
+
+	msg, err := PostMessage(post, subscriber)
+	assert.NoError(t, err)
+	assert.Equal(t, "reader@example.com", msg.To)
+	assert.Equal(t, "Generics <in> Go", msg.Subject)
+	assert.Equal(t, "<https://api.example.com/api/v1/newsletter/unsubscribe?token=tok123>", msg.Headers["List-Unsubscribe"])
+	assert.Equal(t, "List-Unsubscribe=One-Click", msg.Headers["List-Unsubscribe-Post"])
+
+	assert.Contains(t, msg.Text, "Generics <in> Go")
+	assert.Contains(t, msg.Text, "https://api.example.com/api/v1/posts/generics")
+	assert.Contains(t, msg.Text, "https://api.example.com/api/v1/newsletter/preferences?token=tok123")
+	assert.Contains(t, msg.HTML, "Generics &lt;in&gt; Go", "Post fields are escaped in HTML")
+	assert.Contains(t, msg.HTML, "Type parameters &amp; you")
+}
+
+func TestConfirmationMessage(t *testing.T) {
+	t.Setenv("NEWSLETTER_BASE_URL", "https://api.example.com")
+
+	msg, err := ConfirmationMessage("reader@example.com", "abc")
+	assert.NoError(t, err)
+	assert.Contains(t, msg.Text, "https://api.example.com/api/v1/newsletter/confirm?token=abc")
+	assert.Contains(t, msg.HTML, `href="https://api.example.com/api/v1/newsletter/confirm?token=abc"`)
+	assert.Empty(t, msg.Headers)
+}
+
+func TestEncode(t *testing.T) {
+	msg := Message{
+		To:      "reader@example.com",
+		Subject: "Café news",
+		Text:    "Hello\nworld = " + strings.Repeat("x", 100),
+		HTML:    "<p>Hello</p>",
+		Headers: map[string]string{"list-unsubscribe": "<https://example.com/u>"},
+	}
+
+	data, err := Encode("Blog <news@example.com>", msg, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	assert.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, `"Blog" <news@example.com>`, parsed.Header.Get("From"))
+	assert.Equal(t, "<reader@example.com>", parsed.Header.Get("To"))
+	assert.Equal(t, "<https://example.com/u>", parsed.Header.Get("List-Unsubscribe"))
+	assert.True(t, strings.HasSuffix(parsed.Header.Get("Message-ID"), "@example.com>"))
+	assert.Equal(t, "Tue, 02 Jan 2024 03:04:05 +0000", parsed.Header.Get("Date"))
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Café news", subject)
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	var contents []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		body, _ := io.ReadAll(part) // Quoted-printable is decoded by the reader
+		contents = append(contents, string(body))
+	}
+	assert.Equal(t, []string{"Hello\r\nworld = " + strings.Repeat("x", 100), "<p>Hello</p>"}, contents)
+}
+
+func TestEncode_RejectsHeaderInjection(t *testing.T) {
+	_, err := Encode("news@example.com", Message{To: "reader@example.com", Text: "Hi",
+		Headers: map[string]string{"X-Test": "a\r\nBcc: victim@example.com"}}, time.Now())
+	assert.Error(t, err)
+
+	_, err = Encode("news@example.com", Message{To: "not an address", Text: "Hi"}, time.Now())
+	assert.Error(t, err)
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	mailer := FileMailer{Dir: filepath.Join(dir, "mail"), From: "news@example.com"}
+
+	assert.NoError(t, mailer.Send(t.Context(), Message{To: "reader@example.com", Subject: "Hi", Text: "Hello"}))
+	assert.NoError(t, mailer.Send(t.Context(), Message{To: "reader@example.com", Subject: "Hi", Text: "Again"}))
+
+	files, err := os.ReadDir(mailer.Dir)
+	assert.NoError(t, err)
+	if assert.Len(t, files, 2) {
+		data, _ := os.ReadFile(filepath.Join(mailer.Dir, files[0].Name()))
+		assert.Contains(t, string(data), "Subject: Hi")
+	}
+}
+
+func TestMailerFromEnv(t *testing.T) {
+	t.Setenv("MAILER", "")
+	mailer, err := mailerFromEnv()
+	assert.NoError(t, err)
+	assert.IsType(t, LogMailer{}, mailer)
+
+	t.Setenv("MAILER", "smtp")
+	t.Setenv("SMTP_HOST", "")
+	_, err = mailerFromEnv()
+	assert.Error(t, err, "SMTP needs a host")
+
+	t.Setenv("MAILER", "carrier-pigeon")
+	_, err = mailerFromEnv()
+	assert.Error(t, err)
+}
+
+func TestRecipientRejected(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"unknown mailbox", &textproto.Error{Code: 550, Msg: "5.1.1 No such user"}, true},
+		{"mailbox name not allowed", &textproto.Error{Code: 553, Msg: "Invalid address"}, true},
+		{"relaying denied", &textproto.Error{Code: 550, Msg: "5.7.1 Relaying denied"}, false},
+		{"temporary failure", &textproto.Error{Code: 451, Msg: "4.3.0 Try again later"}, false},
+		{"network error", errors.New("connection reset"), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, recipientRejected(tc.err))
+		})
+	}
+}
+
+func TestSMTPMailer(t *testing.T) {
+	t.Run("delivers the message", func(t *testing.T) {
+		host, port, received := fakeSMTPServer(t, "250 OK")
+		mailer, err := NewSMTPMailer(SMTPConfig{Host: host, Port: port, From: "news@example.com"})
+		assert.NoError(t, err)
+
+		err = mailer.Send(t.Context(), Message{To: "reader@example.com", Subject: "Hi", Text: "Hello"})
+		assert.NoError(t, err)
+		data := <-received
+		assert.Contains(t, data, "RCPT TO:<reader@example.com>")
+		assert.Contains(t, data, "Subject: Hi")
+	})
+
+	t.Run("rejected recipients are reported", func(t *testing.T) {
+		host, port, _ := fakeSMTPServer(t, "550 5.1.1 No such user")
+		mailer, err := NewSMTPMailer(SMTPConfig{Host: host, Port: port, From: "news@example.com"})
+		assert.NoError(t, err)
+
+		err = mailer.Send(t.Context(), Message{To: "nobody@example.com", Subject: "Hi", Text: "Hello"})
+		assert.True(t, IsRejected(err), "got %v", err)
+	})
+}
+
+// fakeSMTPServer accepts one SMTP session without TLS or authentication,
+// answering RCPT with rcptReply, and sends the session transcript on the
+// returned channel once it ends
+func fakeSMTPServer(t *testing.T, rcptReply string) (string, string, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		var transcript strings.Builder
+		defer func() { received <- transcript.String() }()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP")
+		inData := false
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			transcript.WriteString(line)
+			if inData {
+				if line == ".\r\n" {
+					inData = false
+					reply("250 Queued")
+				}
+				continue
+			}
+			switch command := strings.ToUpper(strings.Fields(line + " x")[0]); command {
+			case "EHLO", "HELO":
+				reply("250 localhost")
+			case "RCPT":
+				reply(rcptReply)
+			case "DATA":
+				inData = true
+				reply("354 Go ahead")
+			case "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	return host, port, received
+}
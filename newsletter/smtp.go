@@ -0,0 +1,142 @@
+package newsletter
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// smtpTimeout bounds sending one message, from connecting to QUIT
+const smtpTimeout = 30 * time.Second
+
+// SMTPConfig configures an SMTPMailer. Port 465 uses implicit TLS; other ports
+// (587 by default) upgrade with STARTTLS when the server offers it.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPMailer sends messages through an SMTP server
+type SMTPMailer struct {
+	config SMTPConfig
+}
+
+// NewSMTPMailer creates an SMTP mailer, checking that the configuration is complete
+func NewSMTPMailer(config SMTPConfig) (*SMTPMailer, error) {
+	if config.Host == "" {
+		return nil, errors.New("SMTP mailer needs SMTP_HOST")
+	}
+	if config.Port == "" {
+		config.Port = "587"
+	}
+	if _, err := mail.ParseAddress(config.From); err != nil {
+		return nil, errors.New("SMTP mailer needs a valid NEWSLETTER_FROM address")
+	}
+	return &SMTPMailer{config: config}, nil
+}
+
+// Send delivers msg over a new connection to the SMTP server
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	data, err := Encode(m.config.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	sender, _ := mail.ParseAddress(m.config.From)
+	recipient, _ := mail.ParseAddress(msg.To)
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	client, err := m.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = client.Close() }()
+
+	if m.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(sender.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(recipient.Address); err != nil {
+		if recipientRejected(err) {
+			return &RejectedError{Err: err}
+		}
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// dial connects to the SMTP server, over TLS when the port or server allows it
+func (m *SMTPMailer) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(m.config.Host, m.config.Port)
+	tlsConfig := &tls.Config{ServerName: m.config.Host, MinVersion: tls.VersionTLS12}
+	implicitTLS := m.config.Port == "465"
+
+	var conn net.Conn
+	var err error
+	if implicitTLS {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if !implicitTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				_ = client.Close()
+				return nil, err
+			}
+		}
+	}
+	return client, nil
+}
+
+// recipientRejected reports whether err refuses the recipient's mailbox for good
+// (550, 551 or 553). Policy refusals with enhanced status 5.7.x, such as relaying
+// being denied, are a problem with the sender rather than the address.
+func recipientRejected(err error) bool {
+	var protocolErr *textproto.Error
+	if !errors.As(err, &protocolErr) {
+		return false
+	}
+	switch protocolErr.Code {
+	case 550, 551, 553:
+		return !strings.HasPrefix(protocolErr.Msg, "5.7.")
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package newsletter
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"dbl-blog-backend/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Collections holding subscribers and newsletter issues
+const (
+	SubscribersCollectionName = "subscribers"
+	IssuesCollectionName      = "newsletter_issues"
+)
+
+// Subscriber statuses. Only confirmed subscribers receive posts.
+const (
+	SubscriberPending      = "pending"
+	SubscriberConfirmed    = "confirmed"
+	SubscriberUnsubscribed = "unsubscribed"
+	SubscriberBounced      = "bounced"    // Their mail server rejected an email
+	SubscriberComplained   = "complained" // They reported an email as spam
+)
+
+// Subscription limits
+const (
+	// ConfirmTokenLifetime is how long a confirmation link stays valid
+	ConfirmTokenLifetime = 48 * time.Hour
+
+	// ConfirmResendInterval is the minimum time between two confirmation emails
+	// to the same address
+	ConfirmResendInterval = 10 * time.Minute
+
+	// MaxTags is the number of tag preferences a subscriber can have
+	MaxTags = 20
+)
+
+// NewToken returns a random URL-safe token for confirmation and unsubscribe links
+func NewToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// HashToken returns the hash a confirmation token is stored as
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeEmail trims and lowercases an email address, so that an address is
+// subscribed once whatever its case
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizeTags trims tag preferences, dropping empty and duplicate ones. The
+// result is never nil.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	result := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// RecordBounce marks a subscriber as bounced, so they receive nothing more until
+// they subscribe again
+func RecordBounce(ctx context.Context, subscriberID primitive.ObjectID, reason string) error {
+	now := time.Now()
+	_, err := database.Database.Collection(SubscribersCollectionName).UpdateOne(ctx,
+		bson.M{"_id": subscriberID, "status": bson.M{"$ne": SubscriberComplained}},
+		bson.M{
+			"$set": bson.M{"status": SubscriberBounced, "last_bounce_at": now, "status_reason": reason, "updated_at": now},
+			"$inc": bson.M{"bounces": 1},
+		},
+	)
+	return err
+}
+
+// RecordComplaint marks a subscriber as having reported an email as spam. They
+// receive nothing more, and subscribing again is refused.
+func RecordComplaint(ctx context.Context, subscriberID primitive.ObjectID, reason string) error {
+	now := time.Now()
+	_, err := database.Database.Collection(SubscribersCollectionName).UpdateOne(ctx,
+		bson.M{"_id": subscriberID},
+		bson.M{"$set": bson.M{"status": SubscriberComplained, "complained_at": now, "status_reason": reason, "updated_at": now}},
+	)
+	return err
+}
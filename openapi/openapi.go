@@ -0,0 +1,117 @@
+// Package openapi embeds the OpenAPI document describing the API, and the page
+// rendering it as documentation, so that both are served from the binary and
+// can be checked against the routes and traffic of the server.
+package openapi
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+//go:embed docs
+var docs embed.FS
+
+var (
+	loadOnce sync.Once
+	loaded   *openapi3.T
+	loadErr  error
+)
+
+// ginParam matches the :name and *name parameters of gin route paths
+var ginParam = regexp.MustCompile(`[:*]([^/]+)`)
+
+// JSON returns the OpenAPI document as served at /openapi.json
+func JSON() []byte {
+	return spec
+}
+
+// Docs returns the files of the documentation page, with index.html at its root
+func Docs() fs.FS {
+	sub, err := fs.Sub(docs, "docs")
+	if err != nil {
+		panic(err) // The directory is embedded, so this can't happen
+	}
+	return sub
+}
+
+// Load parses and validates the OpenAPI document. It is parsed once and shared,
+// so callers must not modify it.
+func Load() (*openapi3.T, error) {
+	loadOnce.Do(func() {
+		doc, err := openapi3.NewLoader().LoadFromData(spec)
+		if err != nil {
+			loadErr = fmt.Errorf("failed to parse OpenAPI document: %w", err)
+			return
+		}
+		if err := doc.Validate(context.Background()); err != nil {
+			loadErr = fmt.Errorf("invalid OpenAPI document: %w", err)
+			return
+		}
+		loaded = doc
+	})
+	return loaded, loadErr
+}
+
+// Path converts a gin route path to its OpenAPI path template, e.g.
+// /api/v1/posts/:id to /api/v1/posts/{id}
+func Path(ginPath string) string {
+	return ginParam.ReplaceAllString(ginPath, "{$1}")
+}
+
+// Operation returns the path item and operation of doc describing the gin route
+// of method and ginPath, or nils when the route isn't documented
+func Operation(doc *openapi3.T, method, ginPath string) (*openapi3.PathItem, *openapi3.Operation) {
+	pathItem := doc.Paths.Value(Path(ginPath))
+	if pathItem == nil {
+		return nil, nil
+	}
+	operation := pathItem.GetOperation(method)
+	if operation == nil {
+		return nil, nil
+	}
+	return pathItem, operation
+}
+
+// Undocumented returns the routes missing from doc, as sorted "METHOD /path"
+// strings with OpenAPI path templates
+func Undocumented(doc *openapi3.T, routes gin.RoutesInfo) []string {
+	var missing []string
+	for _, route := range routes {
+		if _, operation := Operation(doc, route.Method, route.Path); operation == nil {
+			missing = append(missing, route.Method+" "+Path(route.Path))
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Unrouted returns the operations of doc that no route serves, as sorted
+// "METHOD /path" strings
+func Unrouted(doc *openapi3.T, routes gin.RoutesInfo) []string {
+	served := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		served[route.Method+" "+Path(route.Path)] = true
+	}
+
+	var missing []string
+	for path, pathItem := range doc.Paths.Map() {
+		for method := range pathItem.Operations() {
+			if operation := method + " " + path; !served[operation] {
+				missing = append(missing, operation)
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
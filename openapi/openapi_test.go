@@ -0,0 +1,125 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io/fs"
+	"testing"
+	"time"
+
+	"dbl-blog-backend/apierrors"
+	"dbl-blog-backend/models"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Unit tests for the embedded OpenAPI document
+
+func loadDoc(t *testing.T) *openapi3.T {
+	doc, err := Load()
+	require.NoError(t, err)
+	return doc
+}
+
+func TestLoadValidatesTheDocument(t *testing.T) {
+	doc := loadDoc(t)
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+
+	again, err := Load()
+	require.NoError(t, err)
+	assert.Same(t, doc, again, "The document is parsed once")
+}
+
+func TestDocsPageIsEmbedded(t *testing.T) {
+	page, err := fs.ReadFile(Docs(), "index.html")
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "/openapi.json")
+}
+
+func TestPath(t *testing.T) {
+	assert.Equal(t, "/health", Path("/health"))
+	assert.Equal(t, "/api/v1/posts/{id}", Path("/api/v1/posts/:id"))
+	assert.Equal(t, "/api/v1/admin/webhooks/{id}/deliveries/{delivery_id}/redeliver", Path("/api/v1/admin/webhooks/:id/deliveries/:delivery_id/redeliver"))
+	assert.Equal(t, "/files/{path}", Path("/files/*path"))
+}
+
+func TestUndocumentedAndUnrouted(t *testing.T) {
+	doc := loadDoc(t)
+	routes := gin.RoutesInfo{
+		{Method: "GET", Path: "/health"},
+		{Method: "GET", Path: "/api/v1/posts/:id"},
+		{Method: "POST", Path: "/api/v1/posts/:id/share"},
+		{Method: "POST", Path: "/health"},
+	}
+
+	assert.Equal(t, []string{"POST /api/v1/posts/{id}/share", "POST /health"}, Undocumented(doc, routes))
+
+	unrouted := Unrouted(doc, routes)
+	assert.Contains(t, unrouted, "PUT /api/v1/posts/{id}")
+	assert.NotContains(t, unrouted, "GET /api/v1/posts/{id}")
+	assert.NotContains(t, unrouted, "GET /health")
+}
+
+// assertMatchesSchema checks that the JSON encoding of value is valid against a
+// schema of the document
+func assertMatchesSchema(t *testing.T, doc *openapi3.T, name string, value interface{}) {
+	t.Helper()
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+	var decoded interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	schema := doc.Components.Schemas[name]
+	require.NotNil(t, schema, "schema %s", name)
+	assert.NoError(t, schema.Value.VisitJSON(decoded), "%s as encoded by the models: %s", name, data)
+}
+
+func TestSchemasMatchModels(t *testing.T) {
+	doc := loadDoc(t)
+	now := time.Now()
+	id := primitive.NewObjectID()
+
+	// Zero values encode nil slices as null and leave out omitempty fields
+	assertMatchesSchema(t, doc, "Post", models.Post{})
+	assertMatchesSchema(t, doc, "Post", models.Post{
+		ID:        id,
+		Title:     "Hello",
+		Content:   "# Hello",
+		Slug:      "hello",
+		Tags:      []string{"go"},
+		AuthorIDs: []primitive.ObjectID{id},
+		Published: true,
+		Version:   2,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Series: &models.PostSeries{ID: id, Title: "Series", Slug: "series", Part: 1, Total: 2,
+			Next: &models.SeriesPart{Part: 2, ID: id, Title: "Next", Slug: "next", CreatedAt: now}},
+		Authors:            []models.Author{{ID: id, Name: "Jane", Slug: "jane"}},
+		Lang:               "en",
+		TranslationGroupID: id,
+		Translations:       []models.PostTranslation{{ID: id, Lang: "pt", Slug: "ola", Title: "Olá"}},
+		TOC:                []models.TOCEntry{{Level: 1, Text: "Hello", ID: "hello"}},
+	})
+	assertMatchesSchema(t, doc, "Author", models.Author{})
+	assertMatchesSchema(t, doc, "Author", models.Author{ID: id, SocialLinks: []models.SocialLink{{Platform: "github", URL: "https://github.com/jane"}}})
+	assertMatchesSchema(t, doc, "Series", models.Series{})
+	assertMatchesSchema(t, doc, "Media", models.Media{Variants: []models.MediaVariant{{Name: "webp", ContentType: "image/webp"}}})
+	assertMatchesSchema(t, doc, "Webhook", models.Webhook{Events: []string{"post.created"}, DisabledAt: &now})
+	assertMatchesSchema(t, doc, "WebhookDelivery", models.WebhookDelivery{Status: "pending"})
+	assertMatchesSchema(t, doc, "WebhookDelivery", models.WebhookDelivery{Status: "succeeded", RedeliveryOf: &id, CompletedAt: &now,
+		Attempts: []models.WebhookAttempt{{At: now, StatusCode: 200, DurationMS: 12}}})
+	assertMatchesSchema(t, doc, "Subscriber", models.Subscriber{Status: "pending"})
+	assertMatchesSchema(t, doc, "NewsletterIssue", models.NewsletterIssue{Status: "sent", CompletedAt: &now})
+	assertMatchesSchema(t, doc, "AuditEntry", models.AuditEntry{Action: "post.update", TargetPostID: &id,
+		Changes: map[string]models.FieldChange{"title": {Before: "Old", After: "New"}, "tags": {Before: nil, After: []string{"go"}}}})
+
+	// Error responses, in both shapes
+	fieldErrors := []apierrors.FieldError{{Field: "title", Rule: "required", Message: "is required"}}
+	assertMatchesSchema(t, doc, "ErrorResponse", apierrors.ErrorResponse{Error: apierrors.ErrPostNotFound})
+	assertMatchesSchema(t, doc, "ErrorResponse", apierrors.ErrorResponse{Error: apierrors.APIError{Code: apierrors.CodeValidationFailed, Message: "Request validation failed", Errors: fieldErrors}})
+	assertMatchesSchema(t, doc, "Problem", apierrors.Problem{Type: "/problems/not-found", Title: "Resource not found", Status: 404, Errors: []apierrors.FieldError{}})
+	assertMatchesSchema(t, doc, "Problem", apierrors.Problem{Type: "/problems/validation-failed", Status: 400, Errors: fieldErrors})
+}
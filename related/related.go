@@ -0,0 +1,282 @@
+package related
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tuning of the similarity ranking
+const (
+	// MaxCandidates is the number of most similar posts stored for each post
+	MaxCandidates = 20
+
+	// maxTermsPerDocument keeps only the heaviest terms of long posts
+	maxTermsPerDocument = 200
+
+	// Weights of the title and summary relative to the content
+	titleWeight   = 3
+	summaryWeight = 2
+
+	// Share of the similarity coming from the text; the rest comes from tag overlap
+	textShare = 0.7
+
+	// RecencyHalfLife is the age at which the recency boost of a post has halved
+	RecencyHalfLife = 180 * 24 * time.Hour
+)
+
+var (
+	urlPattern  = regexp.MustCompile(`https?://\S+`)
+	wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+)
+
+// stopWords are common English words that say nothing about a post's topic
+var stopWords = map[string]bool{}
+
+func init() {
+	for _, word := range strings.Fields(`a about after all also an and any are as at be because been but by can
+		could did do does for from had has have how i if in into is it its just like may more most my no not
+		of on one only or other our out over so some such than that the their them then there these they this
+		those through to too up us use used using very was we were what when where which while who why will
+		with would you your`) {
+		stopWords[word] = true
+	}
+}
+
+// Document is the text, tags and language of a published post
+type Document struct {
+	ID        primitive.ObjectID
+	Lang      string // Only posts in the same language are related
+	Title     string
+	Summary   string
+	Content   string
+	Tags      []string
+	CreatedAt time.Time
+}
+
+// Candidate is a post similar to another one, with a similarity between 0 and 1
+type Candidate struct {
+	PostID     primitive.ObjectID `json:"post_id" bson:"post_id"`
+	Similarity float64            `json:"similarity" bson:"similarity"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Tokenize splits text into lowercase words, leaving out URLs, stop words and
+// single characters
+func Tokenize(text string) []string {
+	text = urlPattern.ReplaceAllString(text, " ")
+	var tokens []string
+	for _, word := range wordPattern.FindAllString(strings.ToLower(text), -1) {
+		if len([]rune(word)) < 2 || stopWords[word] || isNumber(word) {
+			continue
+		}
+		tokens = append(tokens, word)
+	}
+	return tokens
+}
+
+// isNumber reports whether word consists of digits only
+func isNumber(word string) bool {
+	return strings.IndexFunc(word, func(r rune) bool { return !unicode.IsDigit(r) }) < 0
+}
+
+// termCounts counts the terms of a document, weighting the title and summary
+func termCounts(doc Document) map[string]float64 {
+	counts := make(map[string]float64)
+	for _, field := range []struct {
+		text   string
+		weight float64
+	}{
+		{doc.Title, titleWeight},
+		{doc.Summary, summaryWeight},
+		{doc.Content, 1},
+	} {
+		for _, token := range Tokenize(field.text) {
+			counts[token] += field.weight
+		}
+	}
+	return counts
+}
+
+// vectors computes L2-normalized TF-IDF vectors for all documents, using
+// sublinear term frequencies and smoothed inverse document frequencies
+func vectors(docs []Document) []map[string]float64 {
+	counts := make([]map[string]float64, len(docs))
+	documentFrequency := make(map[string]int)
+	for i, doc := range docs {
+		counts[i] = termCounts(doc)
+		for term := range counts[i] {
+			documentFrequency[term]++
+		}
+	}
+
+	n := float64(len(docs))
+	result := make([]map[string]float64, len(docs))
+	for i := range docs {
+		weights := make(map[string]float64, len(counts[i]))
+		for term, count := range counts[i] {
+			idf := math.Log((1+n)/(1+float64(documentFrequency[term]))) + 1
+			weights[term] = (1 + math.Log(count)) * idf
+		}
+		result[i] = normalize(topTerms(weights, maxTermsPerDocument))
+	}
+	return result
+}
+
+// topTerms keeps the limit heaviest terms of a vector
+func topTerms(weights map[string]float64, limit int) map[string]float64 {
+	if len(weights) <= limit {
+		return weights
+	}
+	terms := make([]string, 0, len(weights))
+	for term := range weights {
+		terms = append(terms, term)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if weights[terms[i]] != weights[terms[j]] {
+			return weights[terms[i]] > weights[terms[j]]
+		}
+		return terms[i] < terms[j]
+	})
+	kept := make(map[string]float64, limit)
+	for _, term := range terms[:limit] {
+		kept[term] = weights[term]
+	}
+	return kept
+}
+
+// normalize scales a vector to unit length
+func normalize(weights map[string]float64) map[string]float64 {
+	var sum float64
+	for _, weight := range weights {
+		sum += weight * weight
+	}
+	if sum == 0 {
+		return weights
+	}
+	norm := math.Sqrt(sum)
+	for term := range weights {
+		weights[term] /= norm
+	}
+	return weights
+}
+
+// tagOverlap returns the Jaccard similarity of two tag sets
+func tagOverlap(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for tag := range a {
+		if b[tag] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// Compute returns the most similar documents for every document, most similar
+// first. Similarity combines the cosine similarity of TF-IDF vectors over the
+// title, summary and content with the overlap of tags. Documents sharing no terms
+// or tags, or written in different languages, are never candidates.
+func Compute(docs []Document) map[primitive.ObjectID][]Candidate {
+	vecs := vectors(docs)
+
+	// An inverted index limits the comparisons to documents sharing terms or tags
+	postings := make(map[string][]int)
+	tagPostings := make(map[string][]int)
+	tagSets := make([]map[string]bool, len(docs))
+	for i, doc := range docs {
+		for term := range vecs[i] {
+			postings[term] = append(postings[term], i)
+		}
+		tagSets[i] = make(map[string]bool, len(doc.Tags))
+		for _, tag := range doc.Tags {
+			tag = strings.ToLower(tag)
+			if !tagSets[i][tag] {
+				tagSets[i][tag] = true
+				tagPostings[tag] = append(tagPostings[tag], i)
+			}
+		}
+	}
+
+	result := make(map[primitive.ObjectID][]Candidate, len(docs))
+	for i, doc := range docs {
+		cosine := make(map[int]float64)
+		for term, weight := range vecs[i] {
+			for _, j := range postings[term] {
+				if j != i {
+					cosine[j] += weight * vecs[j][term]
+				}
+			}
+		}
+		for tag := range tagSets[i] {
+			for _, j := range tagPostings[tag] {
+				if _, ok := cosine[j]; !ok && j != i {
+					cosine[j] = 0
+				}
+			}
+		}
+
+		candidates := make([]Candidate, 0, len(cosine))
+		for j, textSimilarity := range cosine {
+			if docs[j].Lang != doc.Lang {
+				continue
+			}
+			similarity := textShare*math.Min(textSimilarity, 1) + (1-textShare)*tagOverlap(tagSets[i], tagSets[j])
+			if similarity > 0 {
+				candidates = append(candidates, Candidate{PostID: docs[j].ID, Similarity: similarity, CreatedAt: docs[j].CreatedAt})
+			}
+		}
+		sortCandidates(candidates, func(c Candidate) float64 { return c.Similarity })
+		if len(candidates) > MaxCandidates {
+			candidates = candidates[:MaxCandidates]
+		}
+		result[doc.ID] = candidates
+	}
+	return result
+}
+
+// RecencyWeight boosts recent posts: a post published now weighs 1, and the part
+// of the weight above 0.5 halves every RecencyHalfLife
+func RecencyWeight(createdAt, now time.Time) float64 {
+	age := now.Sub(createdAt)
+	if age < 0 {
+		age = 0
+	}
+	return 0.5 + 0.5*math.Exp2(-float64(age)/float64(RecencyHalfLife))
+}
+
+// Rank orders candidates by similarity weighted for recency and returns the
+// first limit of them, with their weighted scores
+func Rank(candidates []Candidate, now time.Time, limit int) []Candidate {
+	ranked := make([]Candidate, len(candidates))
+	for i, candidate := range candidates {
+		candidate.Similarity *= RecencyWeight(candidate.CreatedAt, now)
+		ranked[i] = candidate
+	}
+	sortCandidates(ranked, func(c Candidate) float64 { return c.Similarity })
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// sortCandidates sorts by descending score, breaking ties by newest first and then ID
+func sortCandidates(candidates []Candidate, score func(Candidate) float64) {
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if score(a) != score(b) {
+			return score(a) > score(b)
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.PostID.Hex() < b.PostID.Hex()
+	})
+}
@@ -0,0 +1,114 @@
+package related
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Unit tests for related post ranking
+
+func TestTokenize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{"lowercases words", "Go Concurrency", []string{"go", "concurrency"}},
+		{"drops stop words and punctuation", "The basics of **channels**!", []string{"basics", "channels"}},
+		{"drops URLs", "See [docs](https://go.dev/doc/effective_go) now", []string{"see", "docs", "now"}},
+		{"drops numbers and single characters", "Top 10 tips: a b c", []string{"top", "tips"}},
+		{"keeps non-ASCII letters", "Café déjà vu", []string{"café", "déjà", "vu"}},
+		{"mixed words are kept", "HTTP2 and utf8", []string{"http2", "utf8"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Tokenize(tc.text))
+		})
+	}
+}
+
+func TestCompute(t *testing.T) {
+	now := time.Now()
+	docs := []Document{
+		{ID: primitive.NewObjectID(), Title: "Goroutines and channels", Content: "Concurrency in Go with goroutines, channels and select.", Tags: []string{"go", "concurrency"}, CreatedAt: now},
+		{ID: primitive.NewObjectID(), Title: "Channel patterns", Content: "Fan-in and fan-out with channels and goroutines.", Tags: []string{"Go"}, CreatedAt: now},
+		{ID: primitive.NewObjectID(), Title: "Baking sourdough bread", Content: "Flour, water, salt and a starter.", Tags: []string{"baking"}, CreatedAt: now},
+		{ID: primitive.NewObjectID(), Title: "Kitchen notes", Content: "Nothing shared here.", Tags: []string{"concurrency"}, CreatedAt: now},
+	}
+
+	results := Compute(docs)
+	assert.Len(t, results, 4)
+
+	first := results[docs[0].ID]
+	if assert.NotEmpty(t, first) {
+		assert.Equal(t, docs[1].ID, first[0].PostID, "Shared terms and tags rank highest")
+		assert.LessOrEqual(t, first[0].Similarity, 1.0)
+	}
+	for _, candidate := range first {
+		assert.NotEqual(t, docs[0].ID, candidate.PostID, "A post is never related to itself")
+		assert.NotEqual(t, docs[2].ID, candidate.PostID, "Unrelated posts are left out")
+	}
+
+	var tagOnly bool
+	for _, candidate := range results[docs[3].ID] {
+		tagOnly = tagOnly || candidate.PostID == docs[0].ID
+	}
+	assert.True(t, tagOnly, "A shared tag alone relates posts")
+
+	assert.Empty(t, results[docs[2].ID])
+	assert.NotNil(t, results[docs[2].ID], "Posts without candidates get an empty list")
+}
+
+func TestCompute_SameLanguage(t *testing.T) {
+	docs := []Document{
+		{ID: primitive.NewObjectID(), Lang: "en", Title: "Goroutines and channels", Tags: []string{"go"}},
+		{ID: primitive.NewObjectID(), Lang: "pt", Title: "Goroutines and channels", Tags: []string{"go"}},
+		{ID: primitive.NewObjectID(), Lang: "en", Title: "Channel patterns", Tags: []string{"go"}},
+	}
+
+	results := Compute(docs)
+	if assert.Len(t, results[docs[0].ID], 1) {
+		assert.Equal(t, docs[2].ID, results[docs[0].ID][0].PostID, "Translations aren't related to each other")
+	}
+	assert.Empty(t, results[docs[1].ID])
+}
+
+func TestCompute_LimitsCandidates(t *testing.T) {
+	docs := make([]Document, MaxCandidates+5)
+	for i := range docs {
+		docs[i] = Document{ID: primitive.NewObjectID(), Title: "Shared words", Tags: []string{"go"}}
+	}
+
+	for _, candidates := range Compute(docs) {
+		assert.Len(t, candidates, MaxCandidates)
+	}
+}
+
+func TestRecencyWeight(t *testing.T) {
+	now := time.Now()
+
+	assert.InDelta(t, 1.0, RecencyWeight(now, now), 1e-9)
+	assert.InDelta(t, 1.0, RecencyWeight(now.Add(time.Hour), now), 1e-9, "Future dates count as new")
+	assert.InDelta(t, 0.75, RecencyWeight(now.Add(-RecencyHalfLife), now), 1e-9)
+	assert.InDelta(t, 0.5, RecencyWeight(now.Add(-100*RecencyHalfLife), now), 1e-9)
+}
+
+func TestRank(t *testing.T) {
+	now := time.Now()
+	old := Candidate{PostID: primitive.NewObjectID(), Similarity: 0.5, CreatedAt: now.Add(-4 * RecencyHalfLife)}
+	recent := Candidate{PostID: primitive.NewObjectID(), Similarity: 0.4, CreatedAt: now}
+	weak := Candidate{PostID: primitive.NewObjectID(), Similarity: 0.1, CreatedAt: now}
+	candidates := []Candidate{old, recent, weak}
+
+	ranked := Rank(candidates, now, 2)
+	if assert.Len(t, ranked, 2) {
+		assert.Equal(t, recent.PostID, ranked[0].PostID, "Recency outweighs a small similarity gap")
+		assert.InDelta(t, 0.4, ranked[0].Similarity, 1e-9)
+		assert.Equal(t, old.PostID, ranked[1].PostID)
+	}
+	assert.Equal(t, 0.5, candidates[0].Similarity, "The stored candidates are left unchanged")
+}
@@ -0,0 +1,136 @@
+package related
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"dbl-blog-backend/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CollectionName is the collection holding the precomputed related posts of each published post
+const CollectionName = "related_posts"
+
+// entry is the stored list of candidates for one post
+type entry struct {
+	PostID     primitive.ObjectID `bson:"_id"`
+	Candidates []Candidate        `bson:"candidates"`
+	UpdatedAt  time.Time          `bson:"updated_at"`
+}
+
+// rebuildMu prevents concurrent rebuilds from interleaving their writes
+var rebuildMu sync.Mutex
+
+// Rebuild recomputes the related posts of every published post from scratch and
+// returns the number of posts indexed. Entries of posts that are no longer
+// published are removed.
+func Rebuild(ctx context.Context) (int, error) {
+	rebuildMu.Lock()
+	defer rebuildMu.Unlock()
+
+	start := time.Now()
+	docs, err := publishedDocuments(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	collection := database.Database.Collection(CollectionName)
+	results := Compute(docs)
+	ids := make([]primitive.ObjectID, 0, len(docs))
+	writes := make([]mongo.WriteModel, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.ID)
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": doc.ID}).
+			SetReplacement(entry{PostID: doc.ID, Candidates: results[doc.ID], UpdatedAt: start}).
+			SetUpsert(true))
+	}
+
+	if len(writes) > 0 {
+		if _, err := collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$nin": ids}}); err != nil {
+		return 0, err
+	}
+
+	log.Printf("[INFO] related.Rebuild: Indexed %d published posts in %s", len(docs), time.Since(start).Round(time.Millisecond))
+	return len(docs), nil
+}
+
+// Candidates returns the stored candidates of a post, most similar first. Posts
+// that haven't been indexed yet have none.
+func Candidates(ctx context.Context, postID primitive.ObjectID) ([]Candidate, error) {
+	var stored entry
+	err := database.Database.Collection(CollectionName).FindOne(ctx, bson.M{"_id": postID}).Decode(&stored)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return stored.Candidates, nil
+}
+
+// CandidatesOf returns the stored candidates of several posts in one query.
+// Posts that haven't been indexed yet are left out.
+func CandidatesOf(ctx context.Context, postIDs []primitive.ObjectID) (map[primitive.ObjectID][]Candidate, error) {
+	cursor, err := database.Database.Collection(CollectionName).Find(ctx, bson.M{"_id": bson.M{"$in": postIDs}})
+	if err != nil {
+		return nil, err
+	}
+	var stored []entry
+	if err := cursor.All(ctx, &stored); err != nil {
+		return nil, err
+	}
+
+	candidates := make(map[primitive.ObjectID][]Candidate, len(stored))
+	for _, e := range stored {
+		candidates[e.PostID] = e.Candidates
+	}
+	return candidates, nil
+}
+
+// publishedDocuments loads the text and tags of all published posts
+func publishedDocuments(ctx context.Context) ([]Document, error) {
+	projection := bson.M{"title": 1, "summary": 1, "content": 1, "tags": 1, "lang": 1, "created_at": 1}
+	cursor, err := database.Database.Collection("posts").Find(ctx,
+		bson.M{"published": true}, options.Find().SetProjection(projection))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []Document
+	for cursor.Next(ctx) {
+		var post struct {
+			ID        primitive.ObjectID `bson:"_id"`
+			Title     string             `bson:"title"`
+			Summary   string             `bson:"summary"`
+			Content   string             `bson:"content"`
+			Tags      []string           `bson:"tags"`
+			Lang      string             `bson:"lang"`
+			CreatedAt time.Time          `bson:"created_at"`
+		}
+		if err := cursor.Decode(&post); err != nil {
+			return nil, err
+		}
+		docs = append(docs, Document{
+			ID:        post.ID,
+			Title:     post.Title,
+			Summary:   post.Summary,
+			Content:   post.Content,
+			Tags:      post.Tags,
+			Lang:      post.Lang,
+			CreatedAt: post.CreatedAt,
+		})
+	}
+	return docs, cursor.Err()
+}
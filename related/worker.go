@@ -0,0 +1,93 @@
+package related
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+)
+
+// Timing of background rebuilds
+const (
+	// DefaultRebuildInterval is how often related posts are rebuilt without any post changes
+	DefaultRebuildInterval = time.Hour
+
+	// debounceDelay collects bursts of post changes, such as an import, into one rebuild
+	debounceDelay = 5 * time.Second
+
+	// rebuildTimeout bounds a single rebuild
+	rebuildTimeout = 5 * time.Minute
+)
+
+// changes signals the worker that posts changed. Its buffer of one coalesces
+// signals sent while a rebuild is pending.
+var changes = make(chan struct{}, 1)
+
+// Schedule asks the background worker to rebuild related posts after a post was
+// created, updated or deleted. It never blocks and does nothing when the worker
+// isn't running.
+func Schedule() {
+	select {
+	case changes <- struct{}{}:
+	default:
+	}
+}
+
+// RebuildInterval reads RELATED_POSTS_REBUILD_INTERVAL as a Go duration such as
+// "30m". Zero disables periodic rebuilds; invalid values fall back to the default.
+func RebuildInterval() time.Duration {
+	value := os.Getenv("RELATED_POSTS_REBUILD_INTERVAL")
+	if value == "" {
+		return DefaultRebuildInterval
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < 0 {
+		log.Printf("Warning: Invalid RELATED_POSTS_REBUILD_INTERVAL %q, using %s", value, DefaultRebuildInterval)
+		return DefaultRebuildInterval
+	}
+	return interval
+}
+
+// Start runs the background worker until ctx is done. It rebuilds once at
+// startup, shortly after scheduled changes and then every interval.
+func Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		var tick <-chan time.Time
+		if interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		rebuild(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick:
+			case <-changes:
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(debounceDelay):
+				}
+				// Changes made during the delay are covered by this rebuild
+				select {
+				case <-changes:
+				default:
+				}
+			}
+			rebuild(ctx)
+		}
+	}()
+}
+
+// rebuild runs one rebuild, logging failures
+func rebuild(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, rebuildTimeout)
+	defer cancel()
+
+	if _, err := Rebuild(ctx); err != nil {
+		log.Printf("[ERROR] related.Rebuild: Failed to rebuild related posts - %s", err.Error())
+	}
+}
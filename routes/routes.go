@@ -30,6 +30,9 @@ func SetupRoutes() *gin.Engine {
 	// Add CORS middleware
 	router.Use(middleware.CorsMiddleware())
 
+	// Add request ID middleware (used for audit logging and tracing)
+	router.Use(middleware.RequestIDMiddleware())
+
 	// Add logging middleware
 	router.Use(gin.Logger())
 
@@ -39,6 +42,15 @@ func SetupRoutes() *gin.Engine {
 	// Add input sanitization middleware
 	router.Use(middleware.InputSanitizationMiddleware())
 
+	// Optional: Validate requests against openapi/openapi.json (controlled by environment variable).
+	// The development mode validates responses too, replacing those that don't match.
+	switch os.Getenv("OPENAPI_VALIDATION") {
+	case "requests":
+		router.Use(middleware.OpenAPIValidationMiddleware(false))
+	case "development":
+		router.Use(middleware.OpenAPIValidationMiddleware(true))
+	}
+
 	// API v1 group
 	v1 := router.Group("/api/v1")
 
@@ -52,20 +64,133 @@ func SetupRoutes() *gin.Engine {
 		posts := v1.Group("/posts")
 		{
 			// Public endpoints (no authentication required)
-			posts.GET("", handlers.GetPosts)                // Get all posts
-			posts.GET("/:id", handlers.GetPost)             // Get single post
-			posts.PUT("/:id/like", handlers.LikePost)       // Like a post
-			posts.PUT("/:id/dislike", handlers.DislikePost) // Dislike a post
-			posts.PUT("/:id/view", handlers.ViewPost)       // Track post view
+			posts.GET("", middleware.PublicCacheControl("POSTS_LIST"), handlers.GetPosts)                       // Get all posts
+			posts.GET("/:id", middleware.PublicCacheControl("POST"), handlers.GetPost)                          // Get single post
+			posts.GET("/:id/related", middleware.PublicCacheControl("RELATED_POSTS"), handlers.GetRelatedPosts) // Get related posts
+			posts.PUT("/:id/like", handlers.LikePost)                                                           // Like a post
+			posts.PUT("/:id/dislike", handlers.DislikePost)                                                     // Dislike a post
+			posts.PUT("/:id/view", handlers.ViewPost)                                                           // Track post view
+
+			// Protected endpoints (role-based access; authors may only modify their own posts)
+			canCreatePosts := middleware.RequirePermission(middleware.PermCreatePosts)
+			canEditPosts := middleware.RequirePermission(middleware.PermEditAnyPost, middleware.PermEditOwnPosts)
+			canDeletePosts := middleware.RequirePermission(middleware.PermDeleteAnyPost, middleware.PermDeleteOwnPosts)
+
+			adminPosts := posts.Group("", middleware.AdminRateLimitMiddleware(), middleware.AdminCacheControl())
+			{
+				adminPosts.POST("", canCreatePosts, handlers.CreatePost)                      // Create post
+				adminPosts.PUT("/:id", canEditPosts, handlers.UpdatePost)                     // Update post
+				adminPosts.PATCH("/:id", canEditPosts, handlers.PatchPost)                    // Partially update post
+				adminPosts.DELETE("/:id", canDeletePosts, handlers.DeletePost)                // Delete post
+				adminPosts.GET("/:id/slugs", canEditPosts, handlers.GetPostSlugs)             // List previous slugs
+				adminPosts.DELETE("/:id/slugs", canEditPosts, handlers.DeletePostSlugs)       // Prune all previous slugs
+				adminPosts.DELETE("/:id/slugs/:slug", canEditPosts, handlers.DeletePostSlugs) // Prune a previous slug
+			}
+		}
+
+		// Author profile routes
+		authors := v1.Group("/authors")
+		{
+			// Public endpoints (no authentication required)
+			authors.GET("", middleware.PublicCacheControl("AUTHORS"), handlers.GetAuthors)                      // Get all authors
+			authors.GET("/:slug", middleware.PublicCacheControl("AUTHOR"), handlers.GetAuthor)                  // Get single author
+			authors.GET("/:slug/posts", middleware.PublicCacheControl("AUTHOR_POSTS"), handlers.GetAuthorPosts) // Get an author's published posts
 
 			// Protected endpoints (admin only)
-			adminPosts := posts.Group("", middleware.AdminRateLimitMiddleware(), middleware.AdminAuthMiddleware())
+			adminAuthors := authors.Group("", middleware.AdminRateLimitMiddleware(), middleware.AdminCacheControl(), middleware.RequirePermission(middleware.PermManageAuthors))
 			{
-				adminPosts.POST("", handlers.CreatePost)       // Create post
-				adminPosts.PUT("/:id", handlers.UpdatePost)    // Update post
-				adminPosts.DELETE("/:id", handlers.DeletePost) // Delete post
+				adminAuthors.POST("", handlers.CreateAuthor)       // Create author
+				adminAuthors.PUT("/:id", handlers.UpdateAuthor)    // Update author
+				adminAuthors.DELETE("/:id", handlers.DeleteAuthor) // Delete author
 			}
 		}
+
+		// Series routes
+		series := v1.Group("/series")
+		{
+			// Public endpoints (no authentication required)
+			series.GET("", middleware.PublicCacheControl("SERIES_LIST"), handlers.GetSeriesList) // Get all series
+			series.GET("/:slug", middleware.PublicCacheControl("SERIES"), handlers.GetSeries)    // Get a series with its parts
+
+			// Protected endpoints (admins and editors)
+			adminSeries := series.Group("", middleware.AdminRateLimitMiddleware(), middleware.AdminCacheControl(), middleware.RequirePermission(middleware.PermManageSeries))
+			{
+				adminSeries.POST("", handlers.CreateSeries)       // Create series
+				adminSeries.PUT("/:id", handlers.UpdateSeries)    // Update series
+				adminSeries.DELETE("/:id", handlers.DeleteSeries) // Delete series
+			}
+		}
+
+		// Archive routes (public; published posts grouped by year and month)
+		archive := v1.Group("/archive")
+		{
+			archive.GET("", middleware.PublicCacheControl("ARCHIVE"), handlers.GetArchive)                         // Count posts by year and month
+			archive.GET("/:year/:month", middleware.PublicCacheControl("ARCHIVE_MONTH"), handlers.GetArchiveMonth) // Get the posts of a month
+		}
+
+		// GraphQL (public reads; mutations are authorized as the matching REST endpoints)
+		graphQL := handlers.GraphQL(router)
+		v1.GET("/graphql", middleware.PublicCacheControl("GRAPHQL"), graphQL) // Run a query, or a persisted query by hash
+		v1.POST("/graphql", graphQL)                                          // Run a query or mutation
+
+		// Media routes
+		mediaRoutes := v1.Group("/media")
+		{
+			// Public endpoints (no authentication required)
+			mediaRoutes.GET("/:id", handlers.GetMedia)          // Serve or redirect to an uploaded file
+			mediaRoutes.GET("/:id/:variant", handlers.GetMedia) // Serve or redirect to a resized or WebP variant
+
+			// Protected endpoints (role-based access)
+			canUploadMedia := middleware.RequirePermission(middleware.PermUploadMedia)
+
+			adminMedia := mediaRoutes.Group("", middleware.AdminRateLimitMiddleware(), middleware.AdminCacheControl())
+			{
+				adminMedia.POST("", canUploadMedia, handlers.UploadMedia)                                                 // Upload a file
+				adminMedia.GET("", canUploadMedia, handlers.ListMedia)                                                    // List uploaded files
+				adminMedia.DELETE("/:id", middleware.RequirePermission(middleware.PermDeleteMedia), handlers.DeleteMedia) // Delete a file and its variants
+			}
+		}
+
+		// Newsletter routes (public; links in emails carry a token)
+		newsletterRoutes := v1.Group("/newsletter")
+		{
+			newsletterRoutes.POST("/subscribe", middleware.NewsletterRateLimitMiddleware(), handlers.Subscribe) // Subscribe (sends a confirmation email)
+			newsletterRoutes.GET("/confirm", handlers.ConfirmSubscription)                                      // Confirm a subscription
+			newsletterRoutes.GET("/unsubscribe", handlers.Unsubscribe)                                          // Unsubscribe from a link
+			newsletterRoutes.POST("/unsubscribe", handlers.Unsubscribe)                                         // One-click unsubscribe (RFC 8058)
+			newsletterRoutes.GET("/preferences", handlers.GetNewsletterPreferences)                             // Get tag preferences
+			newsletterRoutes.PUT("/preferences", handlers.UpdateNewsletterPreferences)                          // Update tag preferences
+		}
+
+		// Admin routes (role-based access)
+		admin := v1.Group("/admin", middleware.AdminRateLimitMiddleware(), middleware.AdminCacheControl())
+		{
+			admin.GET("/analytics", middleware.RequirePermission(middleware.PermReadAnalytics), handlers.GetAnalytics)                // Read-only post analytics
+			admin.GET("/audit", middleware.RequirePermission(middleware.PermReadAuditLog), handlers.GetAuditLog)                      // Query the audit log
+			admin.GET("/export", middleware.RequirePermission(middleware.PermExportPosts), handlers.ExportPosts)                      // Stream all posts as NDJSON or Markdown archive
+			admin.POST("/related/rebuild", middleware.RequirePermission(middleware.PermRebuildRelated), handlers.RebuildRelatedPosts) // Recompute related posts now
+			admin.POST("/import", middleware.RequirePermission(middleware.PermImportPosts), handlers.ImportPosts)                     // Import posts from a Markdown archive
+			admin.POST("/import/wordpress", middleware.RequirePermission(middleware.PermImportPosts), handlers.ImportWordPress)       // Import posts from a WordPress export
+
+			// Webhooks for post lifecycle events (admin only)
+			adminWebhooks := admin.Group("/webhooks", middleware.RequirePermission(middleware.PermManageWebhooks))
+			{
+				adminWebhooks.POST("", handlers.CreateWebhook)                                                  // Register a webhook
+				adminWebhooks.GET("", handlers.GetWebhooks)                                                     // List webhooks
+				adminWebhooks.GET("/:id", handlers.GetWebhook)                                                  // Get a webhook
+				adminWebhooks.PUT("/:id", handlers.UpdateWebhook)                                               // Update, re-enable or rotate the secret of a webhook
+				adminWebhooks.DELETE("/:id", handlers.DeleteWebhook)                                            // Delete a webhook and its deliveries
+				adminWebhooks.GET("/:id/deliveries", handlers.GetWebhookDeliveries)                             // Delivery log
+				adminWebhooks.POST("/:id/deliveries/:delivery_id/redeliver", handlers.RedeliverWebhookDelivery) // Redeliver an event
+			}
+
+			// Newsletter subscribers and issues (admin only)
+			canManageSubscribers := middleware.RequirePermission(middleware.PermManageSubscribers)
+			admin.GET("/subscribers", canManageSubscribers, handlers.GetSubscribers)                  // List subscribers
+			admin.DELETE("/subscribers/:id", canManageSubscribers, handlers.DeleteSubscriber)         // Delete a subscriber
+			admin.POST("/subscribers/bounces", canManageSubscribers, handlers.ReportSubscriberBounce) // Report a bounce or spam complaint
+			admin.GET("/newsletter/issues", canManageSubscribers, handlers.GetNewsletterIssues)       // Newsletter sending progress
+		}
 	}
 
 	// Health check endpoint
@@ -76,5 +201,9 @@ func SetupRoutes() *gin.Engine {
 		})
 	})
 
+	// API documentation (OpenAPI document and the page rendering it)
+	router.GET("/openapi.json", handlers.GetOpenAPISpec)
+	router.GET("/docs", handlers.GetDocs)
+
 	return router
 }
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"testing"
+
+	"dbl-blog-backend/openapi"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Checks that openapi/openapi.json and the routes don't drift apart
+
+func TestRoutesAreDocumented(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	doc, err := openapi.Load()
+	require.NoError(t, err)
+
+	routes := SetupRoutes().Routes()
+	assert.Empty(t, openapi.Undocumented(doc, routes), "Routes missing from openapi/openapi.json")
+	assert.Empty(t, openapi.Unrouted(doc, routes), "Operations of openapi/openapi.json that no route serves")
+}
@@ -12,6 +12,12 @@ API_KEY=$(openssl rand -hex 32)
 echo "✅ Generated API Key:"
 echo "ADMIN_API_KEYS=$API_KEY"
 echo ""
+
+# The key ID is the first 16 hex characters of the key's SHA-256 digest.
+# Add it to an author's api_key_ids to attribute posts created with this key.
+KEY_ID=$(printf '%s' "$API_KEY" | openssl dgst -sha256 | awk '{print $NF}' | cut -c1-16)
+echo "🪪 Key ID (for author api_key_ids): $KEY_ID"
+echo ""
 echo "📝 Next steps:"
 echo "1. Add this to your .env file (you can add multiple keys separated by commas)"
 echo "2. Restart your server"
@@ -0,0 +1,102 @@
+package slug
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength is the longest slug accepted by models.Post
+const MaxLength = 100
+
+// fallback is used when a title contains nothing that can appear in a slug
+const fallback = "post"
+
+// transliterations covers letters that Unicode decomposition doesn't reduce to ASCII
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d", 'þ': "th",
+	'ł': "l", 'ı': "i", 'ħ': "h", 'ŋ': "ng", 'ŧ': "t", 'ĸ': "k",
+
+	// Cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g",
+
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th",
+	'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p",
+	'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps",
+	'ω': "o",
+}
+
+// Generate builds a URL-friendly slug from a title. Accents are stripped, common
+// non-Latin letters are transliterated, and each run of other characters becomes
+// a single hyphen. Letters of scripts without a transliteration are kept as is.
+// The result is at most MaxLength characters long and never empty.
+func Generate(title string) string {
+	// Decompose so accents become separate combining marks, then drop them
+	stripMarks := transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	normalized, _, err := transform.String(stripMarks, strings.ToLower(title))
+	if err != nil {
+		normalized = strings.ToLower(title)
+	}
+
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range normalized {
+		text, ok := transliterations[r]
+		if !ok {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				pendingHyphen = b.Len() > 0
+				continue
+			}
+			text = string(r)
+		}
+		if text == "" {
+			continue
+		}
+		if pendingHyphen {
+			b.WriteByte('-')
+			pendingHyphen = false
+		}
+		b.WriteString(text)
+	}
+
+	slug := Truncate(b.String(), MaxLength)
+	if slug == "" {
+		return fallback
+	}
+	return slug
+}
+
+// WithSuffix returns the n-th candidate for base, e.g. "my-post-2" for n = 2,
+// shortening base as needed so the result fits in MaxLength
+func WithSuffix(base string, n int) string {
+	if n < 2 {
+		return base
+	}
+	suffix := "-" + strconv.Itoa(n)
+	return Truncate(base, MaxLength-utf8.RuneCountInString(suffix)) + suffix
+}
+
+// Truncate shortens a slug to at most max characters, cutting at the last
+// hyphen in its second half when there is one so words are not split
+func Truncate(slug string, max int) string {
+	if utf8.RuneCountInString(slug) <= max {
+		return strings.Trim(slug, "-")
+	}
+
+	r := []rune(slug)[:max]
+	cut := string(r)
+	if i := strings.LastIndexByte(cut, '-'); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.Trim(cut, "-")
+}
@@ -0,0 +1,58 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected string
+	}{
+		{"Hello World", "hello-world"},
+		{"Café & Go: 2024 — Part 1/2", "cafe-go-2024-part-1-2"},
+		{"  Leading and trailing!  ", "leading-and-trailing"},
+		{"Don't Stop Believin'", "don-t-stop-believin"},
+		{"Straße in Łódź", "strasse-in-lodz"},
+		{"Ærøskøbing", "aeroskobing"},
+		{"Привет, мир", "privet-mir"},
+		{"Ελληνικά", "ellinika"},
+		{"ｆｕｌｌｗｉｄｔｈ", "fullwidth"},
+		{"日本語 タイトル", "日本語-タイトル"},
+		{"!!!", "post"},
+		{"", "post"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Generate(tt.title))
+		})
+	}
+}
+
+func TestGenerate_LengthCap(t *testing.T) {
+	title := strings.Repeat("word ", 40)
+	slug := Generate(title)
+
+	assert.LessOrEqual(t, utf8.RuneCountInString(slug), MaxLength)
+	assert.False(t, strings.HasSuffix(slug, "-"), "Slug should not end with a hyphen")
+	assert.True(t, strings.HasSuffix(slug, "word"), "Slug should be cut between words")
+
+	long := Generate(strings.Repeat("x", 150))
+	assert.Equal(t, MaxLength, utf8.RuneCountInString(long), "Words longer than the cap are cut")
+}
+
+func TestWithSuffix(t *testing.T) {
+	assert.Equal(t, "my-post", WithSuffix("my-post", 1))
+	assert.Equal(t, "my-post-2", WithSuffix("my-post", 2))
+	assert.Equal(t, "my-post-13", WithSuffix("my-post", 13))
+
+	base := strings.Repeat("y", MaxLength)
+	suffixed := WithSuffix(base, 3)
+	assert.Equal(t, MaxLength, utf8.RuneCountInString(suffixed))
+	assert.True(t, strings.HasSuffix(suffixed, "-3"))
+}
@@ -0,0 +1,101 @@
+// Package watch broadcasts committed post lifecycle events to the watchers of
+// this process, such as gRPC WatchPosts streams. Events are the ones webhooks
+// receive, published once the write that raised them is committed. Only writes
+// made by this instance are seen.
+package watch
+
+import (
+	"sync"
+
+	"dbl-blog-backend/webhooks"
+)
+
+// Buffer is the number of events a watcher may fall behind by before it is
+// dropped
+const Buffer = 256
+
+var (
+	mu       sync.Mutex
+	watchers = make(map[*Watcher]struct{})
+)
+
+// Watcher receives the events published after it subscribed
+type Watcher struct {
+	events chan webhooks.Event
+	types  map[string]bool // nil receives all event types
+
+	closeOnce sync.Once
+	lagged    bool
+}
+
+// Subscribe returns a watcher of the given event types, or of all of them when
+// none is given. Watchers must be closed once done with.
+func Subscribe(types ...string) *Watcher {
+	w := &Watcher{events: make(chan webhooks.Event, Buffer)}
+	if len(types) > 0 {
+		w.types = make(map[string]bool, len(types))
+		for _, eventType := range types {
+			w.types[eventType] = true
+		}
+	}
+
+	mu.Lock()
+	watchers[w] = struct{}{}
+	mu.Unlock()
+	return w
+}
+
+// Events returns the channel of events. It is closed when the watcher is
+// closed or dropped for falling behind.
+func (w *Watcher) Events() <-chan webhooks.Event {
+	return w.events
+}
+
+// Lagged reports whether the watcher was dropped for falling behind, once its
+// channel is closed. Events published since were missed.
+func (w *Watcher) Lagged() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return w.lagged
+}
+
+// Close unsubscribes the watcher and closes its channel
+func (w *Watcher) Close() {
+	mu.Lock()
+	defer mu.Unlock()
+	w.closeLocked()
+}
+
+func (w *Watcher) closeLocked() {
+	w.closeOnce.Do(func() {
+		delete(watchers, w)
+		close(w.events)
+	})
+}
+
+// Publish sends events to every watcher subscribed to their type. It never
+// blocks: watchers whose buffer is full are dropped instead.
+func Publish(events ...webhooks.Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for w := range watchers {
+		for _, event := range events {
+			if w.types != nil && !w.types[event.Type] {
+				continue
+			}
+			select {
+			case w.events <- event:
+			default:
+				w.lagged = true
+				w.closeLocked()
+			}
+			if w.lagged {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package watch
+
+import (
+	"testing"
+
+	"dbl-blog-backend/models"
+	"dbl-blog-backend/webhooks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Unit tests for the broadcast of post events
+
+func TestPublishFiltersByType(t *testing.T) {
+	all := Subscribe()
+	defer all.Close()
+	published := Subscribe(webhooks.EventPostPublished)
+	defer published.Close()
+
+	post := models.Post{Title: "Hello"}
+	Publish(webhooks.PostEvents(nil, &post)...) // Draft: post.created only
+	post.Published = true
+	Publish(webhooks.PostEvents(nil, &post)...) // post.created and post.published
+
+	var types []string
+	for len(all.Events()) > 0 {
+		types = append(types, (<-all.Events()).Type)
+	}
+	assert.Equal(t, []string{webhooks.EventPostCreated, webhooks.EventPostCreated, webhooks.EventPostPublished}, types)
+
+	if assert.Len(t, published.Events(), 1) {
+		event := <-published.Events()
+		assert.Equal(t, webhooks.EventPostPublished, event.Type)
+		assert.Equal(t, "Hello", event.Data.Post.Title)
+	}
+}
+
+func TestPublishDropsLaggingWatchers(t *testing.T) {
+	lagging := Subscribe()
+	keeping := Subscribe(webhooks.EventPostDeleted)
+	defer keeping.Close()
+
+	for i := 0; i <= Buffer; i++ {
+		Publish(webhooks.NewEvent(webhooks.EventPostLiked, models.Post{}))
+	}
+
+	received := 0
+	for range lagging.Events() {
+		received++
+	}
+	assert.Equal(t, Buffer, received, "the channel is closed once the buffer overflows")
+	assert.True(t, lagging.Lagged())
+	lagging.Close() // Closing a dropped watcher is harmless
+
+	Publish(webhooks.NewEvent(webhooks.EventPostDeleted, models.Post{}))
+	assert.Len(t, keeping.Events(), 1)
+	assert.False(t, keeping.Lagged())
+}
+
+func TestCloseUnsubscribes(t *testing.T) {
+	w := Subscribe()
+	w.Close()
+	Publish(webhooks.NewEvent(webhooks.EventPostLiked, models.Post{}))
+
+	_, open := <-w.Events()
+	assert.False(t, open)
+	assert.False(t, w.Lagged())
+}
@@ -0,0 +1,268 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Delivery policy
+const (
+	// MaxAttempts is the number of attempts before a delivery fails for good
+	MaxAttempts = 10
+
+	// DisableAfterFailures is the number of consecutive failed attempts, across
+	// all deliveries, after which a webhook is disabled
+	DisableAfterFailures = 15
+
+	// Retries wait retryBaseDelay, doubling after every attempt up to retryMaxDelay
+	retryBaseDelay = time.Minute
+	retryMaxDelay  = 6 * time.Hour
+
+	// requestTimeout bounds each attempt
+	requestTimeout = 10 * time.Second
+
+	// lease is how long a claimed delivery is reserved for the dispatcher sending it
+	lease = time.Minute
+
+	// pollInterval is how often the outbox is checked when it has nothing due
+	pollInterval = 2 * time.Second
+
+	// concurrency is the number of deliveries sent at the same time
+	concurrency = 4
+
+	// maxResponseBody is the length of response bodies kept in the delivery log
+	maxResponseBody = 1024
+)
+
+var client = &http.Client{
+	Timeout: requestTimeout,
+	// Redirects are not followed, so a delivery only ever reaches the registered URL
+	CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+}
+
+// RetryDelay returns the wait before the attempt following attempt number n (1-based)
+func RetryDelay(n int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < n && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay
+}
+
+// Start runs the dispatcher until ctx is done. It sends due deliveries from the
+// outbox, several at a time. Leases on claimed deliveries allow several server
+// instances to dispatch from the same outbox.
+func Start(ctx context.Context) {
+	go func() {
+		slots := make(chan struct{}, concurrency)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case slots <- struct{}{}:
+			}
+
+			delivery, err := claim(ctx)
+			if err != nil || delivery == nil {
+				<-slots
+				if err != nil {
+					log.Printf("[ERROR] webhooks.Start: Failed to claim delivery - %s", err.Error())
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(pollInterval):
+				}
+				continue
+			}
+
+			go func() {
+				defer func() { <-slots }()
+				process(ctx, *delivery)
+			}()
+		}
+	}()
+}
+
+// claim leases the pending delivery that has been due the longest, or returns nil
+// when none is due
+func claim(ctx context.Context) (*models.WebhookDelivery, error) {
+	now := time.Now()
+	lockedUntil := now.Add(lease)
+
+	var delivery models.WebhookDelivery
+	err := database.Database.Collection(DeliveriesCollectionName).FindOneAndUpdate(ctx,
+		bson.M{
+			"status":          StatusPending,
+			"next_attempt_at": bson.M{"$lte": now},
+			"$or": bson.A{
+				bson.M{"locked_until": bson.M{"$exists": false}},
+				bson.M{"locked_until": bson.M{"$lte": now}},
+			},
+		},
+		bson.M{"$set": bson.M{"locked_until": lockedUntil}},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&delivery)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// process sends a claimed delivery and records the outcome
+func process(ctx context.Context, delivery models.WebhookDelivery) {
+	var webhook models.Webhook
+	err := database.Database.Collection(CollectionName).FindOne(ctx, bson.M{"_id": delivery.WebhookID}).Decode(&webhook)
+	if err != nil && err != mongo.ErrNoDocuments {
+		log.Printf("[ERROR] webhooks.process: Failed to fetch webhook ID '%s' - %s", delivery.WebhookID.Hex(), err.Error())
+		return // Retried once the lease expires
+	}
+	if err == mongo.ErrNoDocuments || !webhook.Active {
+		finish(ctx, delivery, StatusCancelled, nil)
+		return
+	}
+
+	attempt := send(ctx, webhook, delivery)
+	if attempt.Error == "" {
+		finish(ctx, delivery, StatusSucceeded, &attempt)
+		recordSuccess(ctx, webhook)
+		return
+	}
+
+	log.Printf("[ERROR] webhooks.process: Delivery '%s' of '%s' to webhook ID '%s' failed - %s",
+		delivery.ID.Hex(), delivery.Event, webhook.ID.Hex(), attempt.Error)
+	if attempts := len(delivery.Attempts) + 1; attempts >= MaxAttempts {
+		finish(ctx, delivery, StatusFailed, &attempt)
+	} else {
+		retry(ctx, delivery, attempt, time.Now().Add(RetryDelay(attempts)))
+	}
+	recordFailure(ctx, webhook)
+}
+
+// send makes one attempt to deliver to the webhook. Any response other than 2xx
+// is a failure.
+func send(ctx context.Context, webhook models.Webhook, delivery models.WebhookDelivery) models.WebhookAttempt {
+	start := time.Now()
+	attempt := models.WebhookAttempt{At: start}
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "dbl-blog-webhooks/1.0")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(EventIDHeader, delivery.EventID)
+	req.Header.Set(DeliveryHeader, delivery.ID.Hex())
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, start.Unix(), body))
+
+	resp, err := client.Do(req)
+	attempt.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	attempt.StatusCode = resp.StatusCode
+	responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	attempt.ResponseBody = string(responseBody)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		attempt.Error = "unexpected status " + strconv.Itoa(resp.StatusCode)
+	}
+	return attempt
+}
+
+// finish completes a delivery with a final status, recording its last attempt if any
+func finish(ctx context.Context, delivery models.WebhookDelivery, status string, attempt *models.WebhookAttempt) {
+	update := bson.M{
+		"$set":   bson.M{"status": status, "completed_at": time.Now()},
+		"$unset": bson.M{"locked_until": "", "next_attempt_at": ""},
+	}
+	if attempt != nil {
+		update["$push"] = bson.M{"attempts": attempt}
+	}
+	updateDelivery(ctx, delivery, update)
+}
+
+// retry records a failed attempt and schedules the next one
+func retry(ctx context.Context, delivery models.WebhookDelivery, attempt models.WebhookAttempt, next time.Time) {
+	updateDelivery(ctx, delivery, bson.M{
+		"$set":   bson.M{"next_attempt_at": next},
+		"$unset": bson.M{"locked_until": ""},
+		"$push":  bson.M{"attempts": attempt},
+	})
+}
+
+// updateDelivery applies an update to a delivery, logging failures
+func updateDelivery(ctx context.Context, delivery models.WebhookDelivery, update bson.M) {
+	_, err := database.Database.Collection(DeliveriesCollectionName).UpdateOne(ctx, bson.M{"_id": delivery.ID}, update)
+	if err != nil {
+		log.Printf("[ERROR] webhooks.process: Failed to update delivery '%s' - %s", delivery.ID.Hex(), err.Error())
+	}
+}
+
+// recordSuccess resets the consecutive failures of a webhook
+func recordSuccess(ctx context.Context, webhook models.Webhook) {
+	if webhook.ConsecutiveFailures == 0 {
+		return
+	}
+	_, err := database.Database.Collection(CollectionName).UpdateOne(ctx,
+		bson.M{"_id": webhook.ID}, bson.M{"$set": bson.M{"consecutive_failures": 0}})
+	if err != nil {
+		log.Printf("[ERROR] webhooks.process: Failed to reset failures of webhook ID '%s' - %s", webhook.ID.Hex(), err.Error())
+	}
+}
+
+// recordFailure counts a failed attempt against a webhook, disabling it once
+// DisableAfterFailures attempts in a row have failed. Its pending deliveries are
+// then cancelled as they come due.
+func recordFailure(ctx context.Context, webhook models.Webhook) {
+	collection := database.Database.Collection(CollectionName)
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": webhook.ID}, bson.M{"$inc": bson.M{"consecutive_failures": 1}})
+	if err != nil {
+		log.Printf("[ERROR] webhooks.process: Failed to count failure of webhook ID '%s' - %s", webhook.ID.Hex(), err.Error())
+		return
+	}
+
+	now := time.Now()
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": webhook.ID, "active": true, "consecutive_failures": bson.M{"$gte": DisableAfterFailures}},
+		bson.M{"$set": bson.M{
+			"active":          false,
+			"disabled_at":     now,
+			"disabled_reason": fmt.Sprintf("%d consecutive failed delivery attempts", DisableAfterFailures),
+			"updated_at":      now,
+		}},
+	)
+	if err != nil {
+		log.Printf("[ERROR] webhooks.process: Failed to disable webhook ID '%s' - %s", webhook.ID.Hex(), err.Error())
+		return
+	}
+	if result.ModifiedCount > 0 {
+		log.Printf("[INFO] webhooks.process: Disabled webhook ID '%s' after %d consecutive failures", webhook.ID.Hex(), DisableAfterFailures)
+	}
+}
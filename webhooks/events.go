@@ -0,0 +1,168 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"dbl-blog-backend/database"
+	"dbl-blog-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Collections holding webhooks and their deliveries
+const (
+	CollectionName           = "webhooks"
+	DeliveriesCollectionName = "webhook_deliveries"
+)
+
+// Post lifecycle events webhooks can subscribe to
+const (
+	EventPostCreated   = "post.created"
+	EventPostUpdated   = "post.updated"
+	EventPostPublished = "post.published"
+	EventPostDeleted   = "post.deleted"
+	EventPostLiked     = "post.liked"
+)
+
+// Events lists all event types
+var Events = []string{EventPostCreated, EventPostUpdated, EventPostPublished, EventPostDeleted, EventPostLiked}
+
+// Delivery statuses
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"    // All attempts failed
+	StatusCancelled = "cancelled" // The webhook was disabled or deleted before delivery
+)
+
+// Event is the JSON body of a delivery
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      EventData `json:"data"`
+}
+
+// EventData holds the post an event is about, as it was after the change, or
+// before it for deletions
+type EventData struct {
+	Post models.Post `json:"post"`
+}
+
+// ValidEvent reports whether name is a known event type
+func ValidEvent(name string) bool {
+	for _, event := range Events {
+		if event == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NewEvent creates an event about a post
+func NewEvent(eventType string, post models.Post) Event {
+	post.Series = nil
+	return Event{
+		ID:        primitive.NewObjectID().Hex(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      EventData{Post: post},
+	}
+}
+
+// PostEvents returns the events of a post write: a nil before is a creation and
+// a nil after a deletion. Posts that become published also raise post.published.
+func PostEvents(before, after *models.Post) []Event {
+	switch {
+	case after == nil && before != nil:
+		return []Event{NewEvent(EventPostDeleted, *before)}
+	case after == nil:
+		return nil
+	}
+
+	events := []Event{NewEvent(EventPostUpdated, *after)}
+	if before == nil {
+		events[0] = NewEvent(EventPostCreated, *after)
+	}
+	if after.Published && (before == nil || !before.Published) {
+		events = append(events, NewEvent(EventPostPublished, *after))
+	}
+	return events
+}
+
+// EnqueuePostEvents queues the events of a post write, see PostEvents. Call it
+// with the context of the transaction making the write.
+func EnqueuePostEvents(ctx context.Context, before, after *models.Post) error {
+	return Enqueue(ctx, PostEvents(before, after)...)
+}
+
+// Enqueue writes a pending delivery to the outbox for every active webhook
+// subscribed to each event. Called within the transaction that makes the change,
+// events are queued if and only if the change is committed; the dispatcher then
+// sends them.
+func Enqueue(ctx context.Context, events ...Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	types := make([]string, len(events))
+	for i, event := range events {
+		types[i] = event.Type
+	}
+
+	var webhooks []models.Webhook
+	cursor, err := database.Database.Collection(CollectionName).Find(ctx,
+		bson.M{"active": true, "events": bson.M{"$in": types}})
+	if err != nil {
+		return err
+	}
+	if err := cursor.All(ctx, &webhooks); err != nil {
+		return err
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	var deliveries []interface{}
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		for _, webhook := range webhooks {
+			if !subscribed(webhook, event.Type) {
+				continue
+			}
+			deliveries = append(deliveries, models.WebhookDelivery{
+				WebhookID:     webhook.ID,
+				EventID:       event.ID,
+				Event:         event.Type,
+				Payload:       string(payload),
+				Status:        StatusPending,
+				Attempts:      []models.WebhookAttempt{},
+				NextAttemptAt: &now,
+				CreatedAt:     now,
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	_, err = database.Database.Collection(DeliveriesCollectionName).InsertMany(ctx, deliveries)
+	return err
+}
+
+// subscribed reports whether webhook receives events of type eventType
+func subscribed(webhook models.Webhook, eventType string) bool {
+	for _, event := range webhook.Events {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"dbl-blog-backend/models"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Unit tests for webhook signing, events and delivery attempts
+
+func TestSign(t *testing.T) {
+	signature := Sign("whsec_test", 1700000000, []byte(`{"id":"evt"}`))
+	assert.Equal(t, "t=1700000000,v1=a94cea056df1fbb92eadafcf2c5cd541dbe0c6ef736e4748202dd53f86694a3e", signature)
+
+	assert.NotEqual(t, signature, Sign("whsec_other", 1700000000, []byte(`{"id":"evt"}`)), "The secret is part of the signature")
+	assert.NotEqual(t, signature, Sign("whsec_test", 1700000001, []byte(`{"id":"evt"}`)), "The timestamp is part of the signature")
+}
+
+func TestGenerateSecret(t *testing.T) {
+	first, err := GenerateSecret()
+	assert.NoError(t, err)
+	second, err := GenerateSecret()
+	assert.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(first, "whsec_"))
+	assert.Len(t, first, len("whsec_")+64)
+	assert.NotEqual(t, first, second)
+}
+
+func TestRetryDelay(t *testing.T) {
+	testCases := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{5, 16 * time.Minute},
+		{9, 256 * time.Minute},
+		{10, 6 * time.Hour},
+		{100, 6 * time.Hour},
+	}
+
+	for _, tc := range testCases {
+		t.Run(strconv.Itoa(tc.attempt), func(t *testing.T) {
+			assert.Equal(t, tc.expected, RetryDelay(tc.attempt))
+		})
+	}
+}
+
+func TestValidEvent(t *testing.T) {
+	for _, event := range Events {
+		assert.True(t, ValidEvent(event), event)
+	}
+	assert.False(t, ValidEvent("post.viewed"))
+	assert.False(t, ValidEvent(""))
+}
+
+func TestPostEvents(t *testing.T) {
+	draft := models.Post{ID: primitive.NewObjectID(), Title: "Draft"}
+	published := draft
+	published.Published = true
+
+	eventTypes := func(events []Event) []string {
+		types := []string{}
+		for _, event := range events {
+			types = append(types, event.Type)
+		}
+		return types
+	}
+
+	testCases := []struct {
+		name          string
+		before, after *models.Post
+		expected      []string
+	}{
+		{"created draft", nil, &draft, []string{EventPostCreated}},
+		{"created published", nil, &published, []string{EventPostCreated, EventPostPublished}},
+		{"updated draft", &draft, &draft, []string{EventPostUpdated}},
+		{"published draft", &draft, &published, []string{EventPostUpdated, EventPostPublished}},
+		{"updated published", &published, &published, []string{EventPostUpdated}},
+		{"unpublished", &published, &draft, []string{EventPostUpdated}},
+		{"deleted", &published, nil, []string{EventPostDeleted}},
+		{"nothing", nil, nil, []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, eventTypes(PostEvents(tc.before, tc.after)))
+		})
+	}
+}
+
+func TestNewEvent(t *testing.T) {
+	post := models.Post{ID: primitive.NewObjectID(), Title: "Hello", Series: &models.PostSeries{Title: "Intro"}}
+
+	event := NewEvent(EventPostLiked, post)
+	assert.Equal(t, EventPostLiked, event.Type)
+	assert.NotEmpty(t, event.ID)
+	assert.Equal(t, "Hello", event.Data.Post.Title)
+	assert.Nil(t, event.Data.Post.Series, "Series links are not part of events")
+	assert.NotNil(t, post.Series, "The post itself is left unchanged")
+
+	assert.NotEqual(t, event.ID, NewEvent(EventPostLiked, post).ID)
+}
+
+func TestSend(t *testing.T) {
+	payload := `{"id":"evt","type":"post.created"}`
+	delivery := models.WebhookDelivery{ID: primitive.NewObjectID(), EventID: "evt", Event: EventPostCreated, Payload: payload}
+
+	t.Run("signs a successful delivery", func(t *testing.T) {
+		var received *http.Request
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			body, _ = io.ReadAll(r.Body)
+			_ = json.NewEncoder(w).Encode(map[string]string{"ok": "yes"})
+		}))
+		defer server.Close()
+
+		webhook := models.Webhook{URL: server.URL, Secret: "whsec_test"}
+		attempt := send(t.Context(), webhook, delivery)
+
+		assert.Empty(t, attempt.Error)
+		assert.Equal(t, http.StatusOK, attempt.StatusCode)
+		assert.Contains(t, attempt.ResponseBody, `"ok":"yes"`)
+		if assert.NotNil(t, received) {
+			assert.Equal(t, payload, string(body))
+			assert.Equal(t, EventPostCreated, received.Header.Get(EventHeader))
+			assert.Equal(t, "evt", received.Header.Get(EventIDHeader))
+			assert.Equal(t, delivery.ID.Hex(), received.Header.Get(DeliveryHeader))
+
+			signature := received.Header.Get(SignatureHeader)
+			timestamp, err := strconv.ParseInt(strings.TrimPrefix(strings.Split(signature, ",")[0], "t="), 10, 64)
+			assert.NoError(t, err)
+			assert.Equal(t, Sign("whsec_test", timestamp, []byte(payload)), signature)
+		}
+	})
+
+	t.Run("non-2xx responses fail", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(strings.Repeat("x", 2*maxResponseBody)))
+		}))
+		defer server.Close()
+
+		attempt := send(t.Context(), models.Webhook{URL: server.URL}, delivery)
+		assert.Equal(t, "unexpected status 503", attempt.Error)
+		assert.Equal(t, http.StatusServiceUnavailable, attempt.StatusCode)
+		assert.Len(t, attempt.ResponseBody, maxResponseBody, "Response bodies are truncated")
+	})
+
+	t.Run("redirects are not followed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/elsewhere", http.StatusFound)
+		}))
+		defer server.Close()
+
+		attempt := send(t.Context(), models.Webhook{URL: server.URL}, delivery)
+		assert.Equal(t, http.StatusFound, attempt.StatusCode)
+		assert.NotEmpty(t, attempt.Error)
+	})
+
+	t.Run("connection errors fail", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		attempt := send(t.Context(), models.Webhook{URL: server.URL}, delivery)
+		assert.NotEmpty(t, attempt.Error)
+		assert.Zero(t, attempt.StatusCode)
+	})
+}